@@ -0,0 +1,122 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/Ayushlm10/skim/internal/components/picker"
+	"github.com/Ayushlm10/skim/internal/components/preview"
+	"github.com/Ayushlm10/skim/internal/index"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backlinkPicker identifies the backlinks panel in picker messages
+const backlinkPicker = "backlinks"
+
+// BacklinksFoundMsg is sent when the documents linking to a file have been found
+type BacklinksFoundMsg struct {
+	Target    string
+	Backlinks []index.Backlink
+	Err       error
+}
+
+// WikiLinkResolvedMsg is sent when a clicked wiki link has been matched to a file
+type WikiLinkResolvedMsg struct {
+	// Target is the link target as written
+	Target string
+
+	// Path is the matching file, "" if there is none
+	Path string
+}
+
+// openBacklinks finds the documents linking to the previewed file in the
+// background; the panel opens once they are known
+func (m Model) openBacklinks() (tea.Model, tea.Cmd) {
+	target := m.preview.FilePath()
+	if target == "" {
+		m.statusMessage = "open a document to see its backlinks"
+		return m, nil
+	}
+
+	m.loading = true
+	root := m.RootPath
+	opts := m.fileTree.ScanOptions()
+	return m, func() tea.Msg {
+		paths, err := markdownFiles(root, opts)
+		if err != nil {
+			return BacklinksFoundMsg{Target: target, Err: err}
+		}
+		return BacklinksFoundMsg{Target: target, Backlinks: index.Backlinks(target, paths)}
+	}
+}
+
+// handleBacklinksFound shows the backlinks panel
+func (m Model) handleBacklinksFound(msg BacklinksFoundMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	var items []picker.Item
+	for _, backlink := range msg.Backlinks {
+		rel, err := filepath.Rel(m.RootPath, backlink.Path)
+		if err != nil {
+			rel = backlink.Path
+		}
+		items = append(items, picker.Item{
+			Label:  backlink.Snippet,
+			Detail: rel + ":" + itoa(backlink.Line),
+			Path:   backlink.Path,
+			Line:   backlink.Line,
+		})
+	}
+
+	title := "Backlinks to " + filepath.Base(msg.Target)
+	return m, m.picker.Open(backlinkPicker, title, items, "No documents link here")
+}
+
+// openPickedLine opens the file a picker item points at, scrolled to its line
+func (m Model) openPickedLine(item picker.Item) (tea.Model, tea.Cmd) {
+	if item.Path == m.preview.FilePath() {
+		m.preview.ScrollToLine(item.Line)
+		return m, nil
+	}
+	m.pendingLine = item.Line
+	return m.openDocument(item.Path, "")
+}
+
+// followWikiLink resolves a clicked [[wiki link]] by file name across the
+// docs tree; [[#heading]] links scroll the current document
+func (m Model) followWikiLink(msg preview.LinkClickedMsg) (tea.Model, tea.Cmd) {
+	name, anchor := mdlinks.SplitAnchor(msg.Target)
+	if name == "" {
+		if !m.preview.ScrollToAnchor(preview.Slug(anchor)) {
+			m.lastError = "no heading #" + anchor
+		}
+		return m, nil
+	}
+
+	root := m.RootPath
+	opts := m.fileTree.ScanOptions()
+	return m, func() tea.Msg {
+		paths, _ := markdownFiles(root, opts)
+		return WikiLinkResolvedMsg{
+			Target: msg.Target,
+			Path:   mdlinks.NewVault(paths).Resolve(msg.From, msg.Target),
+		}
+	}
+}
+
+// handleWikiLinkResolved opens the note a wiki link points at
+func (m Model) handleWikiLinkResolved(msg WikiLinkResolvedMsg) (tea.Model, tea.Cmd) {
+	name, anchor := mdlinks.SplitAnchor(msg.Target)
+	if msg.Path == "" {
+		m.lastError = "no note named " + name
+		return m, nil
+	}
+	if anchor != "" {
+		anchor = preview.Slug(anchor)
+	}
+	return m.openDocument(msg.Path, anchor)
+}
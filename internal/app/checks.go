@@ -0,0 +1,90 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/Ayushlm10/skim/internal/components/picker"
+	"github.com/Ayushlm10/skim/internal/linkcheck"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkPicker identifies the broken links panel in picker messages
+const linkPicker = "links"
+
+// LinksCheckedMsg is sent when the links under the root have been checked
+type LinksCheckedMsg struct {
+	Report linkcheck.Report
+	Err    error
+}
+
+// checkLinks checks every markdown file under the root in the background;
+// the panel opens once the report is ready
+func (m Model) checkLinks() (tea.Model, tea.Cmd) {
+	m.loading = true
+	root := m.RootPath
+	opts := m.fileTree.ScanOptions()
+	return m, func() tea.Msg {
+		paths, err := markdownFiles(root, opts)
+		if err != nil {
+			return LinksCheckedMsg{Err: err}
+		}
+		return LinksCheckedMsg{Report: linkcheck.Check(paths)}
+	}
+}
+
+// handleLinksChecked lists the broken links; picking one opens its line
+func (m Model) handleLinksChecked(msg LinksCheckedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	var items []picker.Item
+	for _, problem := range msg.Report.Problems {
+		rel, err := filepath.Rel(m.RootPath, problem.Path)
+		if err != nil {
+			rel = problem.Path
+		}
+		label := problem.Reason
+		if problem.Target != "" {
+			label = problem.Target + " · " + problem.Reason
+		}
+		items = append(items, picker.Item{
+			Label:  label,
+			Detail: rel + ":" + itoa(problem.Line),
+			Path:   problem.Path,
+			Line:   problem.Line,
+		})
+	}
+
+	report := msg.Report
+	title := "Broken links · " + itoa(len(report.Problems)) + " in " + itoa(report.Files) + " docs"
+	empty := "All " + itoa(report.Links) + " links look good"
+	return m, m.picker.Open(linkPicker, title, items, empty)
+}
+
+// lintPicker identifies the lint diagnostics panel in picker messages
+const lintPicker = "lint"
+
+// openDiagnostics lists the previewed file's lint findings
+func (m Model) openDiagnostics() (tea.Model, tea.Cmd) {
+	path := m.preview.FilePath()
+	if path == "" {
+		m.statusMessage = "open a document to see lint findings"
+		return m, nil
+	}
+
+	var items []picker.Item
+	for _, d := range m.preview.Diagnostics() {
+		items = append(items, picker.Item{
+			Label:  d.Message,
+			Detail: d.Rule + " · line " + itoa(d.Line),
+			Path:   path,
+			Line:   d.Line,
+		})
+	}
+
+	title := "Lint · " + filepath.Base(path)
+	return m, m.picker.Open(lintPicker, title, items, "No problems found")
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hint is a key hint shown in the status bar
+type hint struct {
+	key  string
+	desc string
+
+	// press is the key sent when the hint is clicked ("" if not clickable)
+	press string
+}
+
+// hintSeparator separates hints in the status bar
+var hintSeparator = styles.HelpSeparatorStyle.Render("  │  ")
+
+// statusHints returns the hints for the current mode and focused panel
+func (m Model) statusHints() []hint {
+	switch {
+	case m.fullscreen:
+		return m.fullscreenHints()
+
+	case m.filterActive:
+		return []hint{
+			{"⏎", "accept", "enter"},
+			{"Esc", "cancel", "esc"},
+		}
+
+	case m.FocusedPanel == FileTreePanel:
+		return []hint{
+			{"↑↓", "navigate", ""},
+			{"⏎", "open", "enter"},
+			{"/", "filter", "/"},
+			{"a", "new", "a"},
+			{"r", "move", "r"},
+			{"d", "delete", "d"},
+			{"i", "ignored", "i"},
+			{"f", "fullscreen", "f"},
+			{"Tab", "switch", "tab"},
+			{"?", "help", "?"},
+			{"q", "quit", "q"},
+		}
+
+	case m.preview.IsTaskMode():
+		return taskModeHints()
+
+	case m.preview.IsSearchMode():
+		return []hint{
+			{"⏎", "search", "enter"},
+			{"Esc", "cancel", "esc"},
+		}
+
+	case m.preview.HasActiveSearch():
+		return []hint{
+			{"n/N", "next/prev match", "n"},
+			{"Esc", "clear search", "esc"},
+			{"/", "new search", "/"},
+			{"?", "help", "?"},
+		}
+
+	case m.preview.IsTable():
+		return []hint{
+			{"↑↓", "scroll", ""},
+			{"h/l", "columns", "l"},
+			{"s/S", "sort/reverse", "s"},
+			{"/", "search", "/"},
+			{"r", "raw", "r"},
+			{"f", "fullscreen", "f"},
+			{"Tab", "switch", "tab"},
+			{"?", "help", "?"},
+			{"q", "quit", "q"},
+		}
+
+	case m.preview.IsTree():
+		return []hint{
+			{"↑↓", "scroll", ""},
+			{"⏎", "fold", "enter"},
+			{"1-9/0", "fold to depth/unfold", "0"},
+			{"/", "search", "/"},
+			{"r", "raw", "r"},
+			{"f", "fullscreen", "f"},
+			{"Tab", "switch", "tab"},
+			{"?", "help", "?"},
+			{"q", "quit", "q"},
+		}
+	}
+
+	return m.withDocumentHints([]hint{
+		{"↑↓", "scroll", ""},
+		{"g/G", "top/bottom", "g"},
+		{"/", "search", "/"},
+		{"r", "raw", "r"},
+		{"v", "split", "v"},
+		{"t", "tasks", "t"},
+		{"f", "fullscreen", "f"},
+		{"Tab", "switch", "tab"},
+		{"?", "help", "?"},
+		{"q", "quit", "q"},
+	})
+}
+
+// fullscreenHints returns the hints shown in fullscreen mode
+func (m Model) fullscreenHints() []hint {
+	switch {
+	case m.preview.IsTaskMode():
+		return taskModeHints()
+
+	case m.preview.IsSearchMode():
+		return []hint{
+			{"⏎", "search", "enter"},
+			{"Esc", "cancel", "esc"},
+		}
+
+	case m.preview.HasActiveSearch():
+		return []hint{
+			{"n/N", "next/prev match", "n"},
+			{"Esc", "clear search", "esc"},
+			{"/", "new search", "/"},
+			{"f", "exit fullscreen", "f"},
+		}
+
+	case m.preview.IsTable():
+		return []hint{
+			{"↑↓", "scroll", ""},
+			{"h/l", "columns", "l"},
+			{"s/S", "sort/reverse", "s"},
+			{"/", "search", "/"},
+			{"r", "raw", "r"},
+			{"f/Esc", "exit fullscreen", "f"},
+			{"?", "help", "?"},
+			{"q", "quit", "q"},
+		}
+
+	case m.preview.IsTree():
+		return []hint{
+			{"↑↓", "scroll", ""},
+			{"⏎", "fold", "enter"},
+			{"1-9/0", "fold to depth/unfold", "0"},
+			{"/", "search", "/"},
+			{"r", "raw", "r"},
+			{"f/Esc", "exit fullscreen", "f"},
+			{"?", "help", "?"},
+			{"q", "quit", "q"},
+		}
+	}
+
+	return m.withDocumentHints([]hint{
+		{"↑↓", "scroll", ""},
+		{"g/G", "top/bottom", "g"},
+		{"/", "search", "/"},
+		{"r", "raw", "r"},
+		{"v", "split", "v"},
+		{"t", "tasks", "t"},
+		{"f/Esc", "exit fullscreen", "f"},
+		{"?", "help", "?"},
+		{"q", "quit", "q"},
+	})
+}
+
+// withDocumentHints adds the sideways scroll keys after the top/bottom hint
+// when wide blocks scroll, and the diagram source toggle after the split
+// hint when the document has diagram blocks
+func (m Model) withDocumentHints(hints []hint) []hint {
+	if m.preview.CanScrollSideways() {
+		hints = insertHint(hints, "g/G", hint{"h/l", "scroll sideways", "l"})
+	}
+	if m.preview.HasDiagrams() {
+		desc := "diagram source"
+		if m.preview.IsDiagramSource() {
+			desc = "draw diagrams"
+		}
+		hints = insertHint(hints, "v", hint{"d", desc, "d"})
+	}
+	return hints
+}
+
+// insertHint adds a hint after the one for key, or leaves the hints as
+// they are without it
+func insertHint(hints []hint, key string, h hint) []hint {
+	for i := range hints {
+		if hints[i].key == key {
+			return append(hints[:i+1], append([]hint{h}, hints[i+1:]...)...)
+		}
+	}
+	return hints
+}
+
+// taskModeHints returns the status bar hints shown while selecting tasks
+func taskModeHints() []hint {
+	return []hint{
+		{"↑↓", "select task", ""},
+		{"Space", "toggle", " "},
+		{"t/Esc", "done", "esc"},
+	}
+}
+
+// renderHints renders hints separated for the status bar
+func renderHints(hints []hint) string {
+	var parts []string
+	for _, h := range hints {
+		parts = append(parts, renderHint(h))
+	}
+	return strings.Join(parts, hintSeparator)
+}
+
+// renderHint renders a single key and description
+func renderHint(h hint) string {
+	return styles.HelpKeyStyle.Render(h.key) + " " + styles.HelpDescStyle.Render(h.desc)
+}
+
+// hintAt returns the clickable hint at column x of the status bar
+func (m Model) hintAt(x int) (hint, bool) {
+	pos := styles.StatusBarStyle.GetPaddingLeft()
+	for _, h := range m.statusHints() {
+		width := lipgloss.Width(renderHint(h))
+		if x >= pos && x < pos+width {
+			return h, h.press != ""
+		}
+		pos += width + lipgloss.Width(hintSeparator)
+	}
+	return hint{}, false
+}
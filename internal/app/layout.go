@@ -0,0 +1,323 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Layout selects how the file tree and preview panels are arranged
+type Layout int
+
+const (
+	// LayoutAuto places panels side by side, stacking them on narrow terminals
+	LayoutAuto Layout = iota
+
+	// LayoutSide always places the tree left of the preview
+	LayoutSide
+
+	// LayoutStacked always places the tree above the preview
+	LayoutStacked
+
+	layoutCount
+)
+
+// stackBelowWidth is the terminal width under which the auto layout stacks panels
+const stackBelowWidth = 80
+
+// ratioStep is how much the tree's share changes per resize key press
+const ratioStep = 0.05
+
+// Minimum stacked panel heights (inner, without borders)
+const (
+	minStackedTree    = 3
+	minStackedPreview = 5
+)
+
+// String returns the layout's name as stored in the config file
+func (l Layout) String() string {
+	switch l {
+	case LayoutSide:
+		return "side"
+	case LayoutStacked:
+		return "stacked"
+	default:
+		return "auto"
+	}
+}
+
+// Next returns the following layout, wrapping around
+func (l Layout) Next() Layout {
+	return (l + 1) % layoutCount
+}
+
+// ParseLayout parses a layout name, defaulting to LayoutAuto
+func ParseLayout(name string) Layout {
+	for l := LayoutAuto; l < layoutCount; l++ {
+		if l.String() == name {
+			return l
+		}
+	}
+	return LayoutAuto
+}
+
+// rect is a screen area
+type rect struct {
+	x, y, w, h int
+}
+
+// contains reports whether a screen cell lies inside the area
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// stacked reports whether the panels are arranged vertically
+func (m Model) stacked() bool {
+	switch m.layout {
+	case LayoutStacked:
+		return true
+	case LayoutSide:
+		return false
+	}
+	return m.Width < stackBelowWidth
+}
+
+// treeShown reports whether the tree panel is drawn (as a panel or popup)
+func (m Model) treeShown() bool {
+	return !m.treeHidden || m.treePopup
+}
+
+// PanelHeights calculates the inner height of each panel in the stacked layout
+func (m Model) PanelHeights() (fileTree, preview int) {
+	// Both panels share the content area, with one extra pair of borders
+	usableHeight := m.ContentHeight() - 2
+
+	fileTree = int(float64(usableHeight) * m.treeRatio)
+	if fileTree < minStackedTree {
+		fileTree = minStackedTree
+	}
+	preview = usableHeight - fileTree
+	if preview < minStackedPreview {
+		preview = minStackedPreview
+		fileTree = usableHeight - preview
+	}
+	return fileTree, preview
+}
+
+// treeArea returns the screen area of the tree panel's content (inside the border)
+func (m Model) treeArea() rect {
+	switch {
+	case m.fullscreen || !m.treeShown():
+		return rect{}
+	case m.treePopup || !m.stacked():
+		fileTreeWidth, _ := m.PanelWidths()
+		return rect{x: 1, y: panelTop, w: fileTreeWidth, h: m.ContentHeight()}
+	}
+	fileTreeHeight, _ := m.PanelHeights()
+	return rect{x: 1, y: panelTop, w: m.Width - 2, h: fileTreeHeight}
+}
+
+// previewArea returns the screen area of the preview panel's content (inside the border)
+func (m Model) previewArea() rect {
+	switch {
+	case m.fullscreen:
+		return rect{x: 0, y: 0, w: m.Width, h: m.FullscreenContentHeight()}
+	case m.treeHidden:
+		return rect{x: 1, y: panelTop, w: m.Width - 2, h: m.ContentHeight()}
+	case m.stacked():
+		fileTreeHeight, previewHeight := m.PanelHeights()
+		return rect{x: 1, y: panelTop + fileTreeHeight + 2, w: m.Width - 2, h: previewHeight}
+	}
+	fileTreeWidth, previewWidth := m.PanelWidths()
+	return rect{x: fileTreeWidth + 3, y: panelTop, w: previewWidth, h: m.ContentHeight()}
+}
+
+// onBorder reports whether a screen cell is on the border between the two panels
+func (m Model) onBorder(x, y int) bool {
+	if m.fullscreen || m.treeHidden {
+		return false
+	}
+	tree := m.treeArea()
+	if m.stacked() {
+		return y == tree.y+tree.h || y == tree.y+tree.h+1
+	}
+	return x == tree.x+tree.w || x == tree.x+tree.w+1
+}
+
+// dragBorder moves the border between the panels to a screen cell
+func (m *Model) dragBorder(x, y int) {
+	var ratio float64
+	if m.stacked() {
+		usableHeight := m.ContentHeight() - 2
+		if usableHeight <= 0 {
+			return
+		}
+		ratio = float64(y-panelTop) / float64(usableHeight)
+	} else {
+		usableWidth := m.Width - 4
+		if usableWidth <= 0 {
+			return
+		}
+		ratio = float64(x-1) / float64(usableWidth)
+	}
+	m.setTreeRatio(ratio)
+}
+
+// setTreeRatio changes the tree's share of the split within sensible limits
+func (m *Model) setTreeRatio(ratio float64) {
+	if ratio < minTreeRatio {
+		ratio = minTreeRatio
+	}
+	if ratio > maxTreeRatio {
+		ratio = maxTreeRatio
+	}
+	m.treeRatio = ratio
+	m.resizePanels()
+}
+
+// resizePanels updates component sizes for the current layout
+func (m *Model) resizePanels() {
+	preview := m.previewArea()
+	if m.fullscreen {
+		// In fullscreen, preview gets full terminal dimensions
+		m.preview.SetSize(m.Width-2, preview.h)
+		return
+	}
+	m.preview.SetSize(preview.w-2, preview.h)
+
+	if tree := m.treeArea(); tree.w > 0 {
+		m.fileTree.SetSize(tree.w-2, tree.h)
+	}
+}
+
+// resizeTree grows or shrinks the tree panel by one step
+func (m Model) resizeTree(delta float64) (tea.Model, tea.Cmd) {
+	if m.treeHidden {
+		return m, nil
+	}
+	m.setTreeRatio(m.treeRatio + delta)
+	cmd := m.saveLayout()
+	return m, cmd
+}
+
+// toggleTree hides or shows the tree panel
+func (m Model) toggleTree() (tea.Model, tea.Cmd) {
+	m.treeHidden = !m.treeHidden
+	m.treePopup = false
+	if m.treeHidden {
+		m.FocusedPanel = PreviewPanel
+		m.statusMessage = "tree hidden (Tab to pop it up)"
+	}
+	m.resizePanels()
+	cmd := m.saveLayout()
+	return m, cmd
+}
+
+// setTreePopup opens or closes the tree popup shown while the tree is hidden
+func (m *Model) setTreePopup(open bool) {
+	m.treePopup = open
+	if open {
+		m.FocusedPanel = FileTreePanel
+	} else {
+		m.FocusedPanel = PreviewPanel
+	}
+	m.resizePanels()
+}
+
+// cycleLayout switches to the next panel arrangement
+func (m Model) cycleLayout() (tea.Model, tea.Cmd) {
+	m.layout = m.layout.Next()
+	m.statusMessage = "layout: " + m.layout.String()
+	m.resizePanels()
+	cmd := m.saveLayout()
+	return m, cmd
+}
+
+// cycleOverflow switches how code blocks and tables wider than the preview
+// are shown: wrapped, scrolling on their own or scrolling the document
+func (m Model) cycleOverflow() (tea.Model, tea.Cmd) {
+	m.preview.SetOverflow(m.preview.Overflow().Next())
+	m.statusMessage = "wide blocks: " + m.preview.Overflow().String()
+	cmd := m.saveLayout()
+	return m, cmd
+}
+
+// toggleReading keeps the preview's rendered text to a centered column, or
+// lets it fill the panel
+func (m Model) toggleReading() (tea.Model, tea.Cmd) {
+	m.preview.SetReadingMode(!m.preview.IsReadingMode())
+	m.statusMessage = "reading mode: off"
+	if m.preview.IsReadingMode() {
+		m.statusMessage = "reading mode: on"
+	}
+	cmd := m.saveLayout()
+	return m, cmd
+}
+
+// saveLayout persists the layout preferences. One save runs at a time;
+// changes made meanwhile are saved once it finishes.
+func (m *Model) saveLayout() tea.Cmd {
+	if m.saving {
+		m.saveAgain = true
+		return nil
+	}
+	m.saving = true
+
+	cfg := m.config
+	cfg.Layout = m.layout.String()
+	cfg.TreeRatio = m.treeRatio
+	cfg.TreeHidden = m.treeHidden
+	cfg.Overflow = m.preview.Overflow().String()
+	cfg.Reading = m.preview.IsReadingMode()
+	return func() tea.Msg {
+		if err := config.Save(cfg); err != nil {
+			return ConfigSavedMsg{Err: err}
+		}
+		return ConfigSavedMsg{Config: cfg}
+	}
+}
+
+// renderPanels renders the file tree and preview panels for the current layout
+func (m Model) renderPanels() string {
+	tree := m.treeArea()
+	preview := m.previewArea()
+
+	// Render preview panel
+	previewContent := m.renderPreview(preview.w-2, preview.h)
+	previewPanel := m.stylePanelBox(previewContent, preview.w, preview.h, m.FocusedPanel == PreviewPanel)
+	if tree.w == 0 {
+		return previewPanel
+	}
+
+	// Render file tree panel
+	fileTreeContent := m.renderFileTree(tree.w-2, tree.h)
+	fileTreePanel := m.stylePanelBox(fileTreeContent, tree.w, tree.h, m.FocusedPanel == FileTreePanel)
+
+	switch {
+	case m.treePopup:
+		return overlayLeft(previewPanel, fileTreePanel)
+	case m.stacked():
+		return fileTreePanel + "\n" + previewPanel
+	}
+
+	// Join panels horizontally
+	return lipgloss.JoinHorizontal(lipgloss.Top, fileTreePanel, previewPanel)
+}
+
+// overlayLeft draws popup over the left edge of base, line by line
+func overlayLeft(base, popup string) string {
+	baseLines := strings.Split(base, "\n")
+	popupLines := strings.Split(popup, "\n")
+
+	for i, line := range popupLines {
+		if i >= len(baseLines) {
+			break
+		}
+		width := ansi.StringWidth(line)
+		baseLines[i] = line + ansi.Cut(baseLines[i], width, ansi.StringWidth(baseLines[i]))
+	}
+	return strings.Join(baseLines, "\n")
+}
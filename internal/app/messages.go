@@ -1,5 +1,7 @@
 package app
 
+import "github.com/Ayushlm10/skim/internal/config"
+
 // Panel represents which panel has focus
 type Panel int
 
@@ -61,3 +63,15 @@ type WindowSizeMsg struct {
 	Width  int
 	Height int
 }
+
+// ExternalOpenedMsg is sent after a link has been handed to the system opener
+type ExternalOpenedMsg struct {
+	Target string
+	Err    error
+}
+
+// ConfigSavedMsg is sent after preferences have been written to the config file
+type ConfigSavedMsg struct {
+	Config config.Config
+	Err    error
+}
@@ -1,9 +1,16 @@
 package app
 
 import (
+	"time"
+
 	"github.com/Ayushlm10/skim/internal/components/filetree"
 	"github.com/Ayushlm10/skim/internal/components/help"
+	"github.com/Ayushlm10/skim/internal/components/picker"
 	"github.com/Ayushlm10/skim/internal/components/preview"
+	"github.com/Ayushlm10/skim/internal/config"
+	"github.com/Ayushlm10/skim/internal/images"
+	"github.com/Ayushlm10/skim/internal/index"
+	"github.com/Ayushlm10/skim/internal/lint"
 	"github.com/Ayushlm10/skim/internal/styles"
 	"github.com/Ayushlm10/skim/internal/watcher"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,18 +37,41 @@ type Model struct {
 	// Help overlay (Phase 6)
 	help help.Model
 
+	// Popup list for tags, backlinks and other document-wide results
+	picker   picker.Model
+	tagIndex *index.Index
+
 	// File watcher (Phase 5)
 	watcher     *watcher.Watcher
 	watchedFile string
 
 	// UI state
-	ready        bool
-	filterActive bool
-	filterText   string
-	loading      bool
-	lastError    string
-	showIgnored  bool // Whether ignored directories are visible
-	fullscreen   bool // Whether preview is in fullscreen mode
+	ready         bool
+	filterActive  bool
+	filterText    string
+	loading       bool
+	lastError     string
+	statusMessage string // Result of the last file operation
+	showIgnored   bool   // Whether ignored directories are visible
+	fullscreen    bool   // Whether preview is in fullscreen mode
+	zen           bool   // Whether fullscreen hides the status bar too
+	pendingAnchor string // Heading to scroll to once a followed link loads
+	pendingLine   int    // Source line (1-based) to scroll to once a picked file loads
+
+	// Layout (persisted in the config file)
+	config     config.Config
+	layout     Layout
+	treeRatio  float64 // File tree share of the split
+	treeHidden bool    // Whether the tree panel is hidden
+	treePopup  bool    // Whether the hidden tree is shown as a popup
+	saving     bool    // Whether the config file is being written
+	saveAgain  bool    // Whether the layout changed during the save
+
+	// Mouse state
+	dragging   bool      // Whether the panel border is being dragged
+	lastClick  time.Time // Time and position of the last click, for double-clicks
+	lastClickX int
+	lastClickY int
 }
 
 // New creates a new application model
@@ -52,21 +82,51 @@ func New(rootPath string) Model {
 	// Create preview component with initial dimensions
 	pv := preview.New(60, 20)
 
+	// Lint rules come from the nearest .skimlint.json
+	lintCfg, lintErr := lint.LoadConfig(rootPath)
+	pv.SetLintConfig(lintCfg)
+
 	// Create help overlay
 	h := help.New()
 
 	// Create file watcher
 	w, _ := watcher.New()
 
-	return Model{
+	// Restore the saved layout
+	cfg := config.Load()
+	ratio := cfg.TreeRatio
+	if ratio < minTreeRatio || ratio > maxTreeRatio {
+		ratio = styles.FileTreeRatio
+	}
+
+	m := Model{
 		RootPath:     rootPath,
 		FocusedPanel: FileTreePanel,
 		fileTree:     ft,
 		preview:      pv,
 		help:         h,
+		picker:       picker.New(),
 		watcher:      w,
 		ready:        false,
+		config:       cfg,
+		layout:       ParseLayout(cfg.Layout),
+		treeRatio:    ratio,
+		treeHidden:   cfg.TreeHidden,
 	}
+	if m.treeHidden {
+		m.FocusedPanel = PreviewPanel
+	}
+	if len(cfg.Extensions) > 0 {
+		m.fileTree.SetExtensions(cfg.Extensions)
+	}
+	m.preview.SetImageProtocol(images.ParseProtocol(cfg.Images))
+	m.preview.SetOverflow(preview.ParseOverflow(cfg.Overflow))
+	m.preview.SetMaxWidth(cfg.MaxWidth)
+	m.preview.SetReadingMode(cfg.Reading)
+	if lintErr != nil {
+		m.lastError = lintErr.Error()
+	}
+	return m
 }
 
 // Init initializes the model and returns an initial command
@@ -83,7 +143,7 @@ func (m Model) PanelWidths() (fileTree, preview int) {
 	// Account for borders (2 chars each panel)
 	usableWidth := m.Width - 4
 
-	fileTree = int(float64(usableWidth) * styles.FileTreeRatio)
+	fileTree = int(float64(usableWidth) * m.treeRatio)
 	preview = usableWidth - fileTree
 
 	// Ensure minimum widths
@@ -114,6 +174,9 @@ func (m Model) ContentHeight() int {
 
 // FullscreenContentHeight returns the height available for preview in fullscreen mode
 func (m Model) FullscreenContentHeight() int {
+	if m.zen {
+		return m.Height
+	}
 	// Total height minus:
 	// - newline before status bar (1 line)
 	// - status bar (1 line)
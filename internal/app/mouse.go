@@ -0,0 +1,240 @@
+package app
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Ayushlm10/skim/internal/components/preview"
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is the longest gap between the clicks of a double-click
+const doubleClickInterval = 400 * time.Millisecond
+
+// Limits for the tree panel's share of the width when dragging the border
+const (
+	minTreeRatio = 0.1
+	maxTreeRatio = 0.7
+)
+
+// panelTop is the first screen row of panel content (below the header and top border)
+const panelTop = 2
+
+// handleMouse routes mouse events to the appropriate panel based on X coordinate
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.help.IsVisible() || m.picker.IsOpen() {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown,
+		msg.Button == tea.MouseButtonWheelLeft || msg.Button == tea.MouseButtonWheelRight:
+		return m.handleWheel(msg)
+
+	case msg.Action == tea.MouseActionMotion && m.dragging:
+		m.dragBorder(msg.X, msg.Y)
+		return m, nil
+
+	case msg.Action == tea.MouseActionRelease:
+		if m.dragging {
+			m.dragging = false
+			cmd := m.saveLayout()
+			return m, cmd
+		}
+		return m, nil
+
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		return m.handleClick(msg)
+	}
+
+	return m, nil
+}
+
+// handleWheel scrolls the panel under the pointer
+func (m Model) handleWheel(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Route to the tree if the pointer is over it (never in fullscreen)
+	tree := m.treeArea()
+	tree.x, tree.y, tree.w, tree.h = tree.x-1, tree.y-1, tree.w+2, tree.h+2 // include borders
+	if tree.contains(msg.X, msg.Y) {
+		// Mouse is over file tree panel
+		var cmd tea.Cmd
+		m.fileTree, cmd = m.fileTree.Update(msg)
+		return m, cmd
+	}
+
+	// Mouse is over preview panel
+	var cmd tea.Cmd
+	msg.X -= m.previewArea().x
+	m.preview, cmd = m.preview.HandleMouse(msg)
+	return m, cmd
+}
+
+// handleClick handles a left click: status bar hints, the panel border, tree items and preview links
+func (m Model) handleClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	now := time.Now()
+	double := now.Sub(m.lastClick) < doubleClickInterval && msg.X == m.lastClickX && msg.Y == m.lastClickY
+	m.lastClick, m.lastClickX, m.lastClickY = now, msg.X, msg.Y
+
+	// Status bar hints act like their keys
+	if msg.Y == m.statusBarRow() {
+		if h, ok := m.hintAt(msg.X); ok {
+			return m.handleKeypress(keyMsg(h.press))
+		}
+		return m, nil
+	}
+
+	// Grab the border between the panels
+	if m.onBorder(msg.X, msg.Y) {
+		m.dragging = true
+		return m, nil
+	}
+
+	if tree := m.treeArea(); tree.contains(msg.X, msg.Y) {
+		m.FocusedPanel = FileTreePanel
+		var cmd tea.Cmd
+		m.fileTree, cmd = m.fileTree.HandleClick(msg.X-tree.x, msg.Y-tree.y, double)
+		return m, cmd
+	}
+
+	// Clicking outside the tree popup closes it
+	if m.treePopup {
+		m.setTreePopup(false)
+	}
+
+	if preview := m.previewArea(); preview.contains(msg.X, msg.Y) {
+		m.FocusedPanel = PreviewPanel
+		var cmd tea.Cmd
+		m.preview, cmd = m.preview.HandleClick(msg.X-preview.x, msg.Y-preview.y)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// statusBarRow returns the screen row of the status bar, or -1 in zen mode
+func (m Model) statusBarRow() int {
+	if m.zen {
+		return -1
+	}
+	if m.fullscreen {
+		return m.FullscreenContentHeight()
+	}
+	// Header, then the panels with their borders
+	return panelTop + m.ContentHeight() + 1
+}
+
+// keyMsg builds the key message for a key name as reported by tea.KeyMsg.String
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// openSchemes are the URL schemes handed to the system opener. Others, such
+// as file: or custom protocol handlers, could launch programs from a click.
+var openSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// followLink opens the target of a clicked preview link: anchors scroll the
+// preview, local documents open in it and web and mail links go to the
+// system opener. Other links and files skim can't preview are refused.
+func (m Model) followLink(msg preview.LinkClickedMsg) (tea.Model, tea.Cmd) {
+	if msg.Wiki {
+		return m.followWikiLink(msg)
+	}
+	if mdlinks.IsExternal(msg.Target) {
+		target, ok := openableURL(msg.Target)
+		if !ok {
+			m.lastError = "not opening " + msg.Target + ": only http, https and mailto links open outside skim"
+			return m, nil
+		}
+		m.statusMessage = "opening " + target
+		return m, openExternal(target)
+	}
+
+	path, anchor := mdlinks.SplitAnchor(msg.Target)
+	if path == "" {
+		if !m.preview.ScrollToAnchor(anchor) {
+			m.lastError = "no heading #" + anchor
+		}
+		return m, nil
+	}
+
+	resolved := mdlinks.Resolve(msg.From, msg.Target)
+	info, err := os.Stat(resolved)
+	if err != nil {
+		m.lastError = "link target not found: " + path
+		return m, nil
+	}
+
+	if info.IsDir() {
+		m.FocusedPanel = FileTreePanel
+		return m, m.fileTree.Reveal(resolved)
+	}
+
+	// Documents and source files open in the preview. Other files are not
+	// handed to the system opener, which would run executables.
+	if !m.fileTree.ScanOptions().IsDocument(resolved) && !format.For(resolved).Code {
+		m.lastError = "skim can't preview " + filepath.Base(resolved)
+		return m, nil
+	}
+
+	return m.openDocument(resolved, anchor)
+}
+
+// openDocument loads a document into the preview, scrolling to anchor
+// once it loads, and reveals it in the tree
+func (m Model) openDocument(path, anchor string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.lastError = ""
+	m.pendingAnchor = anchor
+	return m, tea.Batch(preview.LoadFile(path), m.fileTree.Reveal(path))
+}
+
+// openableURL returns an external link target as the URL to open, and
+// whether its scheme may go to the system opener. Scheme-relative links
+// ("//host/path") are opened over https.
+func openableURL(target string) (string, bool) {
+	if strings.HasPrefix(target, "//") {
+		target = "https:" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil || !openSchemes[strings.ToLower(u.Scheme)] {
+		return "", false
+	}
+	return target, true
+}
+
+// openExternal hands a web or mail URL to the operating system's default opener
+func openExternal(target string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", target)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+		default:
+			cmd = exec.Command("xdg-open", target)
+		}
+
+		if err := cmd.Start(); err != nil {
+			return ExternalOpenedMsg{Target: target, Err: err}
+		}
+		go func() { _ = cmd.Wait() }()
+		return ExternalOpenedMsg{Target: target}
+	}
+}
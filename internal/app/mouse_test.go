@@ -0,0 +1,27 @@
+package app
+
+import "testing"
+
+func TestOpenableURL(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+		ok     bool
+	}{
+		{"https://example.com/a?b=c", "https://example.com/a?b=c", true},
+		{"HTTP://example.com", "HTTP://example.com", true},
+		{"mailto:ann@example.com", "mailto:ann@example.com", true},
+		{"//example.com/x", "https://example.com/x", true},
+		{"file:///usr/bin/xterm", "", false},
+		{"javascript:alert(1)", "", false},
+		{"ssh://host", "", false},
+		{"steam://run/1", "", false},
+		{"vscode://file/etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := openableURL(tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("openableURL(%q) = %q, %v; want %q, %v", tt.target, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/components/filetree"
+	"github.com/Ayushlm10/skim/internal/components/picker"
+	"github.com/Ayushlm10/skim/internal/index"
+	"github.com/Ayushlm10/skim/internal/walk"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tagPicker identifies the tag browser in picker messages
+const tagPicker = "tags"
+
+// IndexBuiltMsg is sent when the tag and metadata index has been built
+type IndexBuiltMsg struct {
+	Index *index.Index
+	Err   error
+}
+
+// buildIndex indexes the markdown files under the root in the background
+func (m Model) buildIndex() tea.Cmd {
+	root := m.RootPath
+	opts := m.fileTree.ScanOptions()
+	return func() tea.Msg {
+		paths, err := markdownFiles(root, opts)
+		if err != nil {
+			return IndexBuiltMsg{Err: err}
+		}
+		return IndexBuiltMsg{Index: index.Build(paths)}
+	}
+}
+
+// markdownFiles lists the markdown files under root that the tree shows
+func markdownFiles(root string, opts filetree.ScanOptions) ([]string, error) {
+	var paths []string
+	err := walk.Markdown(root, opts.Options, func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// openTagBrowser starts indexing; the browser opens once the index is ready
+func (m Model) openTagBrowser() (tea.Model, tea.Cmd) {
+	m.loading = true
+	return m, m.buildIndex()
+}
+
+// handleIndexBuilt shows the tag browser for a freshly built index
+func (m Model) handleIndexBuilt(msg IndexBuiltMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.Err != nil {
+		m.lastError = msg.Err.Error()
+		return m, nil
+	}
+
+	var items []picker.Item
+	for _, entry := range msg.Index.Entries() {
+		items = append(items, picker.Item{
+			Label:  entry.Label(),
+			Detail: itoa(len(entry.Paths)),
+			Value:  entry.Field + "\x00" + entry.Value,
+		})
+	}
+
+	m.tagIndex = msg.Index
+	title := "Tags & metadata · " + itoa(msg.Index.Files) + " docs"
+	return m, m.picker.Open(tagPicker, title, items, "No tags or front matter fields found")
+}
+
+// applyTag filters the tree to the documents with a chosen tag or field value
+func (m Model) applyTag(item picker.Item) (tea.Model, tea.Cmd) {
+	if m.tagIndex == nil {
+		return m, nil
+	}
+
+	field, value, _ := strings.Cut(item.Value, "\x00")
+	paths := m.tagIndex.Lookup(field, value)
+
+	if m.treeHidden {
+		m.setTreePopup(true)
+	}
+	m.FocusedPanel = FileTreePanel
+	return m, m.fileTree.SetDocumentFilter(item.Label, paths)
+}
@@ -1,14 +1,32 @@
 package app
 
 import (
+	"strings"
+
 	"github.com/Ayushlm10/skim/internal/components/filetree"
+	"github.com/Ayushlm10/skim/internal/components/picker"
 	"github.com/Ayushlm10/skim/internal/components/preview"
+	"github.com/Ayushlm10/skim/internal/fsutil"
 	"github.com/Ayushlm10/skim/internal/watcher"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Update handles messages and updates the model
+// Update handles messages and updates the model, then starts decoding the
+// images the preview is waiting on and moves the kitty images to where the
+// preview now shows them. View stays free of side effects.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	next, ok := model.(Model)
+	if !ok {
+		return model, cmd
+	}
+	decode := next.preview.DecodeImages()
+	place := next.placeImages()
+	return next, tea.Batch(cmd, decode, place)
+}
+
+// update handles one message
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -19,17 +37,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Height = msg.Height
 		m.ready = true
 
-		if m.fullscreen {
-			// In fullscreen, preview gets full terminal dimensions
-			m.preview.SetSize(m.Width-2, m.FullscreenContentHeight())
-		} else {
-			// Normal mode: update component sizes with panel split
-			fileTreeWidth, previewWidth := m.PanelWidths()
-			contentHeight := m.ContentHeight()
-			m.fileTree.SetSize(fileTreeWidth-2, contentHeight)
-			m.preview.SetSize(previewWidth-2, contentHeight)
-		}
-
+		m.picker.SetSize(m.Width, m.Height)
+		m.resizePanels()
 		return m, nil
 
 	// Keyboard input
@@ -70,14 +79,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// File tree component messages
 	case filetree.FileSelectedMsg:
 		// Load the file content when a file is selected in the tree
+		if m.treePopup {
+			m.setTreePopup(false)
+		}
 		m.loading = true
 		m.lastError = ""
 		return m, preview.LoadFile(msg.Path)
 
+	case filetree.DocumentFilterChangedMsg:
+		// Shown in the status bar while active
+		return m, nil
+
+	case IndexBuiltMsg:
+		return m.handleIndexBuilt(msg)
+
+	case BacklinksFoundMsg:
+		return m.handleBacklinksFound(msg)
+
+	case WikiLinkResolvedMsg:
+		return m.handleWikiLinkResolved(msg)
+
+	case LinksCheckedMsg:
+		return m.handleLinksChecked(msg)
+
+	case picker.SelectedMsg:
+		switch msg.ID {
+		case tagPicker:
+			return m.applyTag(msg.Item)
+		case backlinkPicker, linkPicker, lintPicker:
+			return m.openPickedLine(msg.Item)
+		}
+		return m, nil
+
+	case picker.ClosedMsg:
+		return m, nil
+
 	case filetree.DirectoryToggledMsg:
 		// Directory was toggled, tree already updated
 		return m, nil
 
+	case filetree.FileOpMsg:
+		return m.handleFileOp(msg)
+
+	case filetree.ViewChangedMsg:
+		m.statusMessage = msg.Message
+		return m, nil
+
 	// Preview component messages
 	case preview.FileLoadedMsg:
 		// Forward to preview component
@@ -86,19 +133,65 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loading = false
 
 		// Handle errors
+		anchor, line := m.pendingAnchor, m.pendingLine
+		m.pendingAnchor, m.pendingLine = "", 0
 		if msg.Error != nil {
 			m.lastError = msg.Error.Error()
 			return m, cmd
 		}
 
+		// Jump to the heading a followed link pointed at, or the picked line
+		if anchor != "" {
+			m.preview.ScrollToAnchor(anchor)
+		}
+		m.preview.ScrollToLine(line)
+
 		// Start watching the newly loaded file
 		m.lastError = ""
+		titleCmd := m.fileTree.RefreshTitle(msg.Path)
 		if m.watcher != nil {
 			m.watchedFile = msg.Path
-			return m, tea.Batch(cmd, watcher.StartWatching(m.watcher, msg.Path))
+			return m, tea.Batch(cmd, titleCmd, watcher.StartWatching(m.watcher, msg.Path))
 		}
+		return m, tea.Batch(cmd, titleCmd)
+
+	case preview.LinkClickedMsg:
+		return m.followLink(msg)
+
+	case preview.ImagesDecodedMsg:
+		var cmd tea.Cmd
+		m.preview, cmd = m.preview.Update(msg)
 		return m, cmd
 
+	case ConfigSavedMsg:
+		m.saving = false
+		if msg.Err != nil {
+			m.lastError = msg.Err.Error()
+		} else {
+			m.config = msg.Config
+		}
+		if m.saveAgain {
+			m.saveAgain = false
+			cmd := m.saveLayout()
+			return m, cmd
+		}
+		return m, nil
+
+	case ExternalOpenedMsg:
+		if msg.Err != nil {
+			m.lastError = msg.Err.Error()
+		}
+		return m, nil
+
+	case preview.TaskToggledMsg:
+		// The watcher picks up the write and reloads the preview
+		if msg.Error != nil {
+			m.lastError = msg.Error.Error()
+		} else {
+			m.lastError = ""
+		}
+		return m, nil
+
 	// Watcher messages (Phase 5)
 	case watcher.FileChangedMsg:
 		// File changed, reload it
@@ -122,13 +215,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, watcher.WaitForChange(m.watcher)
 	}
 
-	// Forward messages to file tree when focused
-	if m.FocusedPanel == FileTreePanel {
-		var cmd tea.Cmd
-		m.fileTree, cmd = m.fileTree.Update(msg)
-		if cmd != nil {
-			cmds = append(cmds, cmd)
-		}
+	// Forward remaining messages to the file tree; background results
+	// (scans, titles, file operations) must arrive even when it isn't focused
+	var cmd tea.Cmd
+	m.fileTree, cmd = m.fileTree.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
@@ -147,6 +239,21 @@ func (m Model) handleKeypress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// The picker captures all keys while open
+	if m.picker.IsOpen() {
+		var cmd tea.Cmd
+		m.picker, cmd = m.picker.Update(msg)
+		return m, cmd
+	}
+
+	// Any key dismisses the last file operation message
+	m.statusMessage = ""
+
+	// Tree prompts and confirmations capture all keys
+	if m.FocusedPanel == FileTreePanel && !m.fullscreen && m.fileTree.IsPrompting() {
+		return m.handleFileTreeKeys(msg)
+	}
+
 	// Global keys (work regardless of focus/mode)
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -163,6 +270,11 @@ func (m Model) handleKeypress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "tab":
 		// Switch panel focus (no-op in fullscreen since preview is always focused)
+		if m.treeHidden && !m.fullscreen {
+			// The hidden tree pops up over the preview instead
+			m.setTreePopup(!m.treePopup)
+			return m, nil
+		}
 		if !m.fullscreen {
 			if m.FocusedPanel == FileTreePanel {
 				m.FocusedPanel = PreviewPanel
@@ -178,25 +290,105 @@ func (m Model) handleKeypress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			break
 		}
 		m.fullscreen = !m.fullscreen
+		m.zen = false
 		if m.fullscreen {
 			m.FocusedPanel = PreviewPanel
-			m.preview.SetSize(m.Width-2, m.FullscreenContentHeight())
-		} else {
-			fileTreeWidth, previewWidth := m.PanelWidths()
-			contentHeight := m.ContentHeight()
-			m.fileTree.SetSize(fileTreeWidth-2, contentHeight)
-			m.preview.SetSize(previewWidth-2, contentHeight)
 		}
+		m.resizePanels()
 		return m, nil
 
+	case "Z":
+		// Zen: fullscreen without the status bar
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		m.zen = !m.zen
+		m.fullscreen = m.zen
+		if m.fullscreen {
+			m.FocusedPanel = PreviewPanel
+		}
+		m.resizePanels()
+		return m, nil
+
+	case "z":
+		// Keep the preview's text to a centered column
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.toggleReading()
+
+	case "<", ">", "\\", "|":
+		// Layout keys; not while typing a search or filter
+		if m.fullscreen || m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		switch msg.String() {
+		case "<":
+			return m.resizeTree(-ratioStep)
+		case ">":
+			return m.resizeTree(ratioStep)
+		case "|":
+			return m.cycleLayout()
+		}
+		return m.toggleTree()
+
+	case ".":
+		// Reveal the previewed file in the tree
+		if m.fullscreen || m.preview.IsSearchMode() || m.preview.IsTaskMode() || m.filterActive {
+			break
+		}
+		if m.treeHidden {
+			m.setTreePopup(true)
+		}
+		m.FocusedPanel = FileTreePanel
+		return m, m.fileTree.Reveal(m.preview.FilePath())
+
+	case "#":
+		// Browse tags and front matter values
+		if m.fullscreen || m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.openTagBrowser()
+
+	case "b":
+		// Show the documents linking to the previewed file
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.openBacklinks()
+
+	case "L":
+		// Check links across the docs tree
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.checkLinks()
+
+	case "D":
+		// List lint findings for the previewed file
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.openDiagnostics()
+
+	case "w":
+		// Wrap wide code blocks and tables, or scroll them sideways
+		if m.preview.IsSearchMode() || m.filterActive {
+			break
+		}
+		return m.cycleOverflow()
+
 	case "esc":
+		// Close the tree popup (unless the tree's filter consumes Esc)
+		if m.treePopup && !m.fullscreen && !m.filterActive && m.fileTree.FilterValue() == "" {
+			m.setTreePopup(false)
+			return m, nil
+		}
+
 		// Exit fullscreen if active (and no search/filter is consuming Esc)
-		if m.fullscreen && !m.preview.IsSearchMode() && !m.preview.HasActiveSearch() {
-			m.fullscreen = false
-			fileTreeWidth, previewWidth := m.PanelWidths()
-			contentHeight := m.ContentHeight()
-			m.fileTree.SetSize(fileTreeWidth-2, contentHeight)
-			m.preview.SetSize(previewWidth-2, contentHeight)
+		if m.fullscreen && !m.preview.IsSearchMode() && !m.preview.HasActiveSearch() && !m.preview.IsTaskMode() {
+			m.fullscreen, m.zen = false, false
+			m.resizePanels()
 			return m, nil
 		}
 	}
@@ -217,6 +409,35 @@ func (m Model) handleKeypress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleFileOp reports a file management result and keeps the preview in sync
+func (m Model) handleFileOp(msg filetree.FileOpMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != nil {
+		m.lastError = msg.Error.Error()
+		return m, nil
+	}
+	m.lastError = ""
+	m.statusMessage = msg.Message
+
+	current := m.preview.FilePath()
+	if current == "" || msg.Path == "" || !fsutil.IsWithin(current, msg.Path) {
+		return m, nil
+	}
+
+	switch msg.Op {
+	case "renamed":
+		// Follow the open file to its new location
+		return m, preview.LoadFile(msg.NewPath + strings.TrimPrefix(current, msg.Path))
+	case "deleted":
+		// The open file is gone; stop watching it
+		if m.watcher != nil {
+			m.watcher.Unwatch()
+		}
+		m.watchedFile = ""
+		m.preview.Clear()
+	}
+	return m, nil
+}
+
 // handleFileTreeKeys handles keys when file tree is focused
 func (m Model) handleFileTreeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Delegate to file tree component
@@ -232,35 +453,3 @@ func (m Model) handlePreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.preview, cmd = m.preview.HandleKey(msg)
 	return m, cmd
 }
-
-// handleMouse routes mouse events to the appropriate panel based on X coordinate
-func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Only handle mouse wheel events for scrolling
-	if msg.Button != tea.MouseButtonWheelUp && msg.Button != tea.MouseButtonWheelDown {
-		return m, nil
-	}
-
-	// In fullscreen, all mouse events go to preview
-	if m.fullscreen {
-		var cmd tea.Cmd
-		m.preview, cmd = m.preview.HandleMouse(msg)
-		return m, cmd
-	}
-
-	// Calculate panel boundary (file tree width + left border)
-	fileTreeWidth, _ := m.PanelWidths()
-	panelBoundary := fileTreeWidth + 2 // +2 for left panel border
-
-	// Route to appropriate panel based on mouse X position
-	if msg.X < panelBoundary {
-		// Mouse is over file tree panel
-		var cmd tea.Cmd
-		m.fileTree, cmd = m.fileTree.Update(msg)
-		return m, cmd
-	}
-
-	// Mouse is over preview panel
-	var cmd tea.Cmd
-	m.preview, cmd = m.preview.HandleMouse(msg)
-	return m, cmd
-}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFullscreenKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	keys := []struct {
+		key        string
+		fullscreen bool
+		zen        bool
+		height     int
+	}{
+		{"f", true, false, 38},
+		{"Z", true, true, 40},
+		{"Z", false, false, 38},
+		{"Z", true, true, 40},
+		{"f", false, false, 38},
+		{"Z", true, true, 40},
+		{"esc", false, false, 38},
+	}
+
+	var model tea.Model = New(t.TempDir())
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for i, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k.key)}
+		if k.key == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		model, _ = model.Update(msg)
+
+		m := model.(Model)
+		if m.fullscreen != k.fullscreen || m.zen != k.zen {
+			t.Fatalf("key %d (%s): fullscreen = %v, zen = %v; want %v, %v",
+				i, k.key, m.fullscreen, m.zen, k.fullscreen, k.zen)
+		}
+		if got := m.FullscreenContentHeight(); got != k.height {
+			t.Errorf("key %d (%s): fullscreen height = %d, want %d", i, k.key, got, k.height)
+		}
+	}
+}
+
+func TestReadingModeKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var model tea.Model = New(t.TempDir())
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, want := range []string{"reading mode: on", "reading mode: off"} {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+		if got := model.(Model).statusMessage; got != want {
+			t.Errorf("status = %q, want %q", got, want)
+		}
+	}
+}
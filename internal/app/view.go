@@ -4,7 +4,9 @@ import (
 	"strings"
 
 	"github.com/Ayushlm10/skim/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // View renders the application UI
@@ -16,10 +18,12 @@ func (m Model) View() string {
 	var b strings.Builder
 
 	if m.fullscreen {
-		// Fullscreen: preview content + status bar only
+		// Fullscreen: preview content + status bar only (zen leaves the status bar out)
 		b.WriteString(m.renderFullscreenPreview())
-		b.WriteString("\n")
-		b.WriteString(m.renderStatusBar())
+		if !m.zen {
+			b.WriteString("\n")
+			b.WriteString(m.renderStatusBar())
+		}
 	} else {
 		// Normal mode: header + panels + status bar
 		b.WriteString(m.renderHeader())
@@ -31,12 +35,37 @@ func (m Model) View() string {
 
 	baseView := b.String()
 
+	// Images the terminal draws go over the finished frame, hidden while a
+	// popup covers the preview
+	area := m.previewArea()
+	graphics := m.preview.Graphics(area.x, area.y, m.imagesCovered())
+
 	// Overlay help if visible
 	if m.help.IsVisible() {
-		return m.overlayHelp(baseView)
+		return m.overlayHelp(baseView) + graphics
 	}
 
-	return baseView
+	// Overlay the picker if open
+	if m.picker.IsOpen() {
+		return overlayCenter(baseView, m.picker.View(), m.Width, m.Height) + graphics
+	}
+
+	return baseView + graphics
+}
+
+// imagesCovered reports whether a popup is drawn over the preview's images
+func (m Model) imagesCovered() bool {
+	return m.help.IsVisible() || m.picker.IsOpen()
+}
+
+// placeImages moves the kitty images to where the preview shows them,
+// returning the command that sends the terminal the changes
+func (m Model) placeImages() tea.Cmd {
+	if !m.ready {
+		return nil
+	}
+	area := m.previewArea()
+	return m.preview.PlaceImages(area.x, area.y, m.imagesCovered())
 }
 
 // renderHeader renders the top header bar
@@ -61,23 +90,6 @@ func (m Model) renderHeader() string {
 	return title + spacer + pathStr
 }
 
-// renderPanels renders the file tree and preview panels side by side
-func (m Model) renderPanels() string {
-	fileTreeWidth, previewWidth := m.PanelWidths()
-	contentHeight := m.ContentHeight()
-
-	// Render file tree panel
-	fileTreeContent := m.renderFileTree(fileTreeWidth-2, contentHeight)
-	fileTreePanel := m.stylePanelBox(fileTreeContent, fileTreeWidth, contentHeight, m.FocusedPanel == FileTreePanel)
-
-	// Render preview panel
-	previewContent := m.renderPreview(previewWidth-2, contentHeight)
-	previewPanel := m.stylePanelBox(previewContent, previewWidth, contentHeight, m.FocusedPanel == PreviewPanel)
-
-	// Join panels horizontally
-	return lipgloss.JoinHorizontal(lipgloss.Top, fileTreePanel, previewPanel)
-}
-
 // renderFullscreenPreview renders the preview taking the full terminal area
 func (m Model) renderFullscreenPreview() string {
 	content := m.preview.View()
@@ -158,70 +170,8 @@ func (m Model) renderStatusBar() string {
 		return m.renderFilterStatusBar()
 	}
 
-	// Build help hints based on focused panel
-	var hints []struct {
-		key  string
-		desc string
-	}
-
-	if m.FocusedPanel == FileTreePanel {
-		hints = []struct {
-			key  string
-			desc string
-		}{
-			{"↑↓", "navigate"},
-			{"⏎", "open"},
-			{"/", "filter"},
-			{"i", "ignored"},
-			{"f", "fullscreen"},
-			{"Tab", "switch"},
-			{"?", "help"},
-			{"q", "quit"},
-		}
-	} else {
-		// Preview panel - show search mode or normal hints
-		if m.preview.IsSearchMode() {
-			hints = []struct {
-				key  string
-				desc string
-			}{
-				{"⏎", "search"},
-				{"Esc", "cancel"},
-			}
-		} else if m.preview.HasActiveSearch() {
-			hints = []struct {
-				key  string
-				desc string
-			}{
-				{"n/N", "next/prev match"},
-				{"Esc", "clear search"},
-				{"/", "new search"},
-				{"?", "help"},
-			}
-		} else {
-			hints = []struct {
-				key  string
-				desc string
-			}{
-				{"↑↓", "scroll"},
-				{"g/G", "top/bottom"},
-				{"/", "search"},
-				{"f", "fullscreen"},
-				{"Tab", "switch"},
-				{"?", "help"},
-				{"q", "quit"},
-			}
-		}
-	}
-
-	var parts []string
-	for _, h := range hints {
-		part := styles.HelpKeyStyle.Render(h.key) + " " + styles.HelpDescStyle.Render(h.desc)
-		parts = append(parts, part)
-	}
-
-	separator := styles.HelpSeparatorStyle.Render("  │  ")
-	statusContent := strings.Join(parts, separator)
+	// Help hints for the focused panel
+	statusContent := renderHints(m.statusHints())
 
 	// Build right-side status info
 	var rightInfo string
@@ -234,6 +184,9 @@ func (m Model) renderStatusBar() string {
 			errMsg = errMsg[:27] + "..."
 		}
 		rightInfo = styles.StatusErrorStyle.Render("error: " + errMsg)
+	} else if m.statusMessage != "" {
+		// Show the result of the last file operation
+		rightInfo = styles.StatusMessageStyle.Render(m.statusMessage)
 	} else if m.loading {
 		// Show loading indicator
 		rightInfo = styles.StatusLoadingStyle.Render("loading...")
@@ -262,6 +215,11 @@ func (m Model) renderStatusBar() string {
 		// Show active filter
 		rightInfo = styles.FilterPromptStyle.Render("filter: ") +
 			styles.StatusValueStyle.Render(m.filterText)
+	} else if label, count, ok := m.fileTree.DocumentFilter(); ok && m.FocusedPanel == FileTreePanel {
+		// Show the tag or field value the tree is limited to
+		rightInfo = styles.FilterPromptStyle.Render("showing: ") +
+			styles.StatusValueStyle.Render(label) +
+			styles.HelpDescStyle.Render(" ("+itoa(count)+")")
 	} else if m.FocusedPanel == PreviewPanel && m.preview.FilePath() != "" {
 		// Show file info when preview focused (no active search)
 		scrollPct := int(m.preview.ScrollPercent() * 100)
@@ -276,9 +234,9 @@ func (m Model) renderStatusBar() string {
 			watchIndicator = styles.StatusWatchingStyle.Render(" [watching]")
 		}
 
-		rightInfo = fileName + watchIndicator + " " + scrollIndicator
+		rightInfo = fileName + watchIndicator + m.modeIndicator() + m.taskIndicator() + m.lintIndicator() + " " + scrollIndicator
 	} else if m.FocusedPanel == FileTreePanel && m.showIgnored {
-		// Show indicator when ignored directories are visible
+		// Show indicator when ignored entries are visible
 		rightInfo = styles.StatusIgnoredStyle.Render("[showing ignored]")
 	}
 
@@ -299,22 +257,7 @@ func (m Model) renderStatusBar() string {
 
 // renderFilterStatusBar renders the status bar during filter mode
 func (m Model) renderFilterStatusBar() string {
-	hints := []struct {
-		key  string
-		desc string
-	}{
-		{"⏎", "accept"},
-		{"Esc", "cancel"},
-	}
-
-	var parts []string
-	for _, h := range hints {
-		part := styles.HelpKeyStyle.Render(h.key) + " " + styles.HelpDescStyle.Render(h.desc)
-		parts = append(parts, part)
-	}
-
-	separator := styles.HelpSeparatorStyle.Render("  │  ")
-	statusContent := strings.Join(parts, separator)
+	statusContent := renderHints(m.statusHints())
 
 	// Add filtering indicator
 	filterIndicator := styles.FilterPromptStyle.Render("FILTERING")
@@ -332,51 +275,7 @@ func (m Model) renderFilterStatusBar() string {
 
 // renderFullscreenStatusBar renders the status bar during fullscreen mode
 func (m Model) renderFullscreenStatusBar() string {
-	var hints []struct {
-		key  string
-		desc string
-	}
-
-	if m.preview.IsSearchMode() {
-		hints = []struct {
-			key  string
-			desc string
-		}{
-			{"⏎", "search"},
-			{"Esc", "cancel"},
-		}
-	} else if m.preview.HasActiveSearch() {
-		hints = []struct {
-			key  string
-			desc string
-		}{
-			{"n/N", "next/prev match"},
-			{"Esc", "clear search"},
-			{"/", "new search"},
-			{"f", "exit fullscreen"},
-		}
-	} else {
-		hints = []struct {
-			key  string
-			desc string
-		}{
-			{"↑↓", "scroll"},
-			{"g/G", "top/bottom"},
-			{"/", "search"},
-			{"f/Esc", "exit fullscreen"},
-			{"?", "help"},
-			{"q", "quit"},
-		}
-	}
-
-	var parts []string
-	for _, h := range hints {
-		part := styles.HelpKeyStyle.Render(h.key) + " " + styles.HelpDescStyle.Render(h.desc)
-		parts = append(parts, part)
-	}
-
-	separator := styles.HelpSeparatorStyle.Render("  │  ")
-	statusContent := strings.Join(parts, separator)
+	statusContent := renderHints(m.statusHints())
 
 	// Build right-side status info
 	var rightInfo string
@@ -411,7 +310,7 @@ func (m Model) renderFullscreenStatusBar() string {
 		fileName := styles.StatusValueStyle.Render(m.preview.FileName())
 		scrollIndicator := styles.HelpDescStyle.Render("[" + itoa(scrollPct) + "%]")
 		fsIndicator := styles.StatusWatchingStyle.Render("[fullscreen]")
-		rightInfo = fileName + " " + fsIndicator + " " + scrollIndicator
+		rightInfo = fileName + m.modeIndicator() + m.taskIndicator() + m.lintIndicator() + " " + fsIndicator + " " + scrollIndicator
 	}
 
 	if rightInfo != "" {
@@ -428,6 +327,54 @@ func (m Model) renderFullscreenStatusBar() string {
 		Render(statusContent)
 }
 
+// taskIndicator renders the task progress count (e.g. " 7/12 tasks") for the current document
+func (m Model) taskIndicator() string {
+	done, total := m.preview.TaskProgress()
+	if total == 0 {
+		return ""
+	}
+
+	progress := itoa(done) + "/" + itoa(total) + " tasks"
+	if done == total {
+		return " " + styles.TaskDoneStyle.Render(progress)
+	}
+	return " " + styles.TaskProgressStyle.Render(progress)
+}
+
+// modeIndicator shows how the preview presents the file in the status bar:
+// the sort column of a table, the language of source files, or the raw
+// source and split views
+func (m Model) modeIndicator() string {
+	if name, desc, ok := m.preview.SortColumn(); ok {
+		arrow := " ▲"
+		if desc {
+			arrow = " ▼"
+		}
+		return styles.HelpDescStyle.Render(" [by " + name + arrow + "]")
+	}
+
+	switch {
+	case m.preview.Format().Code:
+		return styles.HelpDescStyle.Render(" [" + m.preview.Format().Name + "]")
+	case m.preview.IsRawMode():
+		return styles.HelpDescStyle.Render(" [raw]")
+	case m.preview.IsSourceFocused():
+		return styles.HelpDescStyle.Render(" [split: source]")
+	case m.preview.IsSplitMode():
+		return styles.HelpDescStyle.Render(" [split]")
+	}
+	return ""
+}
+
+// lintIndicator returns the lint finding count for the status bar (empty if none)
+func (m Model) lintIndicator() string {
+	count := len(m.preview.Diagnostics())
+	if count == 0 {
+		return ""
+	}
+	return " " + styles.LintCountStyle.Render("⚠ "+itoa(count))
+}
+
 // itoa converts int to string without importing strconv
 func itoa(i int) string {
 	if i == 0 {
@@ -472,3 +419,33 @@ func (m Model) overlayHelp(baseView string) string {
 
 	return strings.Join(result, "\n")
 }
+
+// overlayCenter draws popup centered over base, keeping the base visible around it
+func overlayCenter(base, popup string, width, height int) string {
+	baseLines := strings.Split(base, "\n")
+	popupLines := strings.Split(popup, "\n")
+
+	top := (height - len(popupLines)) / 2
+	left := (width - lipgloss.Width(popup)) / 2
+	if top < 0 {
+		top = 0
+	}
+	if left < 0 {
+		left = 0
+	}
+
+	for i, line := range popupLines {
+		row := top + i
+		if row >= len(baseLines) {
+			break
+		}
+		baseLine := baseLines[row]
+		baseWidth := ansi.StringWidth(baseLine)
+		if baseWidth < left {
+			baseLine += strings.Repeat(" ", left-baseWidth)
+		}
+		baseLines[row] = ansi.Cut(baseLine, 0, left) + line +
+			ansi.Cut(baseLine, left+ansi.StringWidth(line), baseWidth)
+	}
+	return strings.Join(baseLines, "\n")
+}
@@ -0,0 +1,436 @@
+package filetree
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+)
+
+// Scanner coordinates background scanning: it shares a memoized index of
+// which directories contain markdown, bounds filesystem work with a worker
+// pool, and cancels in-flight scans when the root or options change
+type Scanner struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// generation increases on every Reset; results from older generations are dropped
+	generation int
+
+	// index maps directory paths to whether their subtree contains markdown
+	index map[string]bool
+
+	// workers limits concurrent directory checks
+	workers chan struct{}
+}
+
+// NewScanner creates a scanner with a worker pool sized to the machine
+func NewScanner() *Scanner {
+	workers := runtime.NumCPU() * 2
+	if workers < 4 {
+		workers = 4
+	}
+
+	s := &Scanner{workers: make(chan struct{}, workers)}
+	s.Reset()
+	return s
+}
+
+// Reset cancels in-flight scans, forgets the markdown index and returns the new generation
+func (s *Scanner) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.index = make(map[string]bool)
+	s.generation++
+	return s.generation
+}
+
+// Generation returns the current scan generation
+func (s *Scanner) Generation() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// current returns the context and generation of the current scan
+func (s *Scanner) current() (context.Context, int) {
+	if s == nil {
+		return context.Background(), 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx, s.generation
+}
+
+// lookup returns the memoized markdown check for a directory
+func (s *Scanner) lookup(dir string) (has, known bool) {
+	if s == nil {
+		return false, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	has, known = s.index[dir]
+	return has, known
+}
+
+// store memoizes a markdown check for a directory
+func (s *Scanner) store(dir string, has bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index[dir] = has
+}
+
+// forget drops the memoized checks for path, everything inside it and its
+// ancestors, whose answers change when path appears or disappears
+func (s *Scanner) forget(path string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for dir := range s.index {
+		if fsutil.IsWithin(dir, path) || fsutil.IsWithin(path, dir) {
+			delete(s.index, dir)
+		}
+	}
+}
+
+// acquire takes a worker slot, returning false if ctx is cancelled first
+func (s *Scanner) acquire(ctx context.Context) bool {
+	if s == nil {
+		return ctx.Err() == nil
+	}
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release returns a worker slot
+func (s *Scanner) release() {
+	if s != nil {
+		<-s.workers
+	}
+}
+
+// levelLoadedMsg is sent when a directory level has been listed in the background
+type levelLoadedMsg struct {
+	gen int
+
+	// parent is the directory that was listed ("" for the root)
+	parent string
+
+	// key records the options the level was listed with
+	key listingKey
+
+	items []*Item
+	err   error
+}
+
+// dirCheckedMsg is sent when a pending directory has been checked for markdown
+type dirCheckedMsg struct {
+	gen         int
+	path        string
+	hasMarkdown bool
+}
+
+// loadLevel lists a directory in the background. Subdirectories come back
+// Pending and are checked separately so the level can be shown straight away.
+func loadLevel(gen int, parent, dirPath string, depth int, opts ScanOptions) tea.Cmd {
+	return func() tea.Msg {
+		items, err := listLevel(dirPath, depth, opts)
+		return levelLoadedMsg{gen: gen, parent: parent, key: opts.key(), items: items, err: err}
+	}
+}
+
+// checkDir checks whether a pending directory contains markdown, on the worker pool
+func checkDir(ctx context.Context, gen int, path string, opts ScanOptions) tea.Cmd {
+	return func() tea.Msg {
+		if !opts.Scanner.acquire(ctx) {
+			return nil
+		}
+		defer opts.Scanner.release()
+
+		has, err := containsMarkdown(ctx, path, opts)
+		if err != nil && ctx.Err() != nil {
+			return nil
+		}
+		return dirCheckedMsg{gen: gen, path: path, hasMarkdown: has}
+	}
+}
+
+// checkPending starts markdown checks for the pending directories among items
+func (m Model) checkPending(items []*Item) []tea.Cmd {
+	ctx, gen := m.scanOptions.Scanner.current()
+
+	var cmds []tea.Cmd
+	for _, item := range items {
+		if item.Pending {
+			cmds = append(cmds, checkDir(ctx, gen, item.Path, m.scanOptions))
+		}
+	}
+	return cmds
+}
+
+// handleLevelLoaded attaches a background-listed level to the tree, keeping
+// the loaded children and expansion of directories it lists again
+func (m Model) handleLevelLoaded(msg levelLoadedMsg) (Model, tea.Cmd) {
+	if msg.gen != m.scanOptions.Scanner.Generation() {
+		return m, nil
+	}
+
+	// The options changed while the level was listed
+	if msg.key != m.scanOptions.key() {
+		dir := msg.parent
+		if dir == "" {
+			dir = m.RootPath
+		}
+		return m, m.relistDirs([]string{dir})
+	}
+
+	sortItems(msg.items, m.scanOptions.Sort, m.scanOptions.SortReverse)
+	if msg.parent == "" {
+		m.scanning = false
+		if msg.err != nil {
+			return m, nil
+		}
+		m.items = mergeLevel(m.items, msg.items)
+	} else {
+		parent := m.findItem(msg.parent)
+		if parent == nil {
+			return m, nil
+		}
+		if msg.err != nil {
+			parent.Loading = false
+			m.rebuildList()
+			return m, nil
+		}
+		parent.SetChildren(mergeLevel(parent.Children, msg.items))
+	}
+
+	m.applyTitles()
+	m.rebuildList()
+
+	cmds := m.checkPending(msg.items)
+	cmds = append(cmds, m.titlePass(), m.metadataPass(), m.revealPending())
+	return m, tea.Batch(cmds...)
+}
+
+// mergeLevel carries the children and expansion of the directories in old
+// over to the same directories in a freshly listed level
+func mergeLevel(old, fresh []*Item) []*Item {
+	previous := make(map[string]*Item, len(old))
+	for _, item := range old {
+		if item.IsDir {
+			previous[item.Path] = item
+		}
+	}
+
+	for _, item := range fresh {
+		prev := previous[item.Path]
+		if prev == nil || !item.IsDir {
+			continue
+		}
+		if prev.Loaded {
+			item.SetChildren(prev.Children)
+		}
+		item.Loading = prev.Loading
+		item.Expanded = prev.Expanded
+	}
+	return fresh
+}
+
+// relist lists the directories around changed paths again in the background:
+// their parents and every ancestor up to the root, as a change can empty or
+// fill a directory
+func (m *Model) relist(paths []string) tea.Cmd {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, path := range paths {
+		if path == "" || path == m.RootPath || !fsutil.IsWithin(path, m.RootPath) {
+			continue
+		}
+		m.scanOptions.Scanner.forget(path)
+		for dir := filepath.Dir(path); fsutil.IsWithin(dir, m.RootPath) && !seen[dir]; dir = filepath.Dir(dir) {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return m.relistDirs(dirs)
+}
+
+// relistAll lists the root and every loaded directory again in the
+// background, e.g. after the listing options changed
+func (m *Model) relistAll() tea.Cmd {
+	// Pick up edits to ignore files
+	if m.scanOptions.Ignore != nil {
+		m.scanOptions.Ignore.Reset()
+	}
+
+	dirs := []string{m.RootPath}
+	walkItems(m.items, func(item *Item) {
+		if item.IsDir && item.Loaded {
+			dirs = append(dirs, item.Path)
+		}
+	})
+	return m.relistDirs(dirs)
+}
+
+// relistDirs lists directories again in the background. Only the root and
+// directories that are loaded or loading are listed; the rest load on expand.
+func (m *Model) relistDirs(dirs []string) tea.Cmd {
+	_, gen := m.scanOptions.Scanner.current()
+
+	var cmds []tea.Cmd
+	for _, dir := range dirs {
+		if dir == m.RootPath {
+			m.scanning = true
+			cmds = append(cmds, loadLevel(gen, "", dir, 0, m.scanOptions))
+			continue
+		}
+
+		item := m.findItem(dir)
+		if item == nil || !(item.Loaded || item.Loading) {
+			continue
+		}
+		item.Loading = true
+		cmds = append(cmds, loadLevel(gen, dir, dir, item.Depth+1, m.scanOptions))
+	}
+
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(append(cmds, m.spinner.Tick)...)
+}
+
+// revealPending selects the path left by a file operation once the tree lists
+// it, loading and expanding the directories leading to it on the way. It gives
+// up when a fully listed directory doesn't contain the next step.
+func (m *Model) revealPending() tea.Cmd {
+	path := m.pendingReveal
+	if path == "" {
+		return nil
+	}
+
+	if item := m.findItem(path); item != nil {
+		for dir := item.Parent; dir != nil; dir = dir.Parent {
+			dir.Expanded = true
+		}
+		m.pendingReveal = ""
+		m.rebuildList()
+		m.selectPath(path)
+		return nil
+	}
+
+	rel, err := filepath.Rel(m.RootPath, path)
+	if err != nil || m.scanning {
+		return nil
+	}
+
+	// Step down from the root towards the path
+	level := m.items
+	dir := m.RootPath
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, name)
+
+		var next *Item
+		for _, item := range level {
+			if item.Path == dir {
+				next = item
+			}
+		}
+		switch {
+		case next == nil || !next.IsDir:
+			m.pendingReveal = ""
+			return nil
+		case next.Loading:
+			return nil
+		case !next.Loaded:
+			next.Loading = true
+			_, gen := m.scanOptions.Scanner.current()
+			return tea.Batch(loadLevel(gen, dir, dir, next.Depth+1, m.scanOptions), m.spinner.Tick)
+		}
+		level = next.Children
+	}
+
+	m.pendingReveal = ""
+	return nil
+}
+
+// handleDirChecked shows or drops a pending directory once its check completes
+func (m Model) handleDirChecked(msg dirCheckedMsg) (Model, tea.Cmd) {
+	if msg.gen != m.scanOptions.Scanner.Generation() {
+		return m, nil
+	}
+
+	item := m.findItem(msg.path)
+	if item == nil || !item.Pending {
+		return m, nil
+	}
+
+	item.Pending = false
+	if !msg.hasMarkdown {
+		m.removeItem(item)
+	}
+	m.rebuildList()
+	return m, nil
+}
+
+// findItem returns the loaded item with the given path
+func (m Model) findItem(path string) *Item {
+	var found *Item
+	walkItems(m.items, func(item *Item) {
+		if item.Path == path {
+			found = item
+		}
+	})
+	return found
+}
+
+// removeItem detaches an item from its parent (or the root level)
+func (m *Model) removeItem(item *Item) {
+	siblings := &m.items
+	if item.Parent != nil {
+		siblings = &item.Parent.Children
+	}
+
+	for i, sibling := range *siblings {
+		if sibling == item {
+			*siblings = append((*siblings)[:i:i], (*siblings)[i+1:]...)
+			return
+		}
+	}
+}
+
+// busy reports whether any directory is still loading or being checked
+func (m Model) busy() bool {
+	if m.scanning {
+		return true
+	}
+	busy := false
+	walkItems(m.items, func(item *Item) {
+		if item.Loading || item.Pending {
+			busy = true
+		}
+	})
+	return busy
+}
@@ -0,0 +1,208 @@
+package filetree
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// settle runs cmd and every command its messages lead to, feeding the
+// messages back into the model (spinner ticks are dropped)
+func settle(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for n := 0; len(queue) > 0; n++ {
+		if n > 1000 {
+			t.Fatal("commands did not settle")
+		}
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case nil, spinner.TickMsg:
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		default:
+			var next tea.Cmd
+			m, next = m.Update(msg)
+			queue = append(queue, next)
+		}
+	}
+	return m
+}
+
+// openTree scans root and reveals path, returning the settled model
+func openTree(t *testing.T, root, path string) Model {
+	t.Helper()
+	m := New(root, 80, 40)
+	m = settle(t, m, m.Init())
+	return settle(t, m, m.Reveal(filepath.Join(root, filepath.FromSlash(path))))
+}
+
+// visible returns the listed paths relative to root
+func visible(m Model) []string {
+	var paths []string
+	for _, listItem := range m.list.Items() {
+		rel, _ := filepath.Rel(m.RootPath, listItem.(*Item).Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+// selected returns the selected path relative to root
+func selected(m Model) string {
+	item := m.SelectedItem()
+	if item == nil {
+		return ""
+	}
+	rel, _ := filepath.Rel(m.RootPath, item.Path)
+	return filepath.ToSlash(rel)
+}
+
+func TestFileOpsRelistChangedLevels(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		reveal       string
+		op           func(root string) tea.Cmd
+		wantVisible  []string
+		wantSelected string
+	}{
+		{
+			name:   "create in an expanded directory",
+			files:  map[string]string{"docs/sub/b.md": "", "top.md": ""},
+			reveal: "docs/sub/b.md",
+			op: func(root string) tea.Cmd {
+				return createFile(filepath.Join(root, "docs/sub/a.md"), "")
+			},
+			wantVisible:  []string{"docs", "docs/sub", "docs/sub/a.md", "docs/sub/b.md", "top.md"},
+			wantSelected: "docs/sub/a.md",
+		},
+		{
+			name:   "create in a new directory",
+			files:  map[string]string{"docs/b.md": ""},
+			reveal: "docs/b.md",
+			op: func(root string) tea.Cmd {
+				return createFile(filepath.Join(root, "docs/new/deep/c.md"), "")
+			},
+			wantVisible:  []string{"docs", "docs/new", "docs/new/deep", "docs/new/deep/c.md", "docs/b.md"},
+			wantSelected: "docs/new/deep/c.md",
+		},
+		{
+			name:   "move the last document out of a directory",
+			files:  map[string]string{"only/x.md": "", "keep.md": ""},
+			reveal: "only/x.md",
+			op: func(root string) tea.Cmd {
+				return renamePath(root, DefaultScanOptions(), filepath.Join(root, "only/x.md"), filepath.Join(root, "x.md"))
+			},
+			wantVisible:  []string{"keep.md", "x.md"},
+			wantSelected: "x.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			m := openTree(t, root, tt.reveal)
+			m = settle(t, m, func() tea.Msg { return runOp(t, tt.op(root)) })
+
+			if got := visible(m); !reflect.DeepEqual(got, tt.wantVisible) {
+				t.Errorf("visible = %q, want %q", got, tt.wantVisible)
+			}
+			if got := selected(m); got != tt.wantSelected {
+				t.Errorf("selected = %q, want %q", got, tt.wantSelected)
+			}
+		})
+	}
+}
+
+func TestRelistKeepsLoadedDirectories(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docs/a.md":     "",
+		"docs/main.go":  "",
+		"docs/sub/b.md": "",
+	})
+
+	m := openTree(t, root, "docs/sub/b.md")
+	if want := []string{"docs", "docs/sub", "docs/sub/b.md", "docs/a.md"}; !reflect.DeepEqual(visible(m), want) {
+		t.Fatalf("visible = %q, want %q", visible(m), want)
+	}
+
+	m = settle(t, m, m.SetMarkdownOnly(false))
+	want := []string{"docs", "docs/sub", "docs/sub/b.md", "docs/a.md", "docs/main.go"}
+	if got := visible(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible = %q, want %q", got, want)
+	}
+}
+
+func TestSortChangeReordersLoadedItems(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docs/2.md":  "+++\nweight = 1\n+++\n",
+		"docs/10.md": "+++\nweight = 2\n+++\n",
+		"docs/1.md":  "",
+	})
+
+	m := openTree(t, root, "docs/1.md")
+	docs := m.findItem(filepath.Join(root, "docs"))
+
+	keys := []struct {
+		key  string
+		want []string
+	}{
+		// name → natural
+		{"s", []string{"docs", "docs/1.md", "docs/2.md", "docs/10.md"}},
+		{"S", []string{"docs", "docs/10.md", "docs/2.md", "docs/1.md"}},
+		// natural → modified → size → title → date → weight
+		{"s", nil}, {"s", nil}, {"s", nil}, {"s", nil}, {"s", nil},
+		{"S", []string{"docs", "docs/2.md", "docs/10.md", "docs/1.md"}},
+	}
+	for _, k := range keys {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k.key)})
+		m = settle(t, m, cmd)
+		if k.want != nil && !reflect.DeepEqual(visible(m), k.want) {
+			t.Fatalf("after %q (%s): visible = %q, want %q", k.key, m.sortLabel(), visible(m), k.want)
+		}
+	}
+
+	// The loaded directory was sorted in place, not listed again
+	if m.findItem(filepath.Join(root, "docs")) != docs {
+		t.Error("sorting replaced the loaded directory")
+	}
+}
+
+func TestDocumentFilterLoadsDirectories(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/deep/tagged.md": "",
+		"a/other.md":       "",
+		"b/skip.md":        "",
+		"top.md":           "",
+	})
+
+	m := New(root, 80, 40)
+	m = settle(t, m, m.Init())
+	m = settle(t, m, m.SetDocumentFilter("#infra", []string{filepath.Join(root, "a/deep/tagged.md")}))
+
+	want := []string{"a", "a/deep", "a/deep/tagged.md"}
+	if got := visible(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible = %q, want %q", got, want)
+	}
+
+	m = settle(t, m, m.ClearDocumentFilter())
+	want = []string{"a", "a/deep", "a/deep/tagged.md", "a/other.md", "b", "top.md"}
+	if got := visible(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("after clearing: visible = %q, want %q", got, want)
+	}
+}
@@ -3,17 +3,54 @@ package filetree
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Ayushlm10/skim/internal/styles"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
+// Columns selects which metadata columns are shown beside item names
+type Columns int
+
+const (
+	// ColumnModified shows the relative modification time
+	ColumnModified Columns = 1 << iota
+
+	// ColumnSize shows the file size
+	ColumnSize
+
+	// ColumnWords shows the word count
+	ColumnWords
+)
+
+// columnPresets are cycled through with the columns key, from none to all
+var columnPresets = []Columns{
+	0,
+	ColumnModified,
+	ColumnModified | ColumnSize,
+	ColumnModified | ColumnSize | ColumnWords,
+}
+
+// minNameWidth is the narrowest the name may get before columns are dropped
+const minNameWidth = 10
+
 // ItemDelegate handles rendering of tree items in the list
 type ItemDelegate struct {
 	// ShowIndicator shows the selection indicator
 	ShowIndicator bool
+
+	// Columns are the metadata columns to right-align after names
+	Columns Columns
+
+	// ShowTitles shows document titles with the file name dimmed beside them
+	ShowTitles bool
+
+	// SpinnerFrame replaces the expand indicator of directories still loading
+	SpinnerFrame string
 }
 
 // NewItemDelegate creates a new delegate with default settings
@@ -53,6 +90,16 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	isSelected := index == m.Index()
 
+	// Fit metadata columns into the panel, dropping the least important first
+	columns := d.renderColumns(item, m.Width()-lipgloss.Width(indent)-4-minNameWidth)
+	name := item.Name
+	if item.IsDir {
+		name = item.DisplayName()
+	}
+	if columns != "" {
+		name = truncateName(name, maxNameWidth(m.Width(), indent, columns))
+	}
+
 	// Render based on item type
 	if item.IsDir {
 		// Directory with expand/collapse indicator
@@ -60,33 +107,177 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		if item.Expanded {
 			indicator = styles.TreeExpanded
 		}
+		if (item.Loading || item.Pending) && d.SpinnerFrame != "" {
+			indicator = d.SpinnerFrame
+		}
 		b.WriteString(styles.TreeIndicatorStyle.Render(indicator + " "))
 
 		if isSelected {
-			b.WriteString(styles.SelectedDirectoryStyle.Render(item.DisplayName()))
+			b.WriteString(styles.SelectedDirectoryStyle.Render(name))
 			if d.ShowIndicator {
 				b.WriteString(" " + styles.TreeIndicatorStyle.Render(styles.SelectedMark))
 			}
 		} else {
-			b.WriteString(styles.DirectoryStyle.Render(item.DisplayName()))
+			b.WriteString(styles.DirectoryStyle.Render(name))
+		}
+		if item.Cycle {
+			b.WriteString(" " + styles.TreeSecondaryStyle.Render(styles.TreeCycleMark))
 		}
 	} else {
-		// File with proper alignment
-		b.WriteString("  ") // Align with directory indicators
+		// File with its format icon, aligned with directory indicators
+		b.WriteString(styles.TreeIndicatorStyle.Render(item.Icon() + " "))
 
-		if isSelected {
-			b.WriteString(styles.SelectedItemStyle.Render(item.Name))
-			if d.ShowIndicator {
-				b.WriteString(" " + styles.TreeIndicatorStyle.Render(styles.SelectedMark))
+		// Document title first, with the file name dimmed beside it
+		fileName := ""
+		if d.ShowTitles && item.DocTitle != "" {
+			fileName = name
+			name = truncateName(item.DocTitle, maxNameWidth(m.Width(), indent, columns))
+			if remaining := maxNameWidth(m.Width(), indent, columns) - lipgloss.Width(name) - 1; remaining >= 4 {
+				fileName = truncateName(fileName, remaining)
+			} else {
+				fileName = ""
 			}
+		}
+
+		if isSelected {
+			b.WriteString(styles.SelectedItemStyle.Render(name))
 		} else {
-			b.WriteString(styles.FileStyle.Render(item.Name))
+			b.WriteString(styles.FileStyle.Render(name))
+		}
+		if fileName != "" {
+			b.WriteString(" " + styles.TreeSecondaryStyle.Render(fileName))
+		}
+		if isSelected && d.ShowIndicator {
+			b.WriteString(" " + styles.TreeIndicatorStyle.Render(styles.SelectedMark))
+		}
+	}
+
+	// Right-align the columns against the panel edge
+	if columns != "" {
+		gap := m.Width() - lipgloss.Width(b.String()) - lipgloss.Width(columns)
+		if gap >= 1 {
+			b.WriteString(strings.Repeat(" ", gap) + columns)
 		}
 	}
 
 	fmt.Fprint(w, b.String())
 }
 
+// renderColumns renders the enabled metadata columns that fit in the available width
+func (d ItemDelegate) renderColumns(item *Item, available int) string {
+	if d.Columns == 0 {
+		return ""
+	}
+
+	// Most important first; columns are dropped from the end when space runs out
+	var cells []string
+	if d.Columns&ColumnModified != 0 {
+		cells = append(cells, fmt.Sprintf("%4s", relativeTime(item.ModTime)))
+	}
+	if d.Columns&ColumnSize != 0 {
+		size := ""
+		if !item.IsDir {
+			size = humanSize(item.Size)
+		}
+		cells = append(cells, fmt.Sprintf("%5s", size))
+	}
+	if d.Columns&ColumnWords != 0 {
+		words := ""
+		if !item.IsDir && item.Words >= 0 {
+			words = humanWords(item.Words)
+		}
+		cells = append(cells, fmt.Sprintf("%6s", words))
+	}
+
+	for len(cells) > 0 {
+		joined := strings.Join(cells, " ")
+		if len(joined) <= available {
+			return styles.TreeColumnStyle.Render(joined)
+		}
+		cells = cells[:len(cells)-1]
+	}
+	return ""
+}
+
+// maxNameWidth returns the room left for the name after indentation,
+// indicators and metadata columns
+func maxNameWidth(width int, indent, columns string) int {
+	w := width - lipgloss.Width(indent) - 4
+	if columns != "" {
+		w -= lipgloss.Width(columns) + 1
+	}
+	return w
+}
+
+// truncateName shortens a name to fit width, marking the cut with an ellipsis
+func truncateName(name string, width int) string {
+	if width < 1 || lipgloss.Width(name) <= width {
+		return name
+	}
+	runes := []rune(name)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// relativeTime formats how long ago t was in a compact form (5m, 3h, 2d, 4w, 3mo, 2y)
+func relativeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	case d < 24*time.Hour:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	case d < 7*24*time.Hour:
+		return strconv.Itoa(int(d.Hours()/24)) + "d"
+	case d < 30*24*time.Hour:
+		return strconv.Itoa(int(d.Hours()/(24*7))) + "w"
+	case d < 365*24*time.Hour:
+		return strconv.Itoa(int(d.Hours()/(24*30))) + "mo"
+	default:
+		return strconv.Itoa(int(d.Hours()/(24*365))) + "y"
+	}
+}
+
+// humanSize formats a byte count (512B, 1.2K, 3.4M)
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+
+	value := float64(n)
+	for _, suffix := range []string{"K", "M", "G", "T"} {
+		value /= unit
+		if value < unit {
+			if value < 10 {
+				return strconv.FormatFloat(value, 'f', 1, 64) + suffix
+			}
+			return strconv.FormatFloat(value, 'f', 0, 64) + suffix
+		}
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64) + "P"
+}
+
+// humanWords formats a word count (850w, 1.2kw, 12kw)
+func humanWords(n int) string {
+	switch {
+	case n < 1000:
+		return strconv.Itoa(n) + "w"
+	case n < 10000:
+		return strconv.FormatFloat(float64(n)/1000, 'f', 1, 64) + "kw"
+	default:
+		return strconv.Itoa(n/1000) + "kw"
+	}
+}
+
 // ShortHelp returns short help text for the list
 func (d ItemDelegate) ShortHelp() []string {
 	return []string{"j/k: navigate", "enter: open/toggle", "/: filter"}
@@ -0,0 +1,111 @@
+package filetree
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+	"github.com/Ayushlm10/skim/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DocumentFilterChangedMsg is sent when the tree starts or stops showing
+// only a chosen set of documents
+type DocumentFilterChangedMsg struct {
+	Label  string
+	Count  int
+	Active bool
+}
+
+// SetDocumentFilter limits the tree to paths (and their directories),
+// expanding every directory leading to them. label describes the filter,
+// e.g. "#infra".
+func (m *Model) SetDocumentFilter(label string, paths []string) tea.Cmd {
+	m.docLabel = label
+	m.docFilter = make(map[string]bool)
+	m.docDirs = make(map[string]bool)
+
+	for _, path := range paths {
+		if !fsutil.IsWithin(path, m.RootPath) {
+			continue
+		}
+		m.docFilter[path] = true
+		for dir := filepath.Dir(path); fsutil.IsWithin(dir, m.RootPath) && dir != m.RootPath; dir = filepath.Dir(dir) {
+			m.docDirs[dir] = true
+		}
+	}
+
+	// Load unlisted directories shallowest first so each attaches to its parent
+	var roots []dirRef
+	for dir := range m.docDirs {
+		if item := m.findItem(dir); item == nil || !item.Loaded {
+			rel, _ := filepath.Rel(m.RootPath, dir)
+			roots = append(roots, dirRef{path: dir, depth: strings.Count(filepath.ToSlash(rel), "/")})
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].depth < roots[j].depth || (roots[i].depth == roots[j].depth && roots[i].path < roots[j].path)
+	})
+
+	changed := DocumentFilterChangedMsg{Label: label, Count: len(m.docFilter), Active: true}
+	msg := dirsLoadedMsg{expand: m.docDirs}
+	ctx, gen := m.scanOptions.Scanner.current()
+	opts := m.scanOptions
+	load := func() tea.Msg {
+		msg.gen = gen
+		msg.key = opts.key()
+		msg.levels = make(map[string][]*Item)
+		for _, root := range roots {
+			items, err := scanLevel(ctx, root.path, root.depth+1, opts)
+			if err != nil {
+				continue
+			}
+			msg.levels[root.path] = items
+		}
+		return msg
+	}
+
+	m.rebuildList()
+	return tea.Batch(load, func() tea.Msg { return changed })
+}
+
+// ClearDocumentFilter shows the whole tree again
+func (m *Model) ClearDocumentFilter() tea.Cmd {
+	if m.docFilter == nil {
+		return nil
+	}
+	m.docFilter = nil
+	m.docDirs = nil
+	m.docLabel = ""
+	m.rebuildList()
+	return func() tea.Msg { return DocumentFilterChangedMsg{} }
+}
+
+// DocumentFilter returns the active document filter's label and size
+func (m Model) DocumentFilter() (label string, count int, active bool) {
+	return m.docLabel, len(m.docFilter), m.docFilter != nil
+}
+
+// passesDocumentFilter reports whether an item is shown under the document filter
+func (m Model) passesDocumentFilter(item *Item) bool {
+	if m.docFilter == nil {
+		return true
+	}
+	if item.IsDir {
+		return m.docDirs[item.Path]
+	}
+	return m.docFilter[item.Path]
+}
+
+// viewWithDocumentFilter renders the list with the filter's label in its
+// (otherwise blank) header line
+func (m Model) viewWithDocumentFilter() string {
+	lines := strings.Split(m.list.View(), "\n")
+	label := styles.FilterPromptStyle.Render(m.docLabel) +
+		styles.TreeSecondaryStyle.Render(" · "+strconv.Itoa(len(m.docFilter))+" docs · Esc clears")
+	lines[0] = ansi.Truncate(label, m.width, "…")
+	return strings.Join(lines, "\n")
+}
@@ -1,8 +1,12 @@
 package filetree
 
 import (
+	"strings"
+
 	"github.com/Ayushlm10/skim/internal/styles"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -31,6 +35,11 @@ type IgnoredDirsToggledMsg struct {
 	ShowIgnored bool
 }
 
+// ViewChangedMsg is sent when tree display options (sorting, columns, ...) change
+type ViewChangedMsg struct {
+	Message string
+}
+
 // Model is the file tree component model
 type Model struct {
 	// Root path being scanned
@@ -51,6 +60,41 @@ type Model struct {
 
 	// Focus state
 	focused bool
+
+	// Name prompt for create/rename/duplicate
+	prompt        promptKind
+	promptInput   textinput.Model
+	promptTarget  *Item
+	templates     []Template
+	templateIndex int
+
+	// Yes/no confirmation for delete and link updates
+	confirm        confirmKind
+	confirmTarget  *Item
+	pendingUpdates []linkUpdate
+
+	// Undo stack of completed file operations
+	undo []undoEntry
+
+	// pendingReveal is selected once the relisted tree shows it
+	pendingReveal string
+
+	// Item rendering (metadata columns)
+	delegate     ItemDelegate
+	columnPreset int
+
+	// Document titles shown instead of file names
+	showTitles bool
+	titles     map[string]string
+
+	// Background scanning state
+	scanning bool
+	spinner  spinner.Model
+
+	// Document filter (e.g. files with a tag) and the directories leading to them
+	docFilter map[string]bool
+	docDirs   map[string]bool
+	docLabel  string
 }
 
 // New creates a new file tree component
@@ -78,10 +122,15 @@ func New(rootPath string, width, height int) Model {
 		RootPath:    rootPath,
 		items:       nil,
 		list:        l,
-		scanOptions: DefaultScanOptions(),
+		scanOptions: ScanOptionsFor(rootPath),
 		width:       width,
 		height:      height,
 		focused:     true,
+		promptInput: newPromptInput(),
+		delegate:    delegate,
+		titles:      make(map[string]string),
+		scanning:    true,
+		spinner:     spinner.New(spinner.WithSpinner(spinner.MiniDot)),
 	}
 }
 
@@ -90,25 +139,11 @@ func (m Model) Init() tea.Cmd {
 	return m.scanRoot()
 }
 
-// scanRoot scans the root directory
+// scanRoot lists the root directory in the background; subdirectories
+// appear as they are found to contain markdown
 func (m Model) scanRoot() tea.Cmd {
-	return func() tea.Msg {
-		items, err := ScanDirectory(m.RootPath, m.scanOptions)
-		if err != nil {
-			return scanErrorMsg{err}
-		}
-		return scanCompleteMsg{items}
-	}
-}
-
-// scanErrorMsg is sent when scanning fails
-type scanErrorMsg struct {
-	err error
-}
-
-// scanCompleteMsg is sent when scanning completes
-type scanCompleteMsg struct {
-	items []*Item
+	_, gen := m.scanOptions.Scanner.current()
+	return tea.Batch(loadLevel(gen, "", m.RootPath, 0, m.scanOptions), m.spinner.Tick)
 }
 
 // Update handles messages
@@ -121,20 +156,56 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.height = msg.Height
 		m.list.SetSize(m.width, m.height)
 
-	case scanCompleteMsg:
-		m.items = msg.items
+	case titlesLoadedMsg:
+		for path, title := range msg.titles {
+			m.titles[path] = title
+		}
+		m.applyTitles()
 		m.rebuildList()
-
-	case scanErrorMsg:
-		// Handle error - could show in status
 		return m, nil
 
+	case metadataLoadedMsg:
+		return m.handleMetadataLoaded(msg)
+
+	case levelLoadedMsg:
+		return m.handleLevelLoaded(msg)
+
+	case dirCheckedMsg:
+		return m.handleDirChecked(msg)
+
+	case dirsLoadedMsg:
+		return m.handleDirsLoaded(msg)
+
+	case spinner.TickMsg:
+		// Keep spinning only while something is loading
+		if msg.ID != m.spinner.ID() || !m.busy() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		m.delegate.SpinnerFrame = m.spinner.View()
+		m.list.SetDelegate(m.delegate)
+		return m, cmd
+
+	case opDoneMsg:
+		return m.handleOpDone(msg)
+
+	case linksUpdatedMsg:
+		return m.handleLinksUpdated(msg)
+
 	case tea.KeyMsg:
 		if m.focused {
 			return m.handleKey(msg)
 		}
 	}
 
+	// Keep the prompt cursor blinking
+	if m.prompt != promptNone {
+		var cmd tea.Cmd
+		m.promptInput, cmd = m.promptInput.Update(msg)
+		return m, cmd
+	}
+
 	// Forward to list for filtering etc.
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
@@ -147,6 +218,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 // handleKey handles keyboard input
 func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	// Prompts and confirmations capture all keys
+	if m.confirm != confirmNone {
+		return m.handleConfirmKey(msg)
+	}
+	if m.prompt != promptNone {
+		return m.handlePromptKey(msg)
+	}
+
 	// When filtering, delegate most keys to the list
 	if m.IsFiltering() {
 		switch msg.String() {
@@ -199,30 +278,259 @@ func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 		})
 
 	case "esc":
-		// Clear filter if there is one
+		// Clear filter if there is one, then the document filter
 		if m.list.FilterValue() != "" {
 			m.list.ResetFilter()
 			return m, func() tea.Msg {
 				return FilterChangedMsg{Active: false, Value: ""}
 			}
 		}
-		return m, nil
+		return m, m.ClearDocumentFilter()
+
+	case "l", "right":
+		// Expand directory (or step into it) / open file
+		return m.expandOrOpen()
+
+	case "h", "left":
+		// Collapse directory / jump to parent
+		return m.collapseOrParent()
+
+	case "E":
+		// Expand the selected directory recursively
+		return m.expandRecursive()
+
+	case "W":
+		// Collapse all directories
+		return m.collapseAll()
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		// Expand to the given number of levels
+		return m.expandToDepth(int(msg.String()[0] - '0'))
 
 	case "i":
 		// Toggle ignored directories visibility
 		return m.toggleIgnoredDirs()
+
+	case "F":
+		// Toggle between documents only and all files
+		return m, m.SetMarkdownOnly(!m.scanOptions.MarkdownOnly)
+
+	case "a":
+		// New markdown file in the selected directory
+		return m.startPrompt(promptNewFile, nil, m.targetDir())
+
+	case "A":
+		// New directory in the selected directory
+		return m.startPrompt(promptNewDir, nil, m.targetDir())
+
+	case "r":
+		// Rename or move the selected item
+		if item := m.SelectedItem(); item != nil {
+			return m.startPrompt(promptRename, item, relativeTo(m.RootPath, item.Path))
+		}
+		return m, nil
+
+	case "C":
+		// Duplicate the selected item
+		if item := m.SelectedItem(); item != nil {
+			return m.startPrompt(promptDuplicate, item, relativeTo(m.RootPath, duplicateName(item.Path)))
+		}
+		return m, nil
+
+	case "d":
+		// Delete the selected item (after confirmation)
+		if item := m.SelectedItem(); item != nil {
+			m.confirm = confirmDelete
+			m.confirmTarget = item
+		}
+		return m, nil
+
+	case "u":
+		// Undo the last file operation
+		return m.undoLast()
+
+	case "s":
+		// Cycle sort mode
+		m.scanOptions.Sort = m.scanOptions.Sort.Next()
+		return m.sortChanged()
+
+	case "S":
+		// Reverse sort order
+		m.scanOptions.SortReverse = !m.scanOptions.SortReverse
+		return m.sortChanged()
+
+	case "m":
+		// Cycle metadata columns
+		return m.cycleColumns()
+
+	case "T":
+		// Toggle document titles
+		return m.toggleTitles()
 	}
 
 	return m, nil
 }
 
-// toggleIgnoredDirs toggles the visibility of ignored directories and rescans
+// sortLabel describes the current sort order, e.g. "natural" or "modified (reversed)"
+func (m Model) sortLabel() string {
+	label := m.scanOptions.Sort.String()
+	if m.scanOptions.SortReverse {
+		label += " (reversed)"
+	}
+	return label
+}
+
+// sortChanged re-sorts the loaded tree, reading any front matter the new
+// mode needs in the background
+func (m Model) sortChanged() (Model, tea.Cmd) {
+	m.resort()
+	return m, tea.Batch(m.metadataPass(), m.viewChanged("sort: "+m.sortLabel()))
+}
+
+// viewChanged reports a display option change to the parent
+func (m Model) viewChanged(message string) tea.Cmd {
+	return func() tea.Msg {
+		return ViewChangedMsg{Message: message}
+	}
+}
+
+// cycleColumns switches to the next metadata column preset
+func (m Model) cycleColumns() (Model, tea.Cmd) {
+	m.columnPreset = (m.columnPreset + 1) % len(columnPresets)
+	m.delegate.Columns = columnPresets[m.columnPreset]
+	m.list.SetDelegate(m.delegate)
+
+	var names []string
+	if m.delegate.Columns&ColumnModified != 0 {
+		names = append(names, "modified")
+	}
+	if m.delegate.Columns&ColumnSize != 0 {
+		names = append(names, "size")
+	}
+	if m.delegate.Columns&ColumnWords != 0 {
+		names = append(names, "words")
+	}
+	message := "columns: none"
+	if len(names) > 0 {
+		message = "columns: " + strings.Join(names, ", ")
+	}
+
+	// Word counts are only read from disk when the column is shown
+	m.scanOptions.CountWords = m.delegate.Columns&ColumnWords != 0
+	return m, tea.Batch(m.viewChanged(message), m.metadataPass())
+}
+
+// handleOpDone records a finished file operation and refreshes the tree
+func (m Model) handleOpDone(msg opDoneMsg) (Model, tea.Cmd) {
+	result := msg.result
+	report := func() tea.Msg { return result }
+
+	if result.Error != nil {
+		return m, report
+	}
+
+	relist := msg.relist
+	if relist == nil {
+		relist = []string{result.Path, result.NewPath}
+	}
+	if msg.undo != nil {
+		entry := *msg.undo
+		entry.paths = relist
+		m.undo = append(m.undo, entry)
+	}
+
+	// Offer to fix links pointing at a renamed file
+	if len(msg.updates) > 0 {
+		m.confirm = confirmLinkUpdate
+		m.pendingUpdates = msg.updates
+	}
+
+	m.pendingReveal = result.NewPath
+	cmds := []tea.Cmd{report, m.relist(relist), m.revealPending()}
+
+	// Open newly created files straight away
+	if result.Op == "created" && m.scanOptions.IsDocument(result.NewPath) {
+		path := result.NewPath
+		cmds = append(cmds, func() tea.Msg { return FileSelectedMsg{Path: path} })
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// handleLinksUpdated folds link rewrites into the rename's undo entry
+func (m Model) handleLinksUpdated(msg linksUpdatedMsg) (Model, tea.Cmd) {
+	original := msg.original
+	restore := func() error {
+		for path, content := range original {
+			if err := writeKeepingMode(path, content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(original) > 0 && len(m.undo) > 0 {
+		last := m.undo[len(m.undo)-1]
+		m.undo[len(m.undo)-1] = undoEntry{
+			description: last.description + ", links reverted",
+			paths:       last.paths,
+			revert: func() error {
+				if err := restore(); err != nil {
+					return err
+				}
+				return last.revert()
+			},
+		}
+	}
+
+	result := FileOpMsg{
+		Op:      "links",
+		Message: "updated " + pluralize(msg.links, "link") + " in " + pluralize(msg.files, "file"),
+		Error:   msg.err,
+	}
+	return m, func() tea.Msg { return result }
+}
+
+// undoLast reverts the most recent file operation in the background, as
+// reverting a move across filesystems copies the whole tree
+func (m Model) undoLast() (Model, tea.Cmd) {
+	if len(m.undo) == 0 {
+		return m, func() tea.Msg {
+			return FileOpMsg{Op: "undo", Message: "nothing to undo"}
+		}
+	}
+
+	entry := m.undo[len(m.undo)-1]
+	m.undo = m.undo[:len(m.undo)-1]
+
+	return m, func() tea.Msg {
+		result := FileOpMsg{Op: "undo", Message: entry.description}
+		if err := entry.revert(); err != nil {
+			result.Error = err
+		}
+		return opDoneMsg{result: result, relist: entry.paths}
+	}
+}
+
+// selectPath moves the cursor to the item with the given path, if visible
+func (m *Model) selectPath(path string) {
+	for i, listItem := range m.list.Items() {
+		if item, ok := listItem.(*Item); ok && item.Path == path {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// toggleIgnoredDirs toggles the visibility of ignored directories and lists
+// the loaded directories again
 func (m Model) toggleIgnoredDirs() (Model, tea.Cmd) {
 	m.scanOptions.ShowIgnored = !m.scanOptions.ShowIgnored
 
-	// Re-scan the directory with new options
+	// Which directories contain markdown depends on what is ignored
+	m.scanOptions.Scanner.forget(m.RootPath)
 	return m, tea.Batch(
-		m.scanRoot(),
+		m.relistAll(),
 		func() tea.Msg {
 			return IgnoredDirsToggledMsg{ShowIgnored: m.scanOptions.ShowIgnored}
 		},
@@ -245,19 +553,22 @@ func (m Model) handleSelect() (Model, tea.Cmd) {
 		// Toggle directory expand/collapse
 		item.Toggle()
 
-		// Load children if expanding and not yet loaded
-		if item.Expanded && !item.HasChildren() {
-			_ = ScanChildren(item, m.scanOptions)
+		// Load children in the background if expanding and not yet loaded
+		var load tea.Cmd
+		if item.Expanded && !item.Loaded && !item.Loading && m.canDescend(item) {
+			item.Loading = true
+			_, gen := m.scanOptions.Scanner.current()
+			load = tea.Batch(loadLevel(gen, item.Path, item.Path, item.Depth+1, m.scanOptions), m.spinner.Tick)
 		}
 
 		m.rebuildList()
 
-		return m, func() tea.Msg {
+		return m, tea.Batch(load, func() tea.Msg {
 			return DirectoryToggledMsg{
 				Path:     item.Path,
 				Expanded: item.Expanded,
 			}
-		}
+		})
 	}
 
 	// File selected - send message to parent
@@ -266,13 +577,24 @@ func (m Model) handleSelect() (Model, tea.Cmd) {
 	}
 }
 
-// rebuildList reconstructs the flattened list from tree structure
+// canDescend reports whether a directory's children may be loaded
+func (m Model) canDescend(item *Item) bool {
+	return m.scanOptions.MaxDepth < 0 || item.Depth < m.scanOptions.MaxDepth
+}
+
+// rebuildList reconstructs the flattened list from tree structure,
+// keeping the cursor on the same item when it is still visible
 func (m *Model) rebuildList() {
+	selected := m.SelectedItem()
+
 	var flatItems []list.Item
 
 	var flatten func(items []*Item)
 	flatten = func(items []*Item) {
 		for _, item := range items {
+			if !m.passesDocumentFilter(item) {
+				continue
+			}
 			flatItems = append(flatItems, item)
 			if item.IsDir && item.Expanded && item.HasChildren() {
 				flatten(item.Children)
@@ -282,17 +604,50 @@ func (m *Model) rebuildList() {
 
 	flatten(m.items)
 	m.list.SetItems(flatItems)
+
+	if selected != nil && m.SelectedItem() != selected {
+		m.selectPath(selected.Path)
+	}
 }
 
 // View renders the component
 func (m Model) View() string {
+	if m.prompt != promptNone || m.confirm != confirmNone {
+		return m.viewWithPrompt()
+	}
+
 	if len(m.items) == 0 {
+		if m.scanning {
+			return m.renderScanningState()
+		}
 		return m.renderEmptyState()
 	}
 
+	if m.docFilter != nil && !m.IsFiltering() {
+		return m.viewWithDocumentFilter()
+	}
 	return m.list.View()
 }
 
+// viewWithPrompt renders the list with the prompt replacing its bottom lines
+func (m Model) viewWithPrompt() string {
+	promptLines := strings.Split(m.renderPromptLine(), "\n")
+
+	var lines []string
+	if len(m.items) > 0 {
+		lines = strings.Split(m.list.View(), "\n")
+	}
+	for len(lines) < m.height {
+		lines = append(lines, "")
+	}
+
+	keep := m.height - len(promptLines)
+	if keep < 0 {
+		keep = 0
+	}
+	return strings.Join(append(lines[:keep], promptLines...), "\n")
+}
+
 // renderEmptyState renders a helpful message when no files are found
 func (m Model) renderEmptyState() string {
 	title := styles.EmptyStateTitleStyle.Render("No Markdown Files")
@@ -308,6 +663,17 @@ func (m Model) renderEmptyState() string {
 		Render(content)
 }
 
+// renderScanningState renders a placeholder while the root is first listed
+func (m Model) renderScanningState() string {
+	content := styles.EmptyStateHintStyle.Render(m.spinner.View() + " Scanning…")
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(content)
+}
+
 // SetSize updates the component size
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -360,6 +726,44 @@ func (m Model) HasActiveFilter() bool {
 	return m.list.FilterValue() != ""
 }
 
+// IsPrompting returns true while a name prompt or confirmation is capturing input
+func (m Model) IsPrompting() bool {
+	return m.prompt != promptNone || m.confirm != confirmNone
+}
+
+// SortMode returns the current sort mode
+func (m Model) SortMode() SortMode {
+	return m.scanOptions.Sort
+}
+
+// ScanOptions returns the options the tree scans with, so other walks of
+// the docs tree can skip the same entries
+func (m Model) ScanOptions() ScanOptions {
+	return m.scanOptions
+}
+
+// SetMarkdownOnly switches between listing only documents and listing every
+// file (source code and other files too), and lists the loaded directories again
+func (m *Model) SetMarkdownOnly(only bool) tea.Cmd {
+	m.scanOptions.MarkdownOnly = only
+	message := "showing all files"
+	if only {
+		message = "showing documents only"
+	}
+	return tea.Batch(m.relistAll(), m.viewChanged(message))
+}
+
+// MarkdownOnly returns true if only documents are listed
+func (m Model) MarkdownOnly() bool {
+	return m.scanOptions.MarkdownOnly
+}
+
+// SetExtensions limits the tree to documents with the given extensions
+// (nil for every supported format). Call before the first scan.
+func (m *Model) SetExtensions(extensions []string) {
+	m.scanOptions.Extensions = extensions
+}
+
 // ShowIgnored returns true if ignored directories are being shown
 func (m Model) ShowIgnored() bool {
 	return m.scanOptions.ShowIgnored
@@ -3,6 +3,10 @@ package filetree
 import (
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/frontmatter"
 )
 
 // Item represents a file or directory in the tree
@@ -30,6 +34,36 @@ type Item struct {
 
 	// Visible indicates if item should be shown (for filtering)
 	Visible bool
+
+	// ModTime is the last modification time
+	ModTime time.Time
+
+	// Size is the file size in bytes (0 for directories)
+	Size int64
+
+	// Words is the word count (-1 when not counted)
+	Words int
+
+	// Matter is the parsed front matter (nil unless a sort mode needed it)
+	Matter *frontmatter.Matter
+
+	// matterRead records that Matter was read (it stays nil without front matter)
+	matterRead bool
+
+	// DocTitle is the document title shown in place of the name (when enabled)
+	DocTitle string
+
+	// Loaded indicates the directory's children have been read
+	Loaded bool
+
+	// Loading indicates the directory's children are being read in the background
+	Loading bool
+
+	// Pending indicates the directory is still being checked for markdown files
+	Pending bool
+
+	// Cycle indicates a symlinked directory that points back at one of its ancestors
+	Cycle bool
 }
 
 // NewItem creates a new tree item
@@ -43,12 +77,13 @@ func NewItem(path string, isDir bool, depth int) *Item {
 		Children: nil,
 		Parent:   nil,
 		Visible:  true,
+		Words:    -1,
 	}
 }
 
 // Toggle expands or collapses a directory
 func (i *Item) Toggle() {
-	if i.IsDir {
+	if i.IsDir && !i.Cycle {
 		i.Expanded = !i.Expanded
 	}
 }
@@ -58,6 +93,16 @@ func (i *Item) HasChildren() bool {
 	return len(i.Children) > 0
 }
 
+// SetChildren replaces the directory's children and marks them loaded
+func (i *Item) SetChildren(children []*Item) {
+	for _, child := range children {
+		child.Parent = i
+	}
+	i.Children = children
+	i.Loaded = true
+	i.Loading = false
+}
+
 // FilterText returns the text used for filtering
 func (i Item) FilterText() string {
 	return strings.ToLower(i.Name)
@@ -73,18 +118,34 @@ func (i Item) Description() string {
 	return i.Path
 }
 
-// FilterValue implements list.Item interface for bubbles list
+// FilterValue implements list.Item interface for bubbles list.
+// When a document title is shown, both title and file name are matched.
 func (i Item) FilterValue() string {
+	if i.DocTitle != "" {
+		return i.DocTitle + " " + i.Name
+	}
 	return i.Name
 }
 
 // IsMarkdown returns true if the file is a markdown file
 func (i *Item) IsMarkdown() bool {
+	return !i.IsDir && format.IsMarkdown(i.Name)
+}
+
+// Icon returns the tree icon for the file's document format
+func (i *Item) Icon() string {
 	if i.IsDir {
-		return false
+		return ""
 	}
-	ext := strings.ToLower(filepath.Ext(i.Name))
-	return ext == ".md" || ext == ".markdown"
+	return format.For(i.Name).Icon
+}
+
+// sortTitle returns the front matter title, or the name when there is none
+func (i *Item) sortTitle() string {
+	if title := i.Matter.Title(); title != "" {
+		return title
+	}
+	return i.Name
 }
 
 // DisplayName returns the name with directory indicator if needed
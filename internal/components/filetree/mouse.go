@@ -0,0 +1,53 @@
+package filetree
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listHeaderLines is the height of the list's title area: the filter input
+// and its padding while filtering, otherwise a single blank line
+func (m Model) listHeaderLines() int {
+	if m.list.FilterState() == list.Filtering {
+		return 1 + m.list.Styles.TitleBar.GetPaddingTop() + m.list.Styles.TitleBar.GetPaddingBottom()
+	}
+	return 1
+}
+
+// ItemAt returns the visible item at row y of the component, or nil
+func (m Model) ItemAt(y int) *Item {
+	row := y - m.listHeaderLines()
+	if row < 0 || row >= m.list.Paginator.PerPage {
+		return nil
+	}
+
+	index := m.list.Paginator.Page*m.list.Paginator.PerPage + row
+	visible := m.list.VisibleItems()
+	if index >= len(visible) {
+		return nil
+	}
+	item, _ := visible[index].(*Item)
+	return item
+}
+
+// HandleClick handles a left click at column x, row y of the component.
+// Clicking selects an item; clicking a file or a directory's arrow (or
+// double-clicking a directory) opens or toggles it.
+func (m Model) HandleClick(x, y int, double bool) (Model, tea.Cmd) {
+	if m.prompt != promptNone || m.confirm != confirmNone || m.IsFiltering() {
+		return m, nil
+	}
+
+	item := m.ItemAt(y)
+	if item == nil {
+		return m, nil
+	}
+	m.selectPath(item.Path)
+
+	arrow := item.Depth * 2
+	onArrow := x >= arrow && x <= arrow+1
+	if !item.IsDir || onArrow || double {
+		return m.handleSelect()
+	}
+	return m, nil
+}
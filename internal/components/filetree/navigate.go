@@ -0,0 +1,308 @@
+package filetree
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+)
+
+// unlimitedDepth expands without a depth limit (ScanOptions.MaxDepth still applies)
+const unlimitedDepth = int(^uint(0) >> 1)
+
+// dirRef identifies a directory to load in the background
+type dirRef struct {
+	path  string
+	depth int
+}
+
+// dirsLoadedMsg is sent when directories have been loaded for an expand or reveal command
+type dirsLoadedMsg struct {
+	gen int
+
+	// key records the options the directories were listed with
+	key listingKey
+
+	// levels maps directory paths to their scanned children
+	levels map[string][]*Item
+
+	// under and depth select the directories to expand: those within under
+	// and shallower than depth. With collapse set, deeper ones are collapsed.
+	under    string
+	depth    int
+	collapse bool
+
+	// reveal is a file whose ancestors are expanded before selecting it
+	reveal string
+
+	// expand lists further directories to expand
+	expand map[string]bool
+}
+
+// loadDirs scans directories below roots in the background, down to (but not
+// including) depth. Directories in known are already loaded; only their
+// subdirectories are visited.
+func loadDirs(ctx context.Context, roots []dirRef, depth int, known map[string][]dirRef, opts ScanOptions) map[string][]*Item {
+	levels := make(map[string][]*Item)
+
+	var visit func(ref dirRef)
+	visit = func(ref dirRef) {
+		if ctx.Err() != nil || ref.depth >= depth {
+			return
+		}
+		if opts.MaxDepth >= 0 && ref.depth >= opts.MaxDepth {
+			return
+		}
+
+		if children, ok := known[ref.path]; ok {
+			for _, child := range children {
+				visit(child)
+			}
+			return
+		}
+
+		items, err := scanLevel(ctx, ref.path, ref.depth+1, opts)
+		if err != nil {
+			return
+		}
+		levels[ref.path] = items
+		for _, item := range items {
+			if item.IsDir && !item.Cycle {
+				visit(dirRef{path: item.Path, depth: item.Depth})
+			}
+		}
+	}
+
+	for _, root := range roots {
+		visit(root)
+	}
+	return levels
+}
+
+// knownDirs snapshots the subdirectories of every loaded directory, so a
+// background load can walk the existing tree without touching its items
+func (m Model) knownDirs() map[string][]dirRef {
+	known := make(map[string][]dirRef)
+	walkItems(m.items, func(item *Item) {
+		if !item.IsDir || !item.Loaded {
+			return
+		}
+		refs := []dirRef{}
+		for _, child := range item.Children {
+			if child.IsDir && !child.Cycle {
+				refs = append(refs, dirRef{path: child.Path, depth: child.Depth})
+			}
+		}
+		known[item.Path] = refs
+	})
+	return known
+}
+
+// rootDirs returns references to the root level directories
+func (m Model) rootDirs() []dirRef {
+	var refs []dirRef
+	for _, item := range m.items {
+		if item.IsDir && !item.Cycle {
+			refs = append(refs, dirRef{path: item.Path, depth: item.Depth})
+		}
+	}
+	return refs
+}
+
+// expandRecursive expands a directory and everything below it
+func (m Model) expandRecursive() (Model, tea.Cmd) {
+	item := m.SelectedItem()
+	if item == nil {
+		return m, nil
+	}
+	if !item.IsDir {
+		item = item.Parent
+	}
+	if item == nil || item.Cycle {
+		return m, nil
+	}
+
+	item.Loading = true
+	m.rebuildList()
+
+	msg := dirsLoadedMsg{under: item.Path, depth: unlimitedDepth}
+	roots := []dirRef{{path: item.Path, depth: item.Depth}}
+	return m, tea.Batch(m.loadDirsCmd(msg, roots, unlimitedDepth), m.spinner.Tick)
+}
+
+// expandToDepth expands every directory shallower than depth and collapses the rest
+func (m Model) expandToDepth(depth int) (Model, tea.Cmd) {
+	msg := dirsLoadedMsg{under: m.RootPath, depth: depth, collapse: true}
+	return m, m.loadDirsCmd(msg, m.rootDirs(), depth)
+}
+
+// collapseAll collapses every directory
+func (m Model) collapseAll() (Model, tea.Cmd) {
+	walkItems(m.items, func(item *Item) {
+		item.Expanded = false
+	})
+
+	// Keep the cursor on the top level ancestor of the selection
+	if item := m.SelectedItem(); item != nil {
+		for item.Parent != nil {
+			item = item.Parent
+		}
+		m.rebuildList()
+		m.selectPath(item.Path)
+		return m, nil
+	}
+
+	m.rebuildList()
+	return m, nil
+}
+
+// collapseOrParent collapses the selected directory, or moves to its parent
+func (m Model) collapseOrParent() (Model, tea.Cmd) {
+	item := m.SelectedItem()
+	if item == nil {
+		return m, nil
+	}
+
+	if item.IsDir && item.Expanded {
+		item.Expanded = false
+		m.rebuildList()
+		return m, nil
+	}
+	if item.Parent != nil {
+		m.selectPath(item.Parent.Path)
+	}
+	return m, nil
+}
+
+// expandOrOpen expands the selected directory (moving into it if already
+// expanded) or opens the selected file
+func (m Model) expandOrOpen() (Model, tea.Cmd) {
+	item := m.SelectedItem()
+	if item == nil {
+		return m, nil
+	}
+
+	if item.IsDir && item.Expanded {
+		if len(item.Children) > 0 {
+			m.selectPath(item.Children[0].Path)
+		}
+		return m, nil
+	}
+	return m.handleSelect()
+}
+
+// Reveal expands the directories leading to path and selects it
+func (m Model) Reveal(path string) tea.Cmd {
+	if path == "" || !fsutil.IsWithin(path, m.RootPath) {
+		return nil
+	}
+
+	// Load each ancestor that hasn't been listed yet
+	var roots []dirRef
+	for dir := filepath.Dir(path); fsutil.IsWithin(dir, m.RootPath) && dir != m.RootPath; dir = filepath.Dir(dir) {
+		item := m.findItem(dir)
+		if item == nil || !item.Loaded {
+			rel, _ := filepath.Rel(m.RootPath, dir)
+			roots = append([]dirRef{{path: dir, depth: strings.Count(filepath.ToSlash(rel), "/")}}, roots...)
+		}
+	}
+
+	msg := dirsLoadedMsg{reveal: path}
+	ctx, gen := m.scanOptions.Scanner.current()
+	opts := m.scanOptions
+	return func() tea.Msg {
+		msg.gen = gen
+		msg.key = opts.key()
+		msg.levels = make(map[string][]*Item)
+		for _, root := range roots {
+			items, err := scanLevel(ctx, root.path, root.depth+1, opts)
+			if err != nil {
+				break
+			}
+			msg.levels[root.path] = items
+		}
+		return msg
+	}
+}
+
+// loadDirsCmd loads directories below roots in the background and reports them in msg
+func (m Model) loadDirsCmd(msg dirsLoadedMsg, roots []dirRef, depth int) tea.Cmd {
+	known := m.knownDirs()
+	ctx, gen := m.scanOptions.Scanner.current()
+	opts := m.scanOptions
+	return func() tea.Msg {
+		msg.gen = gen
+		msg.key = opts.key()
+		msg.levels = loadDirs(ctx, roots, depth, known, opts)
+		return msg
+	}
+}
+
+// handleDirsLoaded attaches loaded directories and applies the expansion
+func (m Model) handleDirsLoaded(msg dirsLoadedMsg) (Model, tea.Cmd) {
+	if msg.gen != m.scanOptions.Scanner.Generation() {
+		return m, nil
+	}
+
+	// Drop levels listed before the options changed; those directories load again on expand
+	if msg.key != m.scanOptions.key() {
+		msg.levels = nil
+	}
+
+	// Attach new levels to directories that weren't loaded yet; attached
+	// children are visited in turn so deeper levels connect as well
+	var attach func(items []*Item)
+	attach = func(items []*Item) {
+		for _, item := range items {
+			if !item.IsDir {
+				continue
+			}
+			if children, ok := msg.levels[item.Path]; ok && !item.Loaded {
+				sortItems(children, m.scanOptions.Sort, m.scanOptions.SortReverse)
+				item.SetChildren(children)
+			}
+			attach(item.Children)
+		}
+	}
+	attach(m.items)
+
+	if msg.under != "" {
+		walkItems(m.items, func(item *Item) {
+			if !item.IsDir || !fsutil.IsWithin(item.Path, msg.under) {
+				return
+			}
+			if item.Path == msg.under {
+				item.Loading = false
+			}
+			if item.Depth < msg.depth && item.Loaded {
+				item.Expanded = !item.Cycle
+			} else if msg.collapse {
+				item.Expanded = false
+			}
+		})
+	}
+
+	if msg.reveal != "" {
+		for dir := filepath.Dir(msg.reveal); fsutil.IsWithin(dir, m.RootPath) && dir != m.RootPath; dir = filepath.Dir(dir) {
+			if item := m.findItem(dir); item != nil {
+				item.Expanded = true
+			}
+		}
+	}
+
+	for dir := range msg.expand {
+		if item := m.findItem(dir); item != nil && item.Loaded {
+			item.Expanded = true
+		}
+	}
+
+	m.applyTitles()
+	m.rebuildList()
+	if msg.reveal != "" {
+		m.selectPath(msg.reveal)
+	}
+	return m, tea.Batch(m.titlePass(), m.metadataPass())
+}
@@ -0,0 +1,467 @@
+package filetree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/walk"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FileOpMsg is sent to the parent after a file management action completes
+type FileOpMsg struct {
+	// Op is a short verb describing the action (created, renamed, deleted, ...)
+	Op string
+
+	// Path is the affected path (the old path for renames and deletes)
+	Path string
+
+	// NewPath is the resulting path (empty for deletes)
+	NewPath string
+
+	// Message is a human readable summary for the status bar
+	Message string
+
+	// Error is set when the action failed
+	Error error
+}
+
+// opDoneMsg is sent internally when an operation finishes and the tree needs refreshing
+type opDoneMsg struct {
+	result FileOpMsg
+	undo   *undoEntry
+
+	// updates are link rewrites offered after a rename
+	updates []linkUpdate
+
+	// relist replaces the result's paths as the entries whose directories
+	// are listed again (set when undoing)
+	relist []string
+}
+
+// linksUpdatedMsg is sent internally after link rewrites are written
+type linksUpdatedMsg struct {
+	files    int
+	links    int
+	original map[string][]byte
+	err      error
+}
+
+// undoEntry records how to reverse a completed operation
+type undoEntry struct {
+	// description is shown after undoing
+	description string
+
+	// revert performs the reversal
+	revert func() error
+
+	// paths are the entries the operation changed, listed again after reverting
+	paths []string
+}
+
+// linkUpdate is a pending link rewrite in a single file
+type linkUpdate struct {
+	path  string
+	edits []mdlinks.Edit
+}
+
+var (
+	// errExists is returned when an operation would overwrite an existing path
+	errExists = errors.New("target already exists")
+
+	// errIntoItself is returned when a directory would move inside itself
+	errIntoItself = errors.New("can't move a directory inside itself")
+)
+
+// createFile creates a new markdown file with template content, failing
+// rather than overwriting a file that already exists
+func createFile(path, content string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "created", Path: path, Error: err}}
+		}
+		if err := writeNewFile(path, []byte(content)); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "created", Path: path, Error: existsError(err)}}
+		}
+
+		return opDoneMsg{
+			result: FileOpMsg{
+				Op:      "created",
+				Path:    path,
+				NewPath: path,
+				Message: "created " + filepath.Base(path),
+			},
+			undo: &undoEntry{
+				description: "removed " + filepath.Base(path),
+				revert:      func() error { return os.Remove(path) },
+			},
+		}
+	}
+}
+
+// createDir creates a new directory
+func createDir(path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "created", Path: path, Error: err}}
+		}
+		if err := os.Mkdir(path, 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "created", Path: path, Error: existsError(err)}}
+		}
+
+		return opDoneMsg{
+			result: FileOpMsg{
+				Op:      "created",
+				Path:    path,
+				NewPath: path,
+				Message: "created " + filepath.Base(path) + "/",
+			},
+			undo: &undoEntry{
+				description: "removed " + filepath.Base(path) + "/",
+				revert:      func() error { return os.Remove(path) },
+			},
+		}
+	}
+}
+
+// renamePath renames or moves a file or directory. Links in other markdown
+// files under rootPath that point at the old location are collected (but not
+// yet rewritten) so the user can confirm the update.
+func renamePath(rootPath string, opts ScanOptions, oldPath, newPath string) tea.Cmd {
+	return func() tea.Msg {
+		if oldPath == newPath {
+			return opDoneMsg{result: FileOpMsg{Op: "renamed", Path: oldPath, NewPath: newPath}}
+		}
+		if fsutil.IsWithin(newPath, oldPath) {
+			return opDoneMsg{result: FileOpMsg{Op: "renamed", Path: oldPath, Error: errIntoItself}}
+		}
+
+		// Collect link updates while the old layout is still on disk
+		updates := findLinkUpdates(rootPath, opts, oldPath, newPath)
+
+		if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "renamed", Path: oldPath, Error: err}}
+		}
+		if err := movePath(oldPath, newPath); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "renamed", Path: oldPath, Error: existsError(err)}}
+		}
+
+		return opDoneMsg{
+			result: FileOpMsg{
+				Op:      "renamed",
+				Path:    oldPath,
+				NewPath: newPath,
+				Message: "renamed " + filepath.Base(oldPath) + " → " + relativeTo(rootPath, newPath),
+			},
+			undo: &undoEntry{
+				description: "restored " + filepath.Base(oldPath),
+				revert:      func() error { return existsError(movePath(newPath, oldPath)) },
+			},
+			updates: updates,
+		}
+	}
+}
+
+// duplicatePath copies a file or directory tree
+func duplicatePath(srcPath, dstPath string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "duplicated", Path: srcPath, Error: err}}
+		}
+		if err := copyNew(srcPath, dstPath); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "duplicated", Path: srcPath, Error: existsError(err)}}
+		}
+
+		return opDoneMsg{
+			result: FileOpMsg{
+				Op:      "duplicated",
+				Path:    srcPath,
+				NewPath: dstPath,
+				Message: "duplicated as " + filepath.Base(dstPath),
+			},
+			undo: &undoEntry{
+				description: "removed " + filepath.Base(dstPath),
+				revert:      func() error { return os.RemoveAll(dstPath) },
+			},
+		}
+	}
+}
+
+// trashPath moves a file or directory into skim's trash directory
+func trashPath(path string) tea.Cmd {
+	return func() tea.Msg {
+		trashDir, err := TrashDir()
+		if err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "deleted", Path: path, Error: err}}
+		}
+		if err := os.MkdirAll(trashDir, 0o755); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "deleted", Path: path, Error: err}}
+		}
+
+		trashed := filepath.Join(trashDir, strconv.FormatInt(time.Now().UnixNano(), 10)+"-"+filepath.Base(path))
+		if err := movePath(path, trashed); err != nil {
+			return opDoneMsg{result: FileOpMsg{Op: "deleted", Path: path, Error: err}}
+		}
+
+		return opDoneMsg{
+			result: FileOpMsg{
+				Op:      "deleted",
+				Path:    path,
+				Message: "moved " + filepath.Base(path) + " to trash (u to undo)",
+			},
+			undo: &undoEntry{
+				description: "restored " + filepath.Base(path),
+				revert:      func() error { return existsError(movePath(trashed, path)) },
+			},
+		}
+	}
+}
+
+// applyLinkUpdates rewrites link targets, remembering original contents for undo
+func applyLinkUpdates(updates []linkUpdate) tea.Cmd {
+	return func() tea.Msg {
+		original := make(map[string][]byte)
+		links := 0
+
+		for _, u := range updates {
+			content, err := os.ReadFile(u.path)
+			if err != nil {
+				return linksUpdatedMsg{original: original, err: err}
+			}
+
+			updated := mdlinks.Apply(string(content), u.edits)
+			if err := writeKeepingMode(u.path, []byte(updated)); err != nil {
+				return linksUpdatedMsg{original: original, err: err}
+			}
+			original[u.path] = content
+			links += len(u.edits)
+		}
+
+		return linksUpdatedMsg{files: len(original), links: links, original: original}
+	}
+}
+
+// findLinkUpdates finds relative links in markdown files under rootPath that
+// point at oldPath (or inside it, for directories) and computes their new
+// targets. Files that move along with oldPath get their links to files that
+// stay put rewritten instead; their updates are keyed by the new location.
+func findLinkUpdates(rootPath string, opts ScanOptions, oldPath, newPath string) []linkUpdate {
+	var updates []linkUpdate
+
+	_ = walk.Markdown(rootPath, opts.Options, func(path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		moving := fsutil.IsWithin(path, oldPath)
+		dest := path
+		if moving {
+			dest = newPath + strings.TrimPrefix(path, oldPath)
+			// A file renamed in place still reaches everything it linked to
+			if filepath.Dir(dest) == filepath.Dir(path) {
+				return nil
+			}
+		}
+
+		var edits []mdlinks.Edit
+		for _, link := range mdlinks.Extract(string(content)) {
+			// Wiki links find their target by name, wherever it lives
+			if link.Kind == mdlinks.Wiki {
+				continue
+			}
+			resolved := mdlinks.Resolve(path, link.Target)
+			if resolved == "" || filepath.IsAbs(link.Target) {
+				continue
+			}
+
+			// Links between files that move together keep working
+			target := resolved
+			if fsutil.IsWithin(resolved, oldPath) {
+				if moving {
+					continue
+				}
+				target = newPath + strings.TrimPrefix(resolved, oldPath)
+			} else if !moving {
+				continue
+			}
+
+			if rewritten := mdlinks.RelativeTarget(dest, target, link.Target); rewritten != link.Target {
+				edits = append(edits, mdlinks.Edit{Link: link, Target: rewritten})
+			}
+		}
+
+		if len(edits) > 0 {
+			updates = append(updates, linkUpdate{path: dest, edits: edits})
+		}
+		return nil
+	})
+
+	return updates
+}
+
+// countEdits returns the total number of link edits across updates
+func countEdits(updates []linkUpdate) int {
+	n := 0
+	for _, u := range updates {
+		n += len(u.edits)
+	}
+	return n
+}
+
+// relativeTo returns path relative to root, falling back to path itself
+func relativeTo(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// TrashDir returns the directory deleted files are moved to
+func TrashDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating trash directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "skim", "trash"), nil
+}
+
+// existsError turns the error from an exclusive create or rename into
+// errExists when the target was already there
+func existsError(err error) error {
+	if errors.Is(err, fs.ErrExist) {
+		return errExists
+	}
+	return err
+}
+
+// movePath renames a path without replacing an existing dst, falling back to
+// copy and delete across filesystems
+func movePath(src, dst string) error {
+	if fsutil.IsWithin(dst, src) {
+		return errIntoItself
+	}
+	err := fsutil.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyNew(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyNew copies src to dst, which must not exist. A partial copy is
+// removed, but never a dst something else created first.
+func copyNew(src, dst string) error {
+	err := copyPath(src, dst)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		_ = os.RemoveAll(dst)
+	}
+	return err
+}
+
+// writeNewFile creates a file that must not exist yet and writes content to it
+func writeNewFile(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeKeepingMode replaces a file's content, keeping its existing permissions
+func writeKeepingMode(path string, content []byte) error {
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return fsutil.WriteFile(path, content, perm)
+}
+
+// copyPath copies a file or a directory tree, preserving permissions
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+
+	case info.IsDir():
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+}
+
+// copyFile copies a single regular file
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// duplicateName suggests a free name for a copy: notes.md -> notes-copy.md, notes-copy-2.md, ...
+func duplicateName(path string) string {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	candidate := filepath.Join(dir, stem+"-copy"+ext)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = filepath.Join(dir, stem+"-copy-"+strconv.Itoa(n)+ext)
+	}
+}
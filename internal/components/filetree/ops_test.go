@@ -0,0 +1,288 @@
+package filetree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeFiles creates files under root, making their directories
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFile returns a file's content, or "<missing>" if it doesn't exist
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// runOp runs a file operation command and returns its result
+func runOp(t *testing.T, cmd tea.Cmd) opDoneMsg {
+	t.Helper()
+	msg, ok := cmd().(opDoneMsg)
+	if !ok {
+		t.Fatalf("command returned %T, want opDoneMsg", msg)
+	}
+	return msg
+}
+
+// isolate keeps the user's git excludes and trash out of a test
+func isolate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"taken.md": "keep", "dir/x.md": ""})
+
+	tests := []struct {
+		name    string
+		cmd     tea.Cmd
+		path    string
+		wantErr error
+	}{
+		{"file", createFile(filepath.Join(root, "new.md"), "# New\n"), "new.md", nil},
+		{"file in new directory", createFile(filepath.Join(root, "a/b/new.md"), ""), "a/b/new.md", nil},
+		{"existing file", createFile(filepath.Join(root, "taken.md"), "lost"), "taken.md", errExists},
+		{"directory", createDir(filepath.Join(root, "fresh")), "fresh", nil},
+		{"existing directory", createDir(filepath.Join(root, "dir")), "dir", errExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := runOp(t, tt.cmd)
+			if msg.result.Error != tt.wantErr {
+				t.Fatalf("error = %v, want %v", msg.result.Error, tt.wantErr)
+			}
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			if _, err := os.Stat(path); err != nil {
+				t.Fatalf("%s missing: %v", tt.path, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if err := msg.undo.revert(); err != nil {
+				t.Fatalf("undo: %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("%s still exists after undo", tt.path)
+			}
+		})
+	}
+	if got := readFile(t, filepath.Join(root, "taken.md")); got != "keep" {
+		t.Errorf("taken.md = %q, want it untouched", got)
+	}
+}
+
+func TestRename(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.md": "a", "b.md": "b", "dir/c.md": "c"})
+	opts := ScanOptionsFor(root)
+	path := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	if msg := runOp(t, renamePath(root, opts, path("a.md"), path("b.md"))); msg.result.Error != errExists {
+		t.Errorf("rename onto a file: error = %v, want %v", msg.result.Error, errExists)
+	}
+	if msg := runOp(t, renamePath(root, opts, path("dir"), path("dir/sub"))); msg.result.Error != errIntoItself {
+		t.Errorf("move into itself: error = %v, want %v", msg.result.Error, errIntoItself)
+	}
+	if got := readFile(t, path("a.md")) + readFile(t, path("b.md")); got != "ab" {
+		t.Fatalf("files changed by failed renames: %q", got)
+	}
+
+	msg := runOp(t, renamePath(root, opts, path("a.md"), path("dir/new/a.md")))
+	if msg.result.Error != nil {
+		t.Fatal(msg.result.Error)
+	}
+	if got := readFile(t, path("dir/new/a.md")); got != "a" {
+		t.Errorf("moved file = %q, want %q", got, "a")
+	}
+
+	// Undo refuses to replace a file that took the old name
+	writeFiles(t, root, map[string]string{"a.md": "new"})
+	if err := msg.undo.revert(); err != errExists {
+		t.Errorf("undo onto a file: error = %v, want %v", err, errExists)
+	}
+	if err := os.Remove(path("a.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.undo.revert(); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if got := readFile(t, path("a.md")) + readFile(t, path("dir/new/a.md")); got != "a<missing>" {
+		t.Errorf("after undo: %q", got)
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.md": "a", "a-copy.md": "other", "dir/b.md": "b"})
+
+	if msg := runOp(t, duplicatePath(filepath.Join(root, "a.md"), filepath.Join(root, "a-copy.md"))); msg.result.Error != errExists {
+		t.Errorf("duplicate onto a file: error = %v, want %v", msg.result.Error, errExists)
+	}
+	if got := readFile(t, filepath.Join(root, "a-copy.md")); got != "other" {
+		t.Errorf("a-copy.md = %q, want it untouched", got)
+	}
+
+	dst := duplicateName(filepath.Join(root, "dir"))
+	if filepath.Base(dst) != "dir-copy" {
+		t.Errorf("duplicateName() = %q, want dir-copy", dst)
+	}
+	msg := runOp(t, duplicatePath(filepath.Join(root, "dir"), dst))
+	if msg.result.Error != nil {
+		t.Fatal(msg.result.Error)
+	}
+	if got := readFile(t, filepath.Join(dst, "b.md")); got != "b" {
+		t.Errorf("copied file = %q, want %q", got, "b")
+	}
+	if got := filepath.Base(duplicateName(filepath.Join(root, "a.md"))); got != "a-copy-2.md" {
+		t.Errorf("duplicateName() = %q, want a-copy-2.md", got)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.md": "a"})
+	path := filepath.Join(root, "a.md")
+
+	msg := runOp(t, trashPath(path))
+	if msg.result.Error != nil {
+		t.Fatal(msg.result.Error)
+	}
+	if got := readFile(t, path); got != "<missing>" {
+		t.Errorf("trashed file still there: %q", got)
+	}
+	if err := msg.undo.revert(); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if got := readFile(t, path); got != "a" {
+		t.Errorf("restored file = %q, want %q", got, "a")
+	}
+}
+
+func TestFindLinkUpdates(t *testing.T) {
+	isolate(t)
+	files := map[string]string{
+		"a.md":          "[b](b.md) [self](#top) [web](https://x.io) [[b]]",
+		"b.md":          "[a](a.md#intro) [dir](docs/guide.md) [docs](docs)",
+		"docs/guide.md": "[a](../a.md) [ref](./ref.md)",
+		"docs/ref.md":   "[guide](guide.md) [up](../b.md)",
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     map[string][]string // file (after the move) -> new link targets
+	}{
+		{
+			name: "rename in place",
+			from: "a.md", to: "c.md",
+			want: map[string][]string{
+				"b.md":          {"c.md#intro"},
+				"docs/guide.md": {"../c.md"},
+			},
+		},
+		{
+			name: "move a file down",
+			from: "a.md", to: "sub/a.md",
+			want: map[string][]string{
+				"sub/a.md":      {"../b.md"},
+				"b.md":          {"sub/a.md#intro"},
+				"docs/guide.md": {"../sub/a.md"},
+			},
+		},
+		{
+			name: "move a directory",
+			from: "docs", to: "manual/docs",
+			want: map[string][]string{
+				"b.md":                 {"manual/docs/guide.md", "manual/docs"},
+				"manual/docs/guide.md": {"../../a.md"},
+				"manual/docs/ref.md":   {"../../b.md"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, files)
+			oldPath := filepath.Join(root, filepath.FromSlash(tt.from))
+			newPath := filepath.Join(root, filepath.FromSlash(tt.to))
+
+			got := make(map[string][]string)
+			for _, u := range findLinkUpdates(root, ScanOptionsFor(root), oldPath, newPath) {
+				rel, _ := filepath.Rel(root, u.path)
+				for _, e := range u.edits {
+					got[filepath.ToSlash(rel)] = append(got[filepath.ToSlash(rel)], e.Target)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findLinkUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameUpdatesLinks(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.md": "See [b](b.md).\n",
+		"b.md": "Back to [a](a.md#top).\n",
+	})
+
+	msg := runOp(t, renamePath(root, ScanOptionsFor(root), filepath.Join(root, "a.md"), filepath.Join(root, "sub", "a.md")))
+	if msg.result.Error != nil {
+		t.Fatal(msg.result.Error)
+	}
+	done, ok := applyLinkUpdates(msg.updates)().(linksUpdatedMsg)
+	if !ok || done.err != nil {
+		t.Fatalf("applyLinkUpdates() = %+v", done)
+	}
+
+	want := map[string]string{
+		"sub/a.md": "See [b](../b.md).\n",
+		"b.md":     "Back to [a](sub/a.md#top).\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, filepath.Join(root, filepath.FromSlash(name))); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	if done.files != 2 || done.links != 2 {
+		t.Errorf("updated %d links in %d files, want 2 in 2", done.links, done.files)
+	}
+}
+
+func TestConfirmClearsTarget(t *testing.T) {
+	for _, key := range []string{"y", "n"} {
+		m := Model{confirm: confirmDelete, confirmTarget: &Item{Path: filepath.Join(t.TempDir(), "a.md")}}
+		m, _ = m.handleConfirmKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if m.confirm != confirmNone || m.confirmTarget != nil {
+			t.Errorf("after %q: confirm = %v, target = %v; want both cleared", key, m.confirm, m.confirmTarget)
+		}
+	}
+}
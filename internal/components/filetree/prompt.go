@@ -0,0 +1,348 @@
+package filetree
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptKind identifies what the name prompt is collecting
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptNewFile
+	promptNewDir
+	promptRename
+	promptDuplicate
+)
+
+// confirmKind identifies what a yes/no confirmation is for
+type confirmKind int
+
+const (
+	confirmNone confirmKind = iota
+	confirmDelete
+	confirmLinkUpdate
+)
+
+// Template is a starting point for new markdown files
+type Template struct {
+	// Name is shown in the prompt
+	Name string
+
+	// Content may contain {{title}} and {{date}} placeholders
+	Content string
+}
+
+// builtinTemplates are always available when creating files
+var builtinTemplates = []Template{
+	{Name: "heading", Content: "# {{title}}\n\n"},
+	{Name: "blank", Content: ""},
+}
+
+// TemplateDir is where per-project templates live, relative to the root
+const TemplateDir = ".skim/templates"
+
+// loadTemplates returns the built-in templates plus any *.md files in TemplateDir
+func loadTemplates(rootPath string) []Template {
+	templates := append([]Template(nil), builtinTemplates...)
+
+	entries, err := os.ReadDir(filepath.Join(rootPath, TemplateDir))
+	if err != nil {
+		return templates
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isMarkdownFile(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(rootPath, TemplateDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		templates = append(templates, Template{
+			Name:    strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+			Content: string(content),
+		})
+	}
+	return templates
+}
+
+// expandTemplate fills in template placeholders for a new file
+func expandTemplate(t Template, path string) string {
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	title := strings.NewReplacer("-", " ", "_", " ").Replace(stem)
+	if title != "" {
+		title = strings.ToUpper(title[:1]) + title[1:]
+	}
+
+	return strings.NewReplacer(
+		"{{title}}", title,
+		"{{date}}", time.Now().Format("2006-01-02"),
+	).Replace(t.Content)
+}
+
+// newPromptInput creates the text input used for name prompts
+func newPromptInput() textinput.Model {
+	ti := textinput.New()
+	ti.PromptStyle = styles.FilterPromptStyle
+	ti.TextStyle = styles.FilterInputStyle
+	ti.Cursor.Style = styles.FilterCursorStyle
+	ti.CharLimit = 255
+	return ti
+}
+
+// startPrompt opens the name prompt prefilled with a path relative to the root
+func (m Model) startPrompt(kind promptKind, target *Item, value string) (Model, tea.Cmd) {
+	m.prompt = kind
+	m.promptTarget = target
+
+	switch kind {
+	case promptNewFile:
+		m.promptInput.Prompt = "new: "
+		m.templates = loadTemplates(m.RootPath)
+		m.templateIndex = 0
+	case promptNewDir:
+		m.promptInput.Prompt = "mkdir: "
+	case promptRename:
+		m.promptInput.Prompt = "move: "
+	case promptDuplicate:
+		m.promptInput.Prompt = "copy: "
+	}
+
+	m.promptInput.Width = m.width - lipgloss.Width(m.promptInput.Prompt) - 1
+	m.promptInput.SetValue(value)
+	m.promptInput.CursorEnd()
+	m.promptInput.Focus()
+	return m, textinput.Blink
+}
+
+// targetDir returns the directory new entries should be created in, relative
+// to the root and ending in a separator (or empty for the root itself)
+func (m Model) targetDir() string {
+	item := m.SelectedItem()
+	if item == nil {
+		return ""
+	}
+
+	dir := filepath.Dir(item.Path)
+	if item.IsDir && item.Expanded {
+		dir = item.Path
+	}
+
+	rel := relativeTo(m.RootPath, dir)
+	if rel == "." {
+		return ""
+	}
+	return rel + string(filepath.Separator)
+}
+
+// handlePromptKey handles keys while the name prompt is open
+func (m Model) handlePromptKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closePrompt()
+		return m, nil
+
+	case "tab":
+		m.promptInput.SetValue(completePath(m.RootPath, m.promptInput.Value()))
+		m.promptInput.CursorEnd()
+		return m, nil
+
+	case "ctrl+t":
+		if m.prompt == promptNewFile && len(m.templates) > 0 {
+			m.templateIndex = (m.templateIndex + 1) % len(m.templates)
+		}
+		return m, nil
+
+	case "enter":
+		return m.submitPrompt()
+	}
+
+	var cmd tea.Cmd
+	m.promptInput, cmd = m.promptInput.Update(msg)
+	return m, cmd
+}
+
+// submitPrompt runs the action for the current prompt
+func (m Model) submitPrompt() (Model, tea.Cmd) {
+	value := strings.TrimSpace(m.promptInput.Value())
+	kind := m.prompt
+	target := m.promptTarget
+	m.closePrompt()
+
+	if value == "" || strings.HasSuffix(value, string(filepath.Separator)) && kind != promptNewDir {
+		return m, nil
+	}
+
+	path := filepath.Join(m.RootPath, value)
+	if !fsutil.IsWithin(path, m.RootPath) {
+		return m, opError("refusing to write outside " + m.RootPath)
+	}
+
+	switch kind {
+	case promptNewFile:
+		if filepath.Ext(path) == "" {
+			path += ".md"
+		}
+		content := ""
+		if m.templateIndex < len(m.templates) {
+			content = expandTemplate(m.templates[m.templateIndex], path)
+		}
+		return m, createFile(path, content)
+
+	case promptNewDir:
+		return m, createDir(path)
+
+	case promptRename:
+		if target == nil {
+			return m, nil
+		}
+		return m, renamePath(m.RootPath, m.scanOptions, target.Path, path)
+
+	case promptDuplicate:
+		if target == nil {
+			return m, nil
+		}
+		return m, duplicatePath(target.Path, path)
+	}
+
+	return m, nil
+}
+
+// closePrompt hides the name prompt
+func (m *Model) closePrompt() {
+	m.prompt = promptNone
+	m.promptTarget = nil
+	m.promptInput.Blur()
+	m.promptInput.SetValue("")
+}
+
+// opError reports a failed action to the parent
+func opError(message string) tea.Cmd {
+	return func() tea.Msg {
+		return FileOpMsg{Error: errorString(message)}
+	}
+}
+
+// errorString is a simple error type for user-facing messages
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+// handleConfirmKey handles keys while a yes/no confirmation is showing
+func (m Model) handleConfirmKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	kind := m.confirm
+	switch msg.String() {
+	case "y", "Y":
+		target := m.confirmTarget
+		m.confirm = confirmNone
+		m.confirmTarget = nil
+		switch kind {
+		case confirmDelete:
+			if target != nil {
+				return m, trashPath(target.Path)
+			}
+		case confirmLinkUpdate:
+			updates := m.pendingUpdates
+			m.pendingUpdates = nil
+			return m, applyLinkUpdates(updates)
+		}
+		return m, nil
+
+	case "n", "N", "esc", "q":
+		m.confirm = confirmNone
+		m.confirmTarget = nil
+		m.pendingUpdates = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// confirmMessage returns the question shown for the current confirmation
+func (m Model) confirmMessage() string {
+	switch m.confirm {
+	case confirmDelete:
+		if m.confirmTarget != nil {
+			return "Delete " + m.confirmTarget.DisplayName() + "?"
+		}
+	case confirmLinkUpdate:
+		files := len(m.pendingUpdates)
+		links := countEdits(m.pendingUpdates)
+		return "Update " + pluralize(links, "link") + " in " + pluralize(files, "file") + "?"
+	}
+	return ""
+}
+
+// renderPromptLine renders the prompt or confirmation shown at the bottom of the tree
+func (m Model) renderPromptLine() string {
+	lineStyle := lipgloss.NewStyle().
+		Background(lipgloss.AdaptiveColor{Light: "#F0F0F0", Dark: "#2A2A2A"}).
+		Foreground(styles.Highlight).
+		Width(m.width)
+
+	if m.confirm != confirmNone {
+		question := styles.FilterPromptStyle.Render(m.confirmMessage())
+		return lineStyle.Render(question + " " + styles.HelpDescStyle.Render("(y/n)"))
+	}
+
+	line := lineStyle.Render(m.promptInput.View())
+	if m.prompt == promptNewFile && m.templateIndex < len(m.templates) {
+		template := styles.HelpDescStyle.Render("template: " + m.templates[m.templateIndex].Name + " (ctrl+t)")
+		line = lineStyle.Render(template) + "\n" + line
+	}
+	return line
+}
+
+// completePath completes a root-relative path against the filesystem,
+// extending it to the longest common prefix of matching entries
+func completePath(rootPath, value string) string {
+	dirPart, prefix := filepath.Split(value)
+	entries, err := os.ReadDir(filepath.Join(rootPath, dirPart))
+	if err != nil {
+		return value
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += string(filepath.Separator)
+		}
+		matches = append(matches, name)
+	}
+	if len(matches) == 0 {
+		return value
+	}
+
+	sort.Strings(matches)
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return dirPart + common
+}
+
+// pluralize formats a count with a noun, adding "s" when needed
+func pluralize(n int, noun string) string {
+	s := strconv.Itoa(n) + " " + noun
+	if n != 1 {
+		s += "s"
+	}
+	return s
+}
@@ -1,65 +1,84 @@
 package filetree
 
 import (
+	"context"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
+	"sync"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/fsutil"
+	"github.com/Ayushlm10/skim/internal/walk"
 )
 
 // ScanOptions configures directory scanning behavior
 type ScanOptions struct {
-	// ShowHidden includes hidden files/directories (starting with .)
-	ShowHidden bool
+	// Options selects the entries shown: hidden and ignored entries and the
+	// document extensions
+	walk.Options
 
-	// MarkdownOnly only shows .md files (directories always shown if they contain md files)
+	// MarkdownOnly only shows document files (directories are shown if they
+	// contain any). Which files count as documents is set by Extensions.
 	MarkdownOnly bool
 
 	// MaxDepth limits recursion depth (-1 = unlimited)
 	MaxDepth int
 
-	// IgnoreDirs is a list of directory names to skip (e.g., node_modules, vendor)
-	IgnoreDirs []string
-
-	// ShowIgnored when true shows directories that would normally be ignored
-	ShowIgnored bool
-}
-
-// DefaultIgnoreDirs is the list of directories to ignore by default
-// These are common development noise directories that often contain
-// many files but rarely have meaningful markdown documentation
-var DefaultIgnoreDirs = []string{
-	"node_modules",     // JavaScript/Node.js dependencies
-	"vendor",           // Go modules, PHP Composer
-	"__pycache__",      // Python bytecode cache
-	".venv",            // Python virtual environments
-	"venv",             // Python virtual environments (alternative)
-	"dist",             // Build output directories
-	"build",            // Build output directories
-	"target",           // Rust/Java build output
-	".cache",           // Generic cache directories
-	".next",            // Next.js build cache
-	".nuxt",            // Nuxt.js build cache
-	"coverage",         // Test coverage reports
-	".terraform",       // Terraform state/cache
-	".serverless",      // Serverless framework
-	"bower_components", // Bower dependencies (legacy)
+	// Sort selects the order of entries within each directory
+	Sort SortMode
+
+	// SortReverse inverts the sort order (directories still come first)
+	SortReverse bool
+
+	// CountWords reads markdown files to fill in Item.Words
+	CountWords bool
+
+	// Scanner shares the markdown index, worker pool and cancellation across
+	// scans (nil scans without caching)
+	Scanner *Scanner
+}
+
+// listingKey is the part of the options that decides which entries a level lists
+type listingKey struct {
+	markdownOnly bool
+	showHidden   bool
+	showIgnored  bool
+}
+
+// key returns the listing options, to spot levels listed before they changed
+func (opts ScanOptions) key() listingKey {
+	return listingKey{
+		markdownOnly: opts.MarkdownOnly,
+		showHidden:   opts.ShowHidden,
+		showIgnored:  opts.ShowIgnored,
+	}
+}
+
+// ScanOptionsFor returns the default options with ignore-file matching for rootPath
+func ScanOptionsFor(rootPath string) ScanOptions {
+	opts := DefaultScanOptions()
+	opts.Options = walk.OptionsFor(rootPath)
+	opts.Scanner = NewScanner()
+	return opts
 }
 
 // DefaultScanOptions returns sensible defaults
 func DefaultScanOptions() ScanOptions {
 	return ScanOptions{
-		ShowHidden:   false,
+		Options:      walk.Options{IgnoreDirs: walk.DefaultIgnoreDirs},
 		MarkdownOnly: true,
 		MaxDepth:     -1,
-		IgnoreDirs:   DefaultIgnoreDirs,
-		ShowIgnored:  false,
 	}
 }
 
 // ScanDirectory scans a directory and returns tree items
 // Only returns the root level items; children are loaded on expand
 func ScanDirectory(rootPath string, opts ScanOptions) ([]*Item, error) {
+	return scanDirectory(opts.context(), rootPath, opts)
+}
+
+// scanDirectory is ScanDirectory with an explicit cancellation context
+func scanDirectory(ctx context.Context, rootPath string, opts ScanOptions) ([]*Item, error) {
 	// Ensure path is absolute
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
@@ -75,77 +94,138 @@ func ScanDirectory(rootPath string, opts ScanOptions) ([]*Item, error) {
 		return nil, os.ErrInvalid
 	}
 
-	return scanLevel(absPath, 0, opts)
+	return scanLevel(ctx, absPath, 0, opts)
 }
 
-// isIgnoredDir checks if a directory name is in the ignore list
-func isIgnoredDir(name string, ignoreDirs []string) bool {
-	for _, ignored := range ignoreDirs {
-		if name == ignored {
-			return true
-		}
+// context returns the scanner's current cancellation context
+func (opts ScanOptions) context() context.Context {
+	if opts.Scanner == nil {
+		return context.Background()
+	}
+	ctx, _ := opts.Scanner.current()
+	return ctx
+}
+
+// entryIsDir reports whether an entry is a directory, following symlinks
+func entryIsDir(fullPath string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
 	}
-	return false
+	info, err := os.Stat(fullPath)
+	return err == nil && info.IsDir()
 }
 
-// scanLevel scans a single directory level
-func scanLevel(dirPath string, depth int, opts ScanOptions) ([]*Item, error) {
+// listLevel reads a single directory level without looking inside subdirectories.
+// Subdirectories that still need a markdown check are marked Pending.
+func listLevel(dirPath string, depth int, opts ScanOptions) ([]*Item, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
 	var items []*Item
+	realDir, _ := filepath.EvalSymlinks(dirPath)
 
 	for _, entry := range entries {
 		name := entry.Name()
-
-		// Skip hidden files unless enabled
-		if !opts.ShowHidden && strings.HasPrefix(name, ".") {
-			continue
-		}
-
 		fullPath := filepath.Join(dirPath, name)
-		isDir := entry.IsDir()
+		isDir := entryIsDir(fullPath, entry)
 
-		// Skip ignored directories unless ShowIgnored is true
-		if isDir && !opts.ShowIgnored && isIgnoredDir(name, opts.IgnoreDirs) {
+		// Skip hidden and ignored entries unless shown
+		if opts.Skip(fullPath, isDir) {
 			continue
 		}
 
-		// For files, check if markdown (when MarkdownOnly is true)
+		// For files, check if a document (when MarkdownOnly is true)
 		if !isDir && opts.MarkdownOnly {
-			if !isMarkdownFile(name) {
+			if !opts.IsDocument(name) {
 				continue
 			}
 		}
 
-		// For directories, check if they contain any markdown files
+		item := NewItem(fullPath, isDir, depth)
+
+		// For directories, use the cached markdown check when there is one
 		if isDir && opts.MarkdownOnly {
-			hasMarkdown, _ := dirContainsMarkdown(fullPath, opts)
-			if !hasMarkdown {
-				continue
+			if has, known := opts.Scanner.lookup(fullPath); known {
+				if !has {
+					continue
+				}
+			} else {
+				item.Pending = true
 			}
 		}
 
-		item := NewItem(fullPath, isDir, depth)
+		// A symlink back to an ancestor would expand forever
+		if isDir && entry.Type()&os.ModeSymlink != 0 {
+			if target, err := filepath.EvalSymlinks(fullPath); err == nil && fsutil.IsWithin(realDir, target) {
+				item.Cycle = true
+			}
+		}
+
+		loadMetadata(item, entry, opts)
 		items = append(items, item)
 	}
 
-	// Sort: directories first, then alphabetically
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].IsDir != items[j].IsDir {
-			return items[i].IsDir // dirs come first
-		}
-		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
-	})
+	// Sort: directories first, then by the selected mode
+	sortItems(items, opts.Sort, opts.SortReverse)
 
 	return items, nil
 }
 
+// scanLevel scans a single directory level, checking subdirectories for
+// markdown in parallel on the scanner's worker pool
+func scanLevel(ctx context.Context, dirPath string, depth int, opts ScanOptions) ([]*Item, error) {
+	items, err := listLevel(dirPath, depth, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make([]bool, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if !item.Pending {
+			keep[i] = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			if !opts.Scanner.acquire(ctx) {
+				return
+			}
+			defer opts.Scanner.release()
+			keep[i], _ = containsMarkdown(ctx, path, opts)
+		}(i, item.Path)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	kept := items[:0]
+	for i, item := range items {
+		if keep[i] {
+			item.Pending = false
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
 // ScanChildren loads children for a directory item
 func ScanChildren(item *Item, opts ScanOptions) error {
-	if !item.IsDir {
+	return scanChildren(opts.context(), item, opts)
+}
+
+// scanChildren is ScanChildren with an explicit cancellation context
+func scanChildren(ctx context.Context, item *Item, opts ScanOptions) error {
+	if !item.IsDir || item.Cycle {
 		return nil
 	}
 
@@ -154,91 +234,77 @@ func ScanChildren(item *Item, opts ScanOptions) error {
 		return nil
 	}
 
-	children, err := scanLevel(item.Path, item.Depth+1, opts)
+	children, err := scanLevel(ctx, item.Path, item.Depth+1, opts)
 	if err != nil {
 		return err
 	}
 
-	// Set parent reference
-	for _, child := range children {
-		child.Parent = item
-	}
-
-	item.Children = children
+	item.SetChildren(children)
 	return nil
 }
 
 // isMarkdownFile checks if a filename is a markdown file
 func isMarkdownFile(name string) bool {
-	ext := strings.ToLower(filepath.Ext(name))
-	return ext == ".md" || ext == ".markdown"
+	return format.IsMarkdown(name)
 }
 
-// dirContainsMarkdown recursively checks if a directory contains markdown files
-func dirContainsMarkdown(dirPath string, opts ScanOptions) (bool, error) {
+// containsMarkdown reports whether a directory tree contains document files,
+// consulting and filling the scanner's shared index
+func containsMarkdown(ctx context.Context, dirPath string, opts ScanOptions) (bool, error) {
+	return dirContainsMarkdown(ctx, dirPath, opts, make(map[string]bool))
+}
+
+// dirContainsMarkdown recursively checks if a directory contains document files.
+// visited holds resolved paths already walked, so symlink cycles terminate.
+func dirContainsMarkdown(ctx context.Context, dirPath string, opts ScanOptions, visited map[string]bool) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if has, known := opts.Scanner.lookup(dirPath); known {
+		return has, nil
+	}
+
+	real, err := filepath.EvalSymlinks(dirPath)
+	if err != nil {
+		return false, err
+	}
+	if visited[real] {
+		return false, nil
+	}
+	visited[real] = true
+
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return false, err
 	}
 
+	found := false
 	for _, entry := range entries {
 		name := entry.Name()
+		fullPath := filepath.Join(dirPath, name)
+		isDir := entryIsDir(fullPath, entry)
 
-		// Skip hidden
-		if !opts.ShowHidden && strings.HasPrefix(name, ".") {
+		// Skip hidden and ignored entries unless shown
+		if opts.Skip(fullPath, isDir) {
 			continue
 		}
 
-		if entry.IsDir() {
-			// Skip ignored directories unless ShowIgnored is true
-			if !opts.ShowIgnored && isIgnoredDir(name, opts.IgnoreDirs) {
-				continue
-			}
-
+		if isDir {
 			// Recursively check subdirectories
-			fullPath := filepath.Join(dirPath, name)
-			has, _ := dirContainsMarkdown(fullPath, opts)
+			has, err := dirContainsMarkdown(ctx, fullPath, opts, visited)
+			if err != nil && ctx.Err() != nil {
+				return false, err
+			}
 			if has {
-				return true, nil
+				found = true
+				break
 			}
-		} else if isMarkdownFile(name) {
-			return true, nil
+		} else if opts.IsDocument(name) {
+			found = true
+			break
 		}
 	}
 
-	return false, nil
-}
-
-// CountMarkdownFiles counts total markdown files in a directory tree
-func CountMarkdownFiles(rootPath string, opts ScanOptions) (int, error) {
-	count := 0
-
-	err := filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // Skip errors, continue walking
-		}
-
-		name := d.Name()
-
-		// Skip hidden
-		if !opts.ShowHidden && strings.HasPrefix(name, ".") {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip ignored directories unless ShowIgnored is true
-		if d.IsDir() && !opts.ShowIgnored && isIgnoredDir(name, opts.IgnoreDirs) {
-			return filepath.SkipDir
-		}
-
-		if !d.IsDir() && isMarkdownFile(name) {
-			count++
-		}
-
-		return nil
-	})
-
-	return count, err
+	opts.Scanner.store(dirPath, found)
+	return found, nil
 }
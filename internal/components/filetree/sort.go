@@ -0,0 +1,285 @@
+package filetree
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+)
+
+// SortMode selects how entries are ordered within each directory
+type SortMode int
+
+const (
+	// SortName orders case-insensitively by name
+	SortName SortMode = iota
+
+	// SortNatural orders by name with embedded numbers compared numerically (2 < 10)
+	SortNatural
+
+	// SortModified orders newest first
+	SortModified
+
+	// SortSize orders largest first
+	SortSize
+
+	// SortTitle orders by front matter title, falling back to the file name
+	SortTitle
+
+	// SortDate orders by front matter date, newest first
+	SortDate
+
+	// SortWeight orders by front matter weight, lightest first
+	SortWeight
+
+	sortModeCount
+)
+
+// String returns the display name of the sort mode
+func (s SortMode) String() string {
+	switch s {
+	case SortNatural:
+		return "natural"
+	case SortModified:
+		return "modified"
+	case SortSize:
+		return "size"
+	case SortTitle:
+		return "title"
+	case SortDate:
+		return "date"
+	case SortWeight:
+		return "weight"
+	default:
+		return "name"
+	}
+}
+
+// Next returns the following sort mode, wrapping around
+func (s SortMode) Next() SortMode {
+	return (s + 1) % sortModeCount
+}
+
+// usesFrontMatter reports whether the mode needs file headers to be read
+func (s SortMode) usesFrontMatter() bool {
+	return s == SortTitle || s == SortDate || s == SortWeight
+}
+
+// loadMetadata fills in stat and optional content metadata for a scanned item
+func loadMetadata(item *Item, entry os.DirEntry, opts ScanOptions) {
+	if info, err := entry.Info(); err == nil {
+		item.ModTime = info.ModTime()
+		if !item.IsDir {
+			item.Size = info.Size()
+		}
+	}
+
+	if item.IsDir || !isMarkdownFile(item.Name) {
+		return
+	}
+
+	if opts.Sort.usesFrontMatter() {
+		item.Matter, _ = frontmatter.ReadFile(item.Path)
+		item.matterRead = true
+	}
+	if opts.CountWords {
+		item.Words = countWords(item.Path)
+	}
+}
+
+// countWords counts whitespace-separated words in a file, or -1 on error
+func countWords(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	return len(strings.Fields(string(content)))
+}
+
+// metadataLoadedMsg is sent when the background metadata pass finishes
+type metadataLoadedMsg struct {
+	matter map[string]*frontmatter.Matter
+	words  map[string]int
+}
+
+// metadataPass reads the front matter and word counts that the sort mode and
+// columns need but loaded documents don't have yet
+func (m Model) metadataPass() tea.Cmd {
+	wantMatter := m.scanOptions.Sort.usesFrontMatter()
+	wantWords := m.scanOptions.CountWords
+
+	var matterPaths, wordPaths []string
+	walkItems(m.items, func(item *Item) {
+		if item.IsDir || !isMarkdownFile(item.Name) {
+			return
+		}
+		if wantMatter && !item.matterRead {
+			matterPaths = append(matterPaths, item.Path)
+		}
+		if wantWords && item.Words < 0 {
+			wordPaths = append(wordPaths, item.Path)
+		}
+	})
+	if len(matterPaths) == 0 && len(wordPaths) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg := metadataLoadedMsg{
+			matter: make(map[string]*frontmatter.Matter, len(matterPaths)),
+			words:  make(map[string]int, len(wordPaths)),
+		}
+		for _, path := range matterPaths {
+			msg.matter[path], _ = frontmatter.ReadFile(path)
+		}
+		for _, path := range wordPaths {
+			msg.words[path] = countWords(path)
+		}
+		return msg
+	}
+}
+
+// handleMetadataLoaded copies read metadata onto items and re-sorts the tree
+func (m Model) handleMetadataLoaded(msg metadataLoadedMsg) (Model, tea.Cmd) {
+	walkItems(m.items, func(item *Item) {
+		if matter, ok := msg.matter[item.Path]; ok {
+			item.Matter = matter
+			item.matterRead = true
+		}
+		if words, ok := msg.words[item.Path]; ok {
+			item.Words = words
+		}
+	})
+	m.resort()
+	return m, nil
+}
+
+// resort orders every loaded level by the current sort mode
+func (m *Model) resort() {
+	sortItems(m.items, m.scanOptions.Sort, m.scanOptions.SortReverse)
+	walkItems(m.items, func(item *Item) {
+		if item.IsDir {
+			sortItems(item.Children, m.scanOptions.Sort, m.scanOptions.SortReverse)
+		}
+	})
+	m.rebuildList()
+}
+
+// sortItems orders a directory level: directories first, then by the selected mode
+func sortItems(items []*Item, mode SortMode, reverse bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir // dirs come first
+		}
+
+		if c := compareItems(a, b, mode); c != 0 {
+			if reverse {
+				return c > 0
+			}
+			return c < 0
+		}
+
+		// Ties fall back to natural name order
+		return naturalLess(a.Name, b.Name)
+	})
+}
+
+// compareItems compares two items of the same kind under a sort mode
+func compareItems(a, b *Item, mode SortMode) int {
+	switch mode {
+	case SortNatural:
+		return boolCompare(naturalLess(a.Name, b.Name), naturalLess(b.Name, a.Name))
+
+	case SortModified:
+		return boolCompare(a.ModTime.After(b.ModTime), b.ModTime.After(a.ModTime))
+
+	case SortSize:
+		return boolCompare(a.Size > b.Size, b.Size > a.Size)
+
+	case SortTitle:
+		ta, tb := strings.ToLower(a.sortTitle()), strings.ToLower(b.sortTitle())
+		return strings.Compare(ta, tb)
+
+	case SortDate:
+		da, db := a.Matter.Date(), b.Matter.Date()
+		// Undated documents go last
+		if da.IsZero() != db.IsZero() {
+			return boolCompare(!da.IsZero(), !db.IsZero())
+		}
+		return boolCompare(da.After(db), db.After(da))
+
+	case SortWeight:
+		wa, okA := a.Matter.Weight()
+		wb, okB := b.Matter.Weight()
+		// Unweighted documents go last
+		if okA != okB {
+			return boolCompare(okA, okB)
+		}
+		return boolCompare(wa < wb, wb < wa)
+	}
+
+	return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+}
+
+// boolCompare turns a pair of "less" results into -1, 0 or 1
+func boolCompare(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// naturalLess compares names case-insensitively, treating digit runs as numbers
+// so that "2-setup.md" sorts before "10-deploy.md"
+func naturalLess(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		ca, cb := chunk(a), chunk(b)
+		a, b = a[len(ca):], b[len(cb):]
+		if ca == cb {
+			continue
+		}
+
+		if isDigits(ca) && isDigits(cb) {
+			na, nb := strings.TrimLeft(ca, "0"), strings.TrimLeft(cb, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			// Same value, fewer leading zeros first
+			return len(ca) < len(cb)
+		}
+		return ca < cb
+	}
+	return len(a) < len(b)
+}
+
+// chunk returns the leading run of digits or non-digits
+func chunk(s string) string {
+	digit := isDigit(s[0])
+	for i := 1; i < len(s); i++ {
+		if isDigit(s[i]) != digit {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	return s != "" && isDigit(s[0])
+}
+
+// isDigit reports whether b is an ASCII digit
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
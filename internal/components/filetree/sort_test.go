@@ -0,0 +1,127 @@
+package filetree
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-setup.md", "10-deploy.md", true},
+		{"10-deploy.md", "2-setup.md", false},
+		{"file9.md", "file10.md", true},
+		{"v1.2.md", "v1.10.md", true},
+		{"chapter 3", "chapter 3b", true},
+		{"007.md", "7.md", false},
+		{"7.md", "007.md", true},
+		{"08.md", "8.md", false},
+		{"99999999999999999999.md", "100000000000000000000.md", true},
+		{"Alpha.md", "beta.md", true},
+		{"README.md", "readme.md", false},
+		{"readme.md", "README.md", false},
+		{"abc", "abc", false},
+		{"abc", "abcd", true},
+		{"1", "a", true},
+		{"", "a", true},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	dir := func(name string) *Item { return NewItem("/"+name, true, 0) }
+	file := func(name string, size int64, modified int) *Item {
+		item := NewItem("/"+name, false, 0)
+		item.Size = size
+		item.ModTime = time.Unix(int64(modified), 0)
+		return item
+	}
+	weighted := func(name, weight string) *Item {
+		item := NewItem("/"+name, false, 0)
+		if weight != "" {
+			item.Matter = frontmatter.Parse("---\nweight: " + weight + "\n---\n")
+		}
+		return item
+	}
+
+	tests := []struct {
+		name    string
+		items   []*Item
+		mode    SortMode
+		reverse bool
+		want    []string
+	}{
+		{
+			name:  "name, directories first",
+			items: []*Item{file("b.md", 0, 0), dir("zdir"), file("A.md", 0, 0), dir("adir")},
+			mode:  SortName,
+			want:  []string{"adir", "zdir", "A.md", "b.md"},
+		},
+		{
+			name:  "name compares digits as text",
+			items: []*Item{file("10.md", 0, 0), file("2.md", 0, 0), file("1.md", 0, 0)},
+			mode:  SortName,
+			want:  []string{"1.md", "10.md", "2.md"},
+		},
+		{
+			name:  "natural",
+			items: []*Item{file("10.md", 0, 0), file("2.md", 0, 0), file("1.md", 0, 0)},
+			mode:  SortNatural,
+			want:  []string{"1.md", "2.md", "10.md"},
+		},
+		{
+			name:    "natural reversed keeps directories first",
+			items:   []*Item{file("1.md", 0, 0), dir("a"), file("2.md", 0, 0), dir("b")},
+			mode:    SortNatural,
+			reverse: true,
+			want:    []string{"b", "a", "2.md", "1.md"},
+		},
+		{
+			name:  "modified newest first",
+			items: []*Item{file("old.md", 0, 1), file("new.md", 0, 3), file("mid.md", 0, 2)},
+			mode:  SortModified,
+			want:  []string{"new.md", "mid.md", "old.md"},
+		},
+		{
+			name:  "size ties fall back to natural names",
+			items: []*Item{file("x10.md", 5, 0), file("big.md", 9, 0), file("x2.md", 5, 0)},
+			mode:  SortSize,
+			want:  []string{"big.md", "x2.md", "x10.md"},
+		},
+		{
+			name:    "reversed ties stay in natural order",
+			items:   []*Item{file("x10.md", 5, 0), file("small.md", 1, 0), file("x2.md", 5, 0)},
+			mode:    SortSize,
+			reverse: true,
+			want:    []string{"small.md", "x2.md", "x10.md"},
+		},
+		{
+			name:  "weight, unweighted last",
+			items: []*Item{weighted("none.md", ""), weighted("heavy.md", "20"), weighted("light.md", "-1")},
+			mode:  SortWeight,
+			want:  []string{"light.md", "heavy.md", "none.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortItems(tt.items, tt.mode, tt.reverse)
+			var got []string
+			for _, item := range tt.items {
+				got = append(got, item.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package filetree
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxTitleLines bounds how far into a file the title pass looks for a heading
+const maxTitleLines = 60
+
+// titlesLoadedMsg is sent when the background title pass finishes
+type titlesLoadedMsg struct {
+	titles map[string]string
+}
+
+// loadTitles reads document titles for the given files in the background
+func loadTitles(paths []string) tea.Cmd {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		titles := make(map[string]string, len(paths))
+		for _, path := range paths {
+			titles[path] = documentTitle(path)
+		}
+		return titlesLoadedMsg{titles: titles}
+	}
+}
+
+// documentTitle returns the front matter title or the first "# Heading" of a
+// markdown file, reading only the first lines. Returns "" if there is neither.
+func documentTitle(path string) string {
+	matter, _ := frontmatter.ReadFile(path)
+	if matter.Title() != "" {
+		return matter.Title()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// Skip over a YAML or TOML front matter block without a title
+	skip := 0
+	if matter != nil {
+		skip = matter.Lines
+	}
+
+	scanner := bufio.NewScanner(f)
+	var fence mdlinks.Fence
+	for n := 0; scanner.Scan() && n < skip+maxTitleLines; n++ {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if n < skip || fence.Scan(line) || fence.Open() {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimRight(line[2:], "#"))
+		}
+	}
+	return ""
+}
+
+// toggleTitles switches between file names and document titles
+func (m Model) toggleTitles() (Model, tea.Cmd) {
+	m.showTitles = !m.showTitles
+	m.delegate.ShowTitles = m.showTitles
+	m.list.SetDelegate(m.delegate)
+	m.applyTitles()
+	m.rebuildList()
+
+	if !m.showTitles {
+		return m, m.viewChanged("showing file names")
+	}
+	return m, tea.Batch(m.viewChanged("showing document titles"), m.titlePass())
+}
+
+// titlePass starts a background title read for every loaded markdown file
+func (m Model) titlePass() tea.Cmd {
+	if !m.showTitles {
+		return nil
+	}
+
+	var paths []string
+	walkItems(m.items, func(item *Item) {
+		if !item.IsDir && isMarkdownFile(item.Name) {
+			paths = append(paths, item.Path)
+		}
+	})
+	return loadTitles(paths)
+}
+
+// RefreshTitle re-reads the title of a single file (e.g. after it was edited)
+func (m Model) RefreshTitle(path string) tea.Cmd {
+	if !m.showTitles || !isMarkdownFile(path) {
+		return nil
+	}
+	return loadTitles([]string{path})
+}
+
+// applyTitles copies cached titles onto items, or clears them when titles are hidden
+func (m *Model) applyTitles() {
+	walkItems(m.items, func(item *Item) {
+		if m.showTitles {
+			item.DocTitle = m.titles[item.Path]
+		} else {
+			item.DocTitle = ""
+		}
+	})
+}
+
+// walkItems visits every loaded item depth-first
+func walkItems(items []*Item, fn func(*Item)) {
+	for _, item := range items {
+		fn(item)
+		walkItems(item.Children, fn)
+	}
+}
@@ -0,0 +1,35 @@
+package filetree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocumentTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"heading", "intro\n# Getting Started #\n", "Getting Started"},
+		{"yaml title", "---\ntitle: From Matter\n---\n# Heading\n", "From Matter"},
+		{"yaml without title", "---\nauthor: Ann\n# not a heading\n---\n# Heading\n", "Heading"},
+		{"toml title", "+++\ntitle = \"Toml\"\n+++\n# Heading\n", "Toml"},
+		{"toml without title", "+++\n# build settings\ndraft = true\n+++\n# Real Title\n", "Real Title"},
+		{"fenced heading", "```sh\n# comment\n```\n# After Code\n", "After Code"},
+		{"second level only", "## Sub\ntext\n", ""},
+		{"unclosed matter is body", "---\n# Heading\n", "Heading"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "doc.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := documentTitle(path); got != tt.want {
+				t.Errorf("documentTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
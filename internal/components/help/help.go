@@ -89,8 +89,33 @@ func (m Model) View() string {
 				{Key: "↑ / k", Desc: "Move up"},
 				{Key: "↓ / j", Desc: "Move down"},
 				{Key: "Enter", Desc: "Open file / Toggle folder"},
+				{Key: "h / l", Desc: "Collapse or go to parent / Expand or open"},
+				{Key: "E / W", Desc: "Expand recursively / Collapse all"},
+				{Key: "1-9", Desc: "Expand to depth"},
+				{Key: ".", Desc: "Reveal previewed file in tree"},
 				{Key: "Tab", Desc: "Switch panel focus"},
-				{Key: "i", Desc: "Toggle ignored directories"},
+				{Key: "i", Desc: "Toggle ignored files (.gitignore, .skimignore)"},
+				{Key: "F", Desc: "Toggle all files (source code and others)"},
+			},
+		},
+		{
+			Title: "File Management",
+			Bindings: []KeyBinding{
+				{Key: "a / A", Desc: "New file / directory"},
+				{Key: "r", Desc: "Rename or move"},
+				{Key: "C", Desc: "Duplicate"},
+				{Key: "d", Desc: "Delete (to trash)"},
+				{Key: "u", Desc: "Undo last action"},
+				{Key: "Tab", Desc: "Complete path in prompt"},
+			},
+		},
+		{
+			Title: "Tree Display",
+			Bindings: []KeyBinding{
+				{Key: "s / S", Desc: "Cycle sort order / Reverse"},
+				{Key: "m", Desc: "Cycle metadata columns"},
+				{Key: "T", Desc: "Show document titles"},
+				{Key: "#", Desc: "Browse tags / Filter tree by tag"},
 			},
 		},
 		{
@@ -100,6 +125,34 @@ func (m Model) View() string {
 				{Key: "PgDn / Ctrl+d", Desc: "Scroll down half page"},
 				{Key: "g", Desc: "Go to top"},
 				{Key: "G", Desc: "Go to bottom"},
+				{Key: "m", Desc: "Collapse / Expand front matter"},
+				{Key: "r", Desc: "Toggle raw source view"},
+				{Key: "v", Desc: "Toggle source / rendered split view"},
+				{Key: "d", Desc: "Toggle diagram source / drawing"},
+				{Key: "h / l", Desc: "Scroll source / rendered pane (split view)"},
+				{Key: "h / l", Desc: "Scroll wide code blocks and tables sideways"},
+				{Key: "b", Desc: "Backlinks to this document"},
+				{Key: "L", Desc: "Check links (broken links panel)"},
+				{Key: "D", Desc: "Lint findings for this document"},
+			},
+		},
+		{
+			Title: "Layout",
+			Bindings: []KeyBinding{
+				{Key: "< / >", Desc: "Shrink / Grow file tree"},
+				{Key: "\\", Desc: "Hide / Show file tree (Tab pops it up)"},
+				{Key: "|", Desc: "Cycle layout (auto, side, stacked)"},
+				{Key: "w", Desc: "Cycle wide blocks (wrap, scroll blocks, scroll document)"},
+				{Key: "f", Desc: "Fullscreen preview"},
+			},
+		},
+		{
+			Title: "Mouse",
+			Bindings: []KeyBinding{
+				{Key: "Click", Desc: "Select / Open file / Toggle folder arrow"},
+				{Key: "Double-click", Desc: "Toggle folder"},
+				{Key: "Click link", Desc: "Follow link in preview"},
+				{Key: "Drag border", Desc: "Resize panels"},
 			},
 		},
 		{
@@ -119,10 +172,32 @@ func (m Model) View() string {
 				{Key: "Esc", Desc: "Clear search"},
 			},
 		},
+		{
+			Title: "Data Files",
+			Bindings: []KeyBinding{
+				{Key: "s / S", Desc: "Sort table by next column / Reverse"},
+				{Key: "h / l", Desc: "Scroll table columns"},
+				{Key: "Click header", Desc: "Sort by that column"},
+				{Key: "Enter", Desc: "Fold / Unfold first tree node in view"},
+				{Key: "1-9 / 0", Desc: "Fold tree to depth / Unfold all"},
+				{Key: "Click node", Desc: "Fold / Unfold tree node"},
+			},
+		},
+		{
+			Title: "Task Lists",
+			Bindings: []KeyBinding{
+				{Key: "t", Desc: "Select tasks (preview)"},
+				{Key: "↑↓ / j k", Desc: "Move between tasks"},
+				{Key: "Space", Desc: "Toggle checkbox in file"},
+				{Key: "t / Esc", Desc: "Leave task selection"},
+			},
+		},
 		{
 			Title: "View",
 			Bindings: []KeyBinding{
 				{Key: "f", Desc: "Toggle fullscreen preview"},
+				{Key: "Z", Desc: "Zen fullscreen (hides the status bar)"},
+				{Key: "z", Desc: "Reading mode (centered text column)"},
 				{Key: "Esc", Desc: "Exit fullscreen"},
 			},
 		},
@@ -0,0 +1,311 @@
+// Package picker provides a popup list panel with type-to-filter, used for
+// browsing tags, backlinks, link problems and other document-wide results
+package picker
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Item is a single entry in the picker
+type Item struct {
+	// Label is the main text
+	Label string
+
+	// Detail is shown dimmed after the label (counts, paths, ...)
+	Detail string
+
+	// Value identifies the item to whoever opened the picker
+	Value string
+
+	// Path and Line locate the item in a file, when it refers to one
+	Path string
+	Line int
+}
+
+// SelectedMsg is sent when an item is chosen
+type SelectedMsg struct {
+	// ID is the identifier the picker was opened with
+	ID   string
+	Item Item
+}
+
+// ClosedMsg is sent when the picker is dismissed without a choice
+type ClosedMsg struct {
+	ID string
+}
+
+// Model is the picker component model
+type Model struct {
+	id      string
+	title   string
+	items   []Item
+	visible []Item
+	cursor  int
+	offset  int
+	filter  textinput.Model
+	open    bool
+	empty   string
+	width   int
+	height  int
+}
+
+// New creates a closed picker
+func New() Model {
+	ti := textinput.New()
+	ti.Placeholder = "type to filter..."
+	ti.Prompt = "/"
+	ti.PromptStyle = styles.FilterPromptStyle
+	ti.TextStyle = styles.FilterInputStyle
+	ti.Cursor.Style = styles.FilterCursorStyle
+	ti.CharLimit = 100
+
+	return Model{filter: ti}
+}
+
+// Open shows the picker with a set of items. id is echoed in SelectedMsg and
+// ClosedMsg; empty is shown when there are no items.
+func (m *Model) Open(id, title string, items []Item, empty string) tea.Cmd {
+	m.id = id
+	m.title = title
+	m.items = items
+	m.empty = empty
+	m.open = true
+	m.filter.SetValue("")
+	m.applyFilter()
+	return m.filter.Focus()
+}
+
+// Close hides the picker
+func (m *Model) Close() {
+	m.open = false
+	m.filter.Blur()
+}
+
+// IsOpen returns whether the picker is visible
+func (m Model) IsOpen() bool {
+	return m.open
+}
+
+// ID returns the identifier the picker was last opened with
+func (m Model) ID() string {
+	return m.id
+}
+
+// SetSize sets the area the picker is centered in
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.filter.Width = m.boxWidth() - 4
+}
+
+// Update handles keys while the picker is open
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.open {
+		return m, nil
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.filter, cmd = m.filter.Update(msg)
+		return m, cmd
+	}
+
+	switch key.String() {
+	case "esc":
+		m.Close()
+		id := m.id
+		return m, func() tea.Msg { return ClosedMsg{ID: id} }
+
+	case "enter":
+		if len(m.visible) == 0 {
+			return m, nil
+		}
+		m.Close()
+		selected := SelectedMsg{ID: m.id, Item: m.visible[m.cursor]}
+		return m, func() tea.Msg { return selected }
+
+	case "up", "ctrl+p", "ctrl+k":
+		m.move(-1)
+		return m, nil
+
+	case "down", "ctrl+n", "ctrl+j":
+		m.move(1)
+		return m, nil
+
+	case "pgup":
+		m.move(-m.listHeight())
+		return m, nil
+
+	case "pgdown":
+		m.move(m.listHeight())
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.applyFilter()
+	return m, cmd
+}
+
+// move moves the cursor, keeping it in view
+func (m *Model) move(delta int) {
+	if len(m.visible) == 0 {
+		return
+	}
+	m.cursor += delta
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if h := m.listHeight(); m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+// applyFilter narrows items to those containing every word of the filter
+func (m *Model) applyFilter() {
+	words := strings.Fields(strings.ToLower(m.filter.Value()))
+
+	m.visible = m.visible[:0]
+	for _, item := range m.items {
+		text := strings.ToLower(item.Label + " " + item.Detail)
+		matches := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			m.visible = append(m.visible, item)
+		}
+	}
+
+	m.cursor = 0
+	m.offset = 0
+}
+
+// boxWidth is the inner width of the popup
+func (m Model) boxWidth() int {
+	w := m.width * 2 / 3
+	if w > 80 {
+		w = 80
+	}
+	if w < 30 {
+		w = 30
+	}
+	return w
+}
+
+// listHeight is the number of item rows shown
+func (m Model) listHeight() int {
+	// Border, padding, title, filter, blank line and footer
+	h := m.height*2/3 - 8
+	if h < 3 {
+		h = 3
+	}
+	return h
+}
+
+// View renders the popup box (without positioning)
+func (m Model) View() string {
+	if !m.open {
+		return ""
+	}
+
+	width := m.boxWidth()
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Foreground(styles.Highlight).Bold(true).Render(m.title))
+	b.WriteString("\n")
+	b.WriteString(m.filter.View())
+	b.WriteString("\n\n")
+
+	rows := m.listHeight()
+	if len(m.visible) == 0 {
+		message := m.empty
+		if len(m.items) > 0 {
+			message = "No matches"
+		}
+		b.WriteString(styles.EmptyStateHintStyle.Render(message))
+		rows--
+	}
+
+	end := m.offset + rows
+	if end > len(m.visible) {
+		end = len(m.visible)
+	}
+	for i := m.offset; i < end; i++ {
+		b.WriteString(m.renderItem(m.visible[i], i == m.cursor, width))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	footer := "↑↓ select · ⏎ open · Esc close"
+	if len(m.visible) > rows {
+		footer = itoa(m.cursor+1) + "/" + itoa(len(m.visible)) + " · " + footer
+	}
+	b.WriteString("\n\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(styles.Subtle).Italic(true).Render(footer))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Accent).
+		Padding(0, 1).
+		Width(width).
+		Render(b.String())
+}
+
+// renderItem renders one row: label, then the detail dimmed and right-aligned
+func (m Model) renderItem(item Item, selected bool, width int) string {
+	label := item.Label
+	detail := item.Detail
+
+	// Leave room for the selection marker
+	width -= 4
+	if detail != "" && ansi.StringWidth(label)+ansi.StringWidth(detail)+2 > width {
+		detail = ansi.TruncateLeft(detail, ansi.StringWidth(detail)-(width/3), "…")
+	}
+	label = ansi.Truncate(label, width-ansi.StringWidth(detail)-2, "…")
+	gap := width - ansi.StringWidth(label) - ansi.StringWidth(detail)
+	if gap < 1 {
+		gap = 1
+	}
+
+	if selected {
+		return styles.TreeIndicatorStyle.Render(styles.TaskMarker+" ") +
+			styles.SelectedItemStyle.Render(label) + strings.Repeat(" ", gap) +
+			styles.TreeSecondaryStyle.Render(detail)
+	}
+	return "  " + styles.FileStyle.Render(label) + strings.Repeat(" ", gap) + styles.TreeSecondaryStyle.Render(detail)
+}
+
+// itoa converts int to string without importing strconv
+func itoa(i int) string {
+	if i == 0 {
+		return "0"
+	}
+	if i < 0 {
+		return "-" + itoa(-i)
+	}
+	var digits []byte
+	for i > 0 {
+		digits = append([]byte{byte('0' + i%10)}, digits...)
+		i /= 10
+	}
+	return string(digits)
+}
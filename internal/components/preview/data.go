@@ -0,0 +1,168 @@
+package preview
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// isData reports whether the file is a table or data tree format
+func (m Model) isData() bool {
+	return m.docFormat.Delimiter != 0 || m.docFormat.Tree != nil
+}
+
+// showsData reports whether the table or tree view is shown, rather than
+// the source
+func (m Model) showsData() bool {
+	return m.isData() && !m.rawMode && m.dataErr == nil
+}
+
+// loadData parses a table or data tree file. A reload keeps the sort
+// order, horizontal scroll and folded nodes.
+func (m *Model) loadData(reload bool) {
+	m.table, m.tree, m.dataErr = nil, nil, nil
+	if !reload {
+		m.sortColumn, m.sortDesc, m.xOffset = -1, false, 0
+		m.folded = nil
+	}
+
+	switch {
+	case m.docFormat.Delimiter != 0:
+		m.table, m.dataErr = format.ParseTable(m.rawContent, m.docFormat.Delimiter)
+		if m.table != nil && m.sortColumn >= len(m.table.Header) {
+			m.sortColumn, m.sortDesc = -1, false
+		}
+	case m.docFormat.Tree != nil:
+		m.tree, m.dataErr = m.docFormat.Tree(m.rawContent)
+	}
+}
+
+// renderData renders a table or data tree. Files that can't be parsed are
+// shown as highlighted source below the error.
+func (m *Model) renderData() (string, []int) {
+	switch {
+	case m.dataErr != nil:
+		content, rows := renderSource(highlightLines(m.rawContent, m.docFormat.Lexer), m.contentWidth())
+		notice := ansi.Truncate("Can't read "+m.docFormat.Name+": "+m.dataErr.Error(), m.contentWidth()-4, "…")
+		return "\n  " + styles.DataErrorStyle.Render(notice) + "\n\n" + content, shiftRows(rows, 3)
+	case m.table != nil:
+		return m.renderTable()
+	}
+	return m.renderTree()
+}
+
+// rerenderData renders the table or tree again after sorting or folding,
+// keeping the scroll position
+func (m *Model) rerenderData() {
+	offset := m.viewport.YOffset
+	if err := m.render(); err != nil {
+		m.err = err
+		m.viewport.SetContent(m.renderError(err))
+		return
+	}
+	m.refreshContent()
+	m.viewport.SetYOffset(offset)
+}
+
+// handleDataKey handles the table keys (sorting and scrolling columns) and
+// the tree keys (folding). Returns false for other keys.
+func (m *Model) handleDataKey(msg tea.KeyMsg) bool {
+	if !m.showsData() {
+		return false
+	}
+
+	key := msg.String()
+	if m.table != nil {
+		switch key {
+		case "s":
+			m.cycleSort()
+		case "S":
+			m.reverseSort()
+		case "h", "left":
+			m.scrollColumns(-1)
+		case "l", "right":
+			m.scrollColumns(1)
+		default:
+			return false
+		}
+		return true
+	}
+
+	switch {
+	case key == "enter" || key == " ":
+		m.foldAtTop()
+	case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+		m.foldToDepth(int(key[0] - '0'))
+	default:
+		return false
+	}
+	return true
+}
+
+// handleDataClick sorts by the table column whose header is clicked, or
+// folds the tree node clicked. y is the row within the preview. Returns
+// false when the click is for something else.
+func (m *Model) handleDataClick(x, y int) bool {
+	if !m.showsData() {
+		return false
+	}
+	if m.table != nil {
+		if y >= m.headerHeight() {
+			return false
+		}
+		if column := m.columnAt(x); column >= 0 {
+			m.sortByColumn(column)
+		}
+		return true
+	}
+
+	if path := m.treeRow(m.viewport.YOffset + y); path != "" {
+		m.toggleFold(path)
+		return true
+	}
+	return false
+}
+
+// headerHeight returns the number of rows the sticky table header takes
+func (m Model) headerHeight() int {
+	if m.tableHeader == "" {
+		return 0
+	}
+	return strings.Count(m.tableHeader, "\n") + 1
+}
+
+// applyHeight sizes the viewport below the sticky table header
+func (m *Model) applyHeight() {
+	m.viewport.Height = max(1, m.height-m.headerHeight())
+}
+
+// viewTableHeader renders the sticky header scrolled with the table
+func (m Model) viewTableHeader() string {
+	header := m.tableHeader
+	if m.searchQuery != "" {
+		header = highlightMatches(header, m.searchQuery)
+	}
+	return cutColumns(header, m.xOffset, m.contentWidth())
+}
+
+// IsTable returns whether a CSV or TSV file is shown as a table
+func (m Model) IsTable() bool {
+	return m.showsData() && m.table != nil
+}
+
+// IsTree returns whether a JSON or YAML file is shown as a tree
+func (m Model) IsTree() bool {
+	return m.showsData() && m.tree != nil
+}
+
+// SortColumn returns the name of the column the table is sorted by and
+// whether the order is descending; ok is false when the table is in file order
+func (m Model) SortColumn() (name string, desc, ok bool) {
+	if !m.IsTable() || m.sortColumn < 0 || m.sortColumn >= len(m.table.Header) {
+		return "", false, false
+	}
+	return m.table.Header[m.sortColumn], m.sortDesc, true
+}
@@ -0,0 +1,167 @@
+package preview
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/diagram"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+)
+
+// diagramBlock is a fenced diagram block taken out of the markdown
+type diagramBlock struct {
+	lang   string
+	source string
+}
+
+// diagramMarker stands in for a diagram block while the markdown is
+// rendered; the drawing takes the marker's line afterwards
+const diagramMarker = "SKIMDIAGRAM"
+
+// diagramPattern matches a marker and the index of its diagram
+var diagramPattern = regexp.MustCompile(diagramMarker + `(\d+)`)
+
+// markDiagrams replaces each fenced block in a diagram language with a
+// marker, unless the source of diagrams is shown. It counts the diagrams
+// either way and returns the markdown and the blocks in marker order.
+func (m *Model) markDiagrams(markdown string) (string, []diagramBlock) {
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	var blocks []diagramBlock
+
+	for i := 0; i < len(lines); i++ {
+		indent, fence, lang, ok := mdlinks.OpenFence(lines[i])
+		if !ok {
+			out = append(out, lines[i])
+			continue
+		}
+
+		end := closingFence(lines, i, fence)
+		last := min(end, len(lines)-1)
+
+		if !diagram.Supported(lang) {
+			out = append(out, lines[i:last+1]...)
+			i = last
+			continue
+		}
+		m.diagramCount++
+		if m.diagramSource {
+			out = append(out, lines[i:last+1]...)
+			i = last
+			continue
+		}
+
+		body := lines[i+1 : max(end, i+1)]
+		for k, line := range body {
+			body[k] = strings.TrimPrefix(line, indent)
+		}
+		out = append(out, "", indent+diagramMarker+strconv.Itoa(len(blocks)), "")
+		blocks = append(blocks, diagramBlock{lang: lang, source: strings.Join(body, "\n")})
+		i = last
+	}
+	return strings.Join(out, "\n"), blocks
+}
+
+// placeDiagrams swaps the markers in the rendered markdown for the
+// drawings. A diagram that can't be drawn is shown as a notice saying why,
+// followed by its source.
+func (m Model) placeDiagrams(rendered string, blocks []diagramBlock) string {
+	return placeMarkers(rendered, diagramPattern, len(blocks), func(n, _ int, pad string) []string {
+		drawing, err := diagram.Render(blocks[n].lang, blocks[n].source)
+		var out []string
+		if err != nil {
+			out = append(out, pad+styles.DiagramNoticeStyle.Render("◇ "+diagramError(err)), "")
+			for _, row := range strings.Split(strings.TrimRight(blocks[n].source, "\n"), "\n") {
+				out = append(out, pad+"  "+styles.DiagramSourceStyle.Render(row))
+			}
+			return out
+		}
+		for _, row := range drawing {
+			out = append(out, pad+styles.DiagramStyle.Render(row))
+		}
+		return out
+	})
+}
+
+// closingFence returns the line that closes the fenced code block opened on
+// line i with fence. It returns len(lines) when the block runs to the end.
+func closingFence(lines []string, i int, fence string) int {
+	for j := i + 1; j < len(lines); j++ {
+		if mdlinks.ClosesFence(lines[j], fence) {
+			return j
+		}
+	}
+	return len(lines)
+}
+
+// placeMarkers replaces each marker line in the rendered markdown with the
+// rows place returns for it, starting at row and indented by pad. A marker
+// that ended up joined to other text, as in a list item, gets rows of its
+// own below that text.
+func placeMarkers(rendered string, pattern *regexp.Regexp, count int, place func(n, row int, pad string) []string) string {
+	if count == 0 {
+		return rendered
+	}
+
+	var out []string
+	for _, line := range strings.Split(rendered, "\n") {
+		loc := pattern.FindStringSubmatchIndex(line)
+		if loc == nil {
+			out = append(out, line)
+			continue
+		}
+		n, _ := strconv.Atoi(line[loc[2]:loc[3]])
+		if n >= count {
+			out = append(out, line)
+			continue
+		}
+
+		plain := stripANSI(line)
+		indent := len(plain) - len(strings.TrimLeft(plain, " "))
+		before, after := line[:loc[0]], line[loc[1]:]
+		if strings.TrimSpace(stripANSI(before)) != "" {
+			out = append(out, before)
+			indent += 2
+		}
+		pad := strings.Repeat(" ", indent)
+		out = append(out, place(n, len(out), pad)...)
+		if strings.TrimSpace(stripANSI(after)) != "" {
+			out = append(out, pad+strings.TrimLeft(after, " "))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// diagramError describes why a diagram wasn't drawn
+func diagramError(err error) string {
+	var unsupported *diagram.UnsupportedError
+	if errors.As(err, &unsupported) {
+		return err.Error()
+	}
+	return "can't draw the diagram: " + err.Error()
+}
+
+// toggleDiagrams switches between drawing the diagram blocks and showing
+// their source
+func (m *Model) toggleDiagrams() {
+	if m.diagramCount == 0 || m.rawMode {
+		return
+	}
+	m.diagramSource = !m.diagramSource
+	if err := m.render(); err == nil {
+		m.refreshContent()
+	}
+}
+
+// HasDiagrams returns whether the current document has diagram blocks
+func (m Model) HasDiagrams() bool {
+	return m.diagramCount > 0
+}
+
+// IsDiagramSource returns whether diagram blocks are shown as source
+func (m Model) IsDiagramSource() bool {
+	return m.diagramSource
+}
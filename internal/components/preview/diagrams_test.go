@@ -0,0 +1,75 @@
+package preview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagramFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []string
+	}{
+		{
+			name:     "drawn",
+			markdown: "```mermaid\ngraph LR\n  A --> B\n```",
+			want: []string{
+				"",
+				"  ┌───┐  ┌───┐",
+				"  │ A │─▶│ B │",
+				"  └───┘  └───┘",
+				"",
+			},
+		},
+		{
+			name:     "unsupported type shows the source",
+			markdown: "```mermaid\npie\n  \"a\": 1\n```",
+			want: []string{
+				"",
+				"  ◇ mermaid pie diagrams can't be drawn as text",
+				"",
+				"    pie",
+				`      "a": 1`,
+				"",
+			},
+		},
+		{
+			name:     "parse error shows the source",
+			markdown: "```dot\ndigraph {\n```",
+			want: []string{
+				"",
+				"  ◇ can't draw the diagram: missing }",
+				"",
+				"    digraph {",
+				"",
+			},
+		},
+		{
+			name:     "other code blocks are left alone",
+			markdown: "```go\nx := 1\n```",
+			want:     []string{"```go", "x := 1", "```"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Model
+			marked, blocks := m.markDiagrams(tt.markdown)
+
+			// Stand in for glamour's two column margin
+			var rendered []string
+			for _, line := range strings.Split(marked, "\n") {
+				if strings.HasPrefix(line, diagramMarker) {
+					line = "  " + line
+				}
+				rendered = append(rendered, line)
+			}
+
+			got := strings.Split(stripANSI(m.placeDiagrams(strings.Join(rendered, "\n"), blocks)), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("placed =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
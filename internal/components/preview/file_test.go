@@ -0,0 +1,68 @@
+package preview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ayushlm10/skim/internal/format"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	binary := "\x00\x01" + strings.Repeat("x", 2*format.SniffSize)
+	files := map[string]string{
+		"notes.md":     "# Notes\n",
+		"main.go":      "package main\n",
+		"blob.bin":     binary,
+		"empty.txt":    "",
+		"exactly.txt":  strings.Repeat("y", format.SniffSize),
+		"bigger.txt":   strings.Repeat("z", format.SniffSize+10),
+		"data.csv":     "a,b\n1,2\n",
+		"too-big.json": "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Truncate(filepath.Join(dir, "too-big.json"), maxFileSize+1); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "notes.md", want: files["notes.md"]},
+		{name: "main.go", want: files["main.go"]},
+		{name: "empty.txt", want: ""},
+		{name: "exactly.txt", want: files["exactly.txt"]},
+		{name: "bigger.txt", want: files["bigger.txt"]},
+		{name: "data.csv", want: files["data.csv"]},
+		// Only the start of a binary file is read
+		{name: "blob.bin", want: binary[:format.SniffSize]},
+		{name: "too-big.json", wantErr: true},
+		{name: "missing.md", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			content, size, err := readFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if content != tt.want {
+				t.Errorf("content = %d bytes, want %d", len(content), len(tt.want))
+			}
+			if info, _ := os.Stat(path); size != info.Size() {
+				t.Errorf("size = %d, want %d", size, info.Size())
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package preview
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/images"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ImagesDecodedMsg is sent when image files have been decoded in the
+// background, so the pictures can take their placeholders' places
+type ImagesDecodedMsg struct {
+	Paths []string
+}
+
+// imageBlock is a picture shown in the rendered content
+type imageBlock struct {
+	row    int // rendered row of its first line
+	indent int // columns before its left edge
+	pic    *images.Picture
+}
+
+// imageMarker stands in for an image on a line of its own while the
+// markdown is rendered; the picture takes the marker's line afterwards
+const imageMarker = "SKIMIMAGE"
+
+// markerPattern matches a marker and the index of its image
+var markerPattern = regexp.MustCompile(imageMarker + `(\d+)`)
+
+// SetImageProtocol sets how images are drawn; Off leaves them as alt text
+func (m *Model) SetImageProtocol(protocol images.Protocol) {
+	m.imageProtocol = protocol
+}
+
+// markImages replaces each image written on a line of its own with a
+// marker. It returns the markdown and the images in marker order.
+func (m Model) markImages(markdown string) (string, []mdlinks.Link) {
+	if m.imageProtocol == images.Off {
+		return markdown, nil
+	}
+
+	links := mdlinks.Extract(markdown)
+	perLine := make(map[int]int)
+	for _, link := range links {
+		perLine[link.Line]++
+	}
+
+	lines := strings.Split(markdown, "\n")
+	var marked []mdlinks.Link
+	for _, link := range links {
+		if link.Kind != mdlinks.Image || perLine[link.Line] != 1 {
+			continue
+		}
+		line := strings.TrimSpace(lines[link.Line])
+		if !strings.HasPrefix(line, "![") || !strings.HasSuffix(line, ")") {
+			continue
+		}
+		lines[link.Line] = strings.Replace(lines[link.Line], line, imageMarker+strconv.Itoa(len(marked)), 1)
+		marked = append(marked, link)
+	}
+	return strings.Join(lines, "\n"), marked
+}
+
+// placeImages swaps the markers in the rendered markdown for the pictures,
+// or for a placeholder naming the image when it can't be shown. A marker
+// that ended up inside a paragraph becomes the alt text.
+func (m *Model) placeImages(rendered string, marked []mdlinks.Link) string {
+	if len(marked) == 0 {
+		return rendered
+	}
+
+	var out []string
+	for _, line := range strings.Split(rendered, "\n") {
+		plain := strings.TrimSpace(stripANSI(line))
+		match := markerPattern.FindStringSubmatch(plain)
+		if match == nil {
+			out = append(out, line)
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		if n >= len(marked) {
+			out = append(out, line)
+			continue
+		}
+		if plain != match[0] {
+			out = append(out, markerPattern.ReplaceAllStringFunc(line, func(marker string) string {
+				i, _ := strconv.Atoi(strings.TrimPrefix(marker, imageMarker))
+				if i < len(marked) {
+					return marked[i].Text
+				}
+				return marker
+			}))
+			continue
+		}
+
+		indent := len(stripANSI(line)) - len(strings.TrimLeft(stripANSI(line), " "))
+		pad := strings.Repeat(" ", indent)
+		pic, reason := m.loadImage(marked[n], m.columnWidth()-indent-4)
+		if pic == nil {
+			out = append(out, pad+styles.ImagePlaceholderStyle.Render("▨ "+imageLabel(marked[n])+" · "+reason))
+			continue
+		}
+		m.imageBlocks = append(m.imageBlocks, imageBlock{row: len(out), indent: indent, pic: pic})
+		for _, row := range pic.Blocks {
+			out = append(out, pad+row)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// loadImage loads the picture an image link points at, sized to at most
+// maxCols and the height of the view. Returns why when it can't be shown;
+// a file not decoded yet is noted for DecodeImages.
+func (m *Model) loadImage(link mdlinks.Link, maxCols int) (*images.Picture, string) {
+	if mdlinks.IsExternal(link.Target) {
+		return nil, "remote image"
+	}
+	path := mdlinks.Resolve(m.filePath, link.Target)
+	if path == "" {
+		return nil, "no path"
+	}
+
+	pic, err := images.Load(path, max(maxCols, 4), max(m.height-2, 4), m.imageProtocol)
+	switch {
+	case err == nil:
+		return pic, ""
+	case errors.Is(err, images.ErrNotDecoded):
+		if !m.decoding[path] {
+			m.undecoded = append(m.undecoded, path)
+		}
+		return nil, "loading"
+	case errors.Is(err, os.ErrNotExist):
+		return nil, "not found"
+	case errors.Is(err, images.ErrUnsupported):
+		return nil, "unsupported format"
+	case errors.Is(err, images.ErrTooLarge):
+		return nil, "too large"
+	}
+	return nil, "can't decode"
+}
+
+// DecodeImages returns a command that decodes the image files the last
+// render was waiting on, or nil when there are none. Decoding is kept out
+// of rendering since a large image takes a while.
+func (m *Model) DecodeImages() tea.Cmd {
+	var paths []string
+	for _, path := range m.undecoded {
+		if !m.decoding[path] {
+			if m.decoding == nil {
+				m.decoding = make(map[string]bool)
+			}
+			m.decoding[path] = true
+			paths = append(paths, path)
+		}
+	}
+	m.undecoded = nil
+	if len(paths) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		for _, path := range paths {
+			images.Decode(path)
+		}
+		return ImagesDecodedMsg{Paths: paths}
+	}
+}
+
+// imageLabel names an image by its alt text, or its file name without one
+func imageLabel(link mdlinks.Link) string {
+	if text := strings.TrimSpace(link.Text); text != "" {
+		return text
+	}
+	return filepath.Base(link.Target)
+}
+
+// shownImages returns the images the terminal draws with its graphics
+// protocol: those entirely in view. The others stay as half blocks.
+func (m Model) shownImages() []imageBlock {
+	if !m.imageProtocol.Graphical() || m.err != nil || m.filePath == "" {
+		return nil
+	}
+	top, height := m.viewport.YOffset, m.viewport.Height
+	if m.searchMode {
+		height-- // the search input covers the last row
+	}
+
+	var shown []imageBlock
+	for _, b := range m.imageBlocks {
+		if b.row >= top && b.row+b.pic.Rows <= top+height {
+			shown = append(shown, b)
+		}
+	}
+	return shown
+}
+
+// viewportView renders the viewport, with blank rows where the terminal
+// draws images over the text
+func (m Model) viewportView() string {
+	view := m.viewport.View()
+	shown := m.shownImages()
+	if len(shown) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	for _, b := range shown {
+		for row := b.row - m.viewport.YOffset; row < b.row-m.viewport.YOffset+b.pic.Rows && row < len(lines); row++ {
+			lines[row] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Graphics returns the escape sequences that draw the images in view with
+// the terminal's graphics protocol, to be written after the frame. Kitty
+// images aren't part of the frame; PlaceImages moves them. x and y are the
+// screen cell of the preview's top-left corner; covered hides the images
+// while a popup is drawn over the preview.
+func (m Model) Graphics(x, y int, covered bool) string {
+	if covered {
+		return ""
+	}
+	return m.imageProtocol.Draw(m.placements(x, y))
+}
+
+// PlaceImages queues the kitty commands that show the images in view at
+// the screen cell x, y, taking them all down when covered, and returns the
+// command that sends them to the terminal. It returns nil for the other
+// protocols, which draw with the frame.
+func (m Model) PlaceImages(x, y int, covered bool) tea.Cmd {
+	if m.imageProtocol != images.Kitty {
+		return nil
+	}
+	var placements []images.Placement
+	if !covered {
+		placements = m.placements(x, y)
+	}
+	if !images.Place(placements) {
+		return nil
+	}
+	return func() tea.Msg {
+		images.Flush()
+		return nil
+	}
+}
+
+// placements positions the images in view on the screen, given the cell
+// of the preview's top-left corner
+func (m Model) placements(x, y int) []images.Placement {
+	if m.splitMode {
+		x += m.sourceWidth() + 1 // divider
+	}
+	var placements []images.Placement
+	for _, b := range m.shownImages() {
+		placements = append(placements, images.Placement{
+			Pic: b.pic,
+			X:   x + m.columnMargin() + b.indent,
+			Y:   y + b.row - m.viewport.YOffset,
+		})
+	}
+	return placements
+}
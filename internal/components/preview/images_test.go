@@ -0,0 +1,54 @@
+package preview
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ayushlm10/skim/internal/images"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestImagesDecodeInBackground(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "pic.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m := New(80, 40)
+	m.SetImageProtocol(images.Blocks)
+	m, _ = m.Update(FileLoadedMsg{Path: filepath.Join(dir, "doc.md"), Content: "# Doc\n\n![a cat](pic.png)\n"})
+
+	if view := ansi.Strip(m.viewport.View()); !strings.Contains(view, "a cat · loading") {
+		t.Fatalf("before decoding: view = %q, want the loading placeholder", view)
+	}
+	cmd := m.DecodeImages()
+	if cmd == nil {
+		t.Fatal("DecodeImages returned no command")
+	}
+
+	// Rendering again while the image decodes doesn't decode it twice
+	m.SetSize(80, 30)
+	if m.DecodeImages() != nil {
+		t.Error("DecodeImages started a second decode of the same file")
+	}
+
+	m, _ = m.Update(cmd())
+	if view := ansi.Strip(m.viewport.View()); strings.Contains(view, "loading") {
+		t.Errorf("after decoding: view = %q, want the picture", view)
+	}
+	if len(m.imageBlocks) != 1 {
+		t.Errorf("image blocks = %d, want 1", len(m.imageBlocks))
+	}
+	if m.DecodeImages() != nil {
+		t.Error("DecodeImages returned a command with nothing to decode")
+	}
+}
@@ -0,0 +1,156 @@
+package preview
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// LinkClickedMsg is sent when a link in the preview is clicked
+type LinkClickedMsg struct {
+	// From is the file containing the link
+	From string
+
+	// Target is the link destination as written
+	Target string
+
+	// Wiki is true for [[wiki links]], whose target is a note name
+	Wiki bool
+}
+
+// bareURL matches URLs written without link syntax (glamour shows them as-is)
+var bareURL = regexp.MustCompile(`(?:https?|mailto|ftp)://[^\s<>()]+|mailto:[^\s<>()]+`)
+
+// HandleClick follows the link at column x, row y of the component, if any.
+// In the split view a click also focuses the pane under it.
+func (m Model) HandleClick(x, y int) (Model, tea.Cmd) {
+	if m.filePath == "" || m.searchMode || m.taskMode {
+		return m, nil
+	}
+
+	if m.splitMode {
+		m.sourceFocused = x < m.sourceWidth()
+		if m.sourceFocused {
+			return m, nil
+		}
+		x -= m.sourceWidth() + 1 // divider
+	}
+
+	// Table headers sort and tree nodes fold
+	if (&m).handleDataClick(x, y) {
+		return m, nil
+	}
+	y -= m.headerHeight()
+
+	target, wiki := m.LinkAt(x, y)
+	if target == "" {
+		return m, nil
+	}
+
+	from := m.filePath
+	return m, func() tea.Msg {
+		return LinkClickedMsg{From: from, Target: target, Wiki: wiki}
+	}
+}
+
+// LinkAt returns the target of the link shown at column x, row y of the viewport,
+// or "" if there is none, and whether it is a wiki link. Rendered text is matched
+// back to links in the source, preferring the printed URL and then the link text.
+func (m Model) LinkAt(x, y int) (string, bool) {
+	lines := strings.Split(m.viewport.View(), "\n")
+	if y < 0 || y >= len(lines) {
+		return "", false
+	}
+	line := stripANSI(lines[y])
+	renderedLine := m.viewport.YOffset + y
+
+	// Links written in the source, nearest to this part of the document first
+	var best mdlinks.Link
+	bestDistance := -1
+	for _, link := range mdlinks.Extract(m.rawContent) {
+		if link.Kind == mdlinks.Image || link.Target == "" {
+			continue
+		}
+		// Wiki link targets are not printed, only their text
+		if (link.Kind == mdlinks.Wiki || !spanCovers(line, link.Target, x)) && !spanCovers(line, plainTaskText(link.Text), x) {
+			continue
+		}
+		distance := abs(m.estimateRenderedLine(link.Line) - renderedLine)
+		if bestDistance == -1 || distance < bestDistance {
+			best, bestDistance = link, distance
+		}
+	}
+	if bestDistance != -1 {
+		return best.Target, best.Kind == mdlinks.Wiki
+	}
+
+	// Autolinks and bare URLs
+	for _, loc := range bareURL.FindAllStringIndex(line, -1) {
+		start := ansi.StringWidth(line[:loc[0]])
+		end := start + ansi.StringWidth(line[loc[0]:loc[1]])
+		if x >= start && x < end {
+			return strings.TrimRight(line[loc[0]:loc[1]], ".,;:!?"), false
+		}
+	}
+	return "", false
+}
+
+// renderWikiLinks turns [[wiki links]] into markdown links glamour shows as
+// plain link text, since their targets are note names rather than paths
+func renderWikiLinks(body string) string {
+	return mdlinks.RewriteWiki(body, func(link mdlinks.Link) string {
+		return "[" + link.Text + "](#)"
+	})
+}
+
+// spanCovers reports whether an occurrence of text in line spans column x
+func spanCovers(line, text string, x int) bool {
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	for offset := 0; ; {
+		idx := strings.Index(line[offset:], text)
+		if idx == -1 {
+			return false
+		}
+		idx += offset
+		start := ansi.StringWidth(line[:idx])
+		end := start + ansi.StringWidth(text)
+		if x >= start && x < end {
+			return true
+		}
+		offset = idx + len(text)
+	}
+}
+
+// ScrollToAnchor scrolls to the heading or HTML anchor named anchor, as
+// mdlinks.Anchors numbers them. Returns false if the document has no such anchor.
+func (m *Model) ScrollToAnchor(anchor string) bool {
+	anchor = strings.ToLower(anchor)
+	for _, a := range mdlinks.FindAnchors(m.rawContent) {
+		if a.Name != anchor {
+			continue
+		}
+
+		// Put the heading at the top of the view
+		m.viewport.SetYOffset(m.findRenderedLine(a.Line, plainTaskText(a.Heading)))
+		return true
+	}
+	return false
+}
+
+// ScrollToLine centers the view on a 1-based line of the markdown source
+func (m *Model) ScrollToLine(line int) {
+	if line < 1 {
+		return
+	}
+	m.centerOnLine(m.estimateRenderedLine(line - 1))
+}
+
+// Slug converts heading text to a GitHub-style anchor
+func Slug(heading string) string {
+	return mdlinks.Slug(heading)
+}
@@ -0,0 +1,52 @@
+package preview
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/lint"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// blockMarkers matches the heading, list and quote markers glamour replaces
+var blockMarkers = regexp.MustCompile(`^\s*(?:(?:#{1,6}|[-*+]|\d+[.)]|>)\s+(?:\[[ xX]\]\s+)?)*`)
+
+// SetLintConfig sets the rules checked when files load
+func (m *Model) SetLintConfig(cfg lint.Config) {
+	m.lintConfig = cfg
+}
+
+// Diagnostics returns the lint findings for the current file
+func (m Model) Diagnostics() []lint.Diagnostic {
+	return m.diagnostics
+}
+
+// renderedDiagnosticLines finds the rendered lines showing lines with
+// findings. render keeps them, so scrolling doesn't search again.
+func (m Model) renderedDiagnosticLines() map[int]bool {
+	rawLines := strings.Split(m.rawContent, "\n")
+
+	lines := make(map[int]bool)
+	for _, d := range m.diagnostics {
+		raw := d.Line - 1
+		if raw < 0 || raw >= len(rawLines) {
+			continue
+		}
+		text := blockMarkers.ReplaceAllString(rawLines[raw], "")
+		lines[m.findRenderedLine(raw, plainTaskText(text))] = true
+	}
+	return lines
+}
+
+// markDiagnosticLines puts a gutter mark in the left margin of rendered lines
+func markDiagnosticLines(content string, marked map[int]bool) string {
+	lines := strings.Split(content, "\n")
+	marker := styles.LintMarkerStyle.Render(styles.LintMarker)
+	for i := range lines {
+		if marked[i] {
+			lines[i] = marker + ansi.TruncateLeft(lines[i], 1, "")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
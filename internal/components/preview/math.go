@@ -0,0 +1,304 @@
+package preview
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/Ayushlm10/skim/internal/texmath"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// mathBlock is display math taken out of the markdown: the converted lines,
+// or the TeX as written when it couldn't be converted
+type mathBlock struct {
+	lines     []string
+	converted bool
+}
+
+// mathMarker stands in for display math while the markdown is rendered
+const mathMarker = "SKIMMATH"
+
+var (
+	// mathPattern matches a marker and the index of its math
+	mathPattern = regexp.MustCompile(mathMarker + `(\d+)`)
+
+	// mathEnvironment matches the start of an equation environment written
+	// without $$ around it
+	mathEnvironment = regexp.MustCompile(`^\\begin\{(equation|align|aligned|alignat|gather|gathered|multline|eqnarray|split)(\*?)\}`)
+
+	// listItem matches a line starting a list item
+	listItem = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])(?:\s|$)`)
+
+	// markdownEscapes are the characters escaped in inline math so they
+	// aren't read as markdown
+	markdownEscapes = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+		`<`, `\<`, `>`, `\>`, `|`, `\|`, `~`, `\~`, `#`, `\#`,
+	)
+)
+
+// markMath converts the TeX math in markdown outside code. Inline math
+// ($...$ and \(...\)) becomes Unicode text in place, or stays as written
+// when it can't be converted. Display math ($$...$$, \[...\] and equation
+// environments) is replaced with markers; the blocks are returned in marker
+// order.
+func (m Model) markMath(markdown string) (string, []mathBlock) {
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	var blocks []mathBlock
+
+	for i := 0; i < len(lines); i++ {
+		if _, fence, _, ok := mdlinks.OpenFence(lines[i]); ok {
+			last := min(closingFence(lines, i, fence), len(lines)-1)
+			out = append(out, lines[i:last+1]...)
+			i = last
+			continue
+		}
+		if last := indentedCode(lines, i); last >= 0 {
+			out = append(out, lines[i:last+1]...)
+			i = last
+			continue
+		}
+
+		tex, end := displayMath(lines, i)
+		if end < 0 {
+			out = append(out, inlineMath(lines[i]))
+			continue
+		}
+		b := mathBlock{lines: lines[i : end+1]}
+		if converted, ok := texmath.Display(tex); ok {
+			b = mathBlock{lines: converted, converted: true}
+		}
+		indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " "))]
+		out = append(out, "", indent+mathMarker+strconv.Itoa(len(blocks)), "")
+		blocks = append(blocks, b)
+		i = end
+	}
+	return strings.Join(out, "\n"), blocks
+}
+
+// indentedCode returns the last line of the indented code block starting on
+// line i, or -1 when none starts there. The block is indented four columns
+// after a blank line; under a list item the indent continues the item.
+func indentedCode(lines []string, i int) int {
+	if !codeIndent(lines[i]) || strings.TrimSpace(lines[i]) == "" {
+		return -1
+	}
+	j := i - 1
+	if j >= 0 && strings.TrimSpace(lines[j]) != "" {
+		return -1 // indented code can't interrupt a paragraph
+	}
+	for j >= 0 && strings.TrimSpace(lines[j]) == "" {
+		j--
+	}
+	if j >= 0 && (listItem.MatchString(lines[j]) || strings.HasPrefix(lines[j], " ") || strings.HasPrefix(lines[j], "\t")) {
+		return -1
+	}
+
+	last := i
+	for k := i + 1; k < len(lines); k++ {
+		if codeIndent(lines[k]) {
+			last = k
+		} else if strings.TrimSpace(lines[k]) != "" {
+			break
+		}
+	}
+	return last
+}
+
+// codeIndent reports whether a line is indented as code: four spaces or a tab
+func codeIndent(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(strings.TrimLeft(line, " "), "\t")
+}
+
+// displayMath returns the TeX of the display math starting on line i and
+// the line it ends on, or -1 when no display math starts there. The math
+// closes in the same block: a blank line ends it unclosed, so an escaped
+// bracket isn't read as math running on through the document.
+func displayMath(lines []string, i int) (string, int) {
+	rest := strings.TrimSpace(lines[i])
+	var close string
+	environment := false // whether the delimiters are part of the TeX
+	switch {
+	case strings.HasPrefix(rest, "$$"):
+		rest, close = rest[2:], "$$"
+	case strings.HasPrefix(rest, `\[`):
+		rest, close = rest[2:], `\]`
+	case mathEnvironment.MatchString(rest):
+		m := mathEnvironment.FindStringSubmatch(rest)
+		close, environment = `\end{`+m[1]+m[2]+`}`, true
+	default:
+		return "", -1
+	}
+
+	var tex []string
+	for j := i; j < len(lines); j++ {
+		if j > i {
+			rest = strings.TrimSpace(lines[j])
+			if rest == "" {
+				return "", -1
+			}
+		}
+		k := strings.Index(rest, close)
+		if k < 0 {
+			tex = append(tex, rest)
+			continue
+		}
+		if strings.TrimSpace(rest[k+len(close):]) != "" {
+			return "", -1 // text after the math: leave it to the inline pass
+		}
+		if environment {
+			k += len(close)
+		}
+		tex = append(tex, rest[:k])
+		return strings.Join(tex, "\n"), j
+	}
+	return "", -1
+}
+
+// inlineMath converts the inline math on a line, leaving code spans,
+// escaped dollars and escaped backslashes alone
+func inlineMath(line string) string {
+	if !strings.ContainsAny(line, `$\`) {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '`':
+			n := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+			end := closingTicks(line, i+n, n)
+			if end < 0 {
+				b.WriteString(line[i : i+n])
+				i += n
+				continue
+			}
+			b.WriteString(line[i : end+n])
+			i = end + n
+
+		case strings.HasPrefix(line[i:], `\$`), strings.HasPrefix(line[i:], `\\`):
+			b.WriteString(line[i : i+2])
+			i += 2
+
+		case strings.HasPrefix(line[i:], `\(`):
+			end := strings.Index(line[i+2:], `\)`)
+			if end < 0 {
+				b.WriteString(`\(`)
+				i += 2
+				continue
+			}
+			raw := line[i : i+2+end+2]
+			b.WriteString(convertInline(line[i+2:i+2+end], raw))
+			i += len(raw)
+
+		case line[i] == '$':
+			delim := "$"
+			if strings.HasPrefix(line[i:], "$$") {
+				delim = "$$"
+			}
+			start := i + len(delim)
+			end := closingDollar(line, start, delim)
+			if end < 0 {
+				b.WriteString(delim)
+				i = start
+				continue
+			}
+			raw := line[i : end+len(delim)]
+			b.WriteString(convertInline(line[start:end], raw))
+			i += len(raw)
+
+		default:
+			b.WriteByte(line[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// closingTicks returns where a run of exactly n backticks starts at or
+// after i, or -1
+func closingTicks(line string, i, n int) int {
+	for i < len(line) {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		run := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// closingDollar returns where the math opened just before start closes, or
+// -1. As in pandoc, math can't start with a space, and a single $ can't
+// close after a space or before a digit, so prices aren't read as math.
+// Math never spans a code span.
+func closingDollar(line string, start int, delim string) int {
+	if start >= len(line) || line[start] == ' ' {
+		return -1
+	}
+	if delim == "$$" {
+		end := strings.Index(line[start:], "$$")
+		if end <= 0 || strings.Contains(line[start:start+end], "`") {
+			return -1
+		}
+		return start + end
+	}
+	for j := start + 1; j < len(line); j++ {
+		if line[j] == '`' {
+			return -1 // math doesn't run into code spans
+		}
+		if line[j] != '$' {
+			continue
+		}
+		if line[j-1] == ' ' || line[j-1] == '\\' {
+			continue
+		}
+		if j+1 < len(line) && line[j+1] >= '0' && line[j+1] <= '9' {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// convertInline returns inline math as Unicode text for the markdown, or the
+// TeX as written, escaped to show literally, when it can't be converted
+func convertInline(tex, raw string) string {
+	if s, ok := texmath.Inline(tex); ok {
+		return markdownEscapes.Replace(s)
+	}
+	return markdownEscapes.Replace(raw)
+}
+
+// placeMath swaps the markers in the rendered markdown for the display
+// math, centered in the view
+func (m Model) placeMath(rendered string, blocks []mathBlock) string {
+	return placeMarkers(rendered, mathPattern, len(blocks), func(n, _ int, pad string) []string {
+		b := blocks[n]
+		var out []string
+		if !b.converted {
+			for _, line := range b.lines {
+				out = append(out, pad+"  "+styles.MathSourceStyle.Render(strings.TrimSpace(line)))
+			}
+			return out
+		}
+
+		w := 0
+		for _, line := range b.lines {
+			w = max(w, ansi.StringWidth(line))
+		}
+		center := pad + strings.Repeat(" ", max((m.columnWidth()-2*len(pad)-w)/2, 2))
+		for _, line := range b.lines {
+			out = append(out, center+styles.MathStyle.Render(line))
+		}
+		return out
+	})
+}
@@ -0,0 +1,101 @@
+package preview
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// matterPriority lists front matter keys shown first, in this order
+var matterPriority = []string{"title", "tags", "authors", "author", "date", "status"}
+
+// renderMatter renders the front matter as a table, or a one line summary when collapsed
+func (m Model) renderMatter() string {
+	if m.matter == nil || len(m.matter.Keys) == 0 {
+		return ""
+	}
+
+	width := m.columnWidth() - 8
+	if width < 20 {
+		width = 20
+	}
+
+	if m.matterCollapsed {
+		var summary []string
+		if title := m.matter.Title(); title != "" {
+			summary = append(summary, styles.MatterTitleStyle.Render(title))
+		}
+		summary = append(summary, styles.MatterHintStyle.Render(pluralFields(len(m.matter.Keys))+" · m to expand"))
+		line := ansi.Truncate(strings.Join(summary, "  "), width, "…")
+		return "\n" + styles.MatterBorderStyle.Render(line) + "\n"
+	}
+
+	keys := orderedKeys(m.matter)
+	keyWidth := 0
+	for _, key := range keys {
+		if w := lipgloss.Width(key); w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	var rows []string
+	for _, key := range keys {
+		value := m.matter.Get(key)
+		valueStyle := styles.MatterValueStyle
+		if key == "title" {
+			valueStyle = styles.MatterTitleStyle
+		}
+		label := styles.MatterKeyStyle.Render(key + strings.Repeat(" ", keyWidth-lipgloss.Width(key)))
+		value = ansi.Truncate(strings.ReplaceAll(value, "\n", " "), width-keyWidth-2, "…")
+		rows = append(rows, label+"  "+valueStyle.Render(value))
+	}
+	rows = append(rows, styles.MatterHintStyle.Render("m to collapse"))
+
+	return "\n" + styles.MatterBorderStyle.Render(strings.Join(rows, "\n")) + "\n"
+}
+
+// orderedKeys returns front matter keys with the well-known ones first
+func orderedKeys(matter *frontmatter.Matter) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range matterPriority {
+		if _, ok := matter.Fields[key]; ok {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	for _, key := range matter.Keys {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// pluralFields formats a field count, e.g. "1 field" or "4 fields"
+func pluralFields(n int) string {
+	if n == 1 {
+		return "1 field"
+	}
+	return strconv.Itoa(n) + " fields"
+}
+
+// toggleMatter collapses or expands the front matter table
+func (m *Model) toggleMatter() {
+	if m.matter == nil {
+		return
+	}
+	m.matterCollapsed = !m.matterCollapsed
+	if err := m.render(); err == nil {
+		m.refreshContent()
+	}
+}
+
+// Matter returns the front matter of the current document (nil if none)
+func (m Model) Matter() *frontmatter.Matter {
+	return m.matter
+}
@@ -0,0 +1,331 @@
+package preview
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Overflow is how code blocks and tables wider than the view are shown
+type Overflow int
+
+const (
+	// WrapOverflow wraps wide blocks to the view, as glamour does
+	WrapOverflow Overflow = iota
+	// ScrollBlocks lays wide blocks out unwrapped and scrolls only them sideways
+	ScrollBlocks
+	// ScrollDocument lays wide blocks out unwrapped and scrolls the whole document
+	ScrollDocument
+	overflowCount
+)
+
+// overflowNames are the config names of the overflow modes
+var overflowNames = [...]string{"wrap", "blocks", "document"}
+
+// String returns the config name of the mode
+func (o Overflow) String() string {
+	return overflowNames[o]
+}
+
+// Next returns the mode after this one, wrapping around
+func (o Overflow) Next() Overflow {
+	return (o + 1) % overflowCount
+}
+
+// ParseOverflow parses a mode name, defaulting to WrapOverflow
+func ParseOverflow(name string) Overflow {
+	for o := WrapOverflow; o < overflowCount; o++ {
+		if o.String() == name {
+			return o
+		}
+	}
+	return WrapOverflow
+}
+
+// sideStep is how many columns a key press or wheel notch scrolls sideways
+const sideStep = 8
+
+// wideMarker stands in for a wide block while the markdown is rendered; the
+// unwrapped block takes the marker's line afterwards
+const wideMarker = "SKIMWIDE"
+
+var (
+	// widePattern matches a marker and the index of its block
+	widePattern = regexp.MustCompile(wideMarker + `(\d+)`)
+
+	// tableDelimiter matches the row under a table's header
+	tableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+	// cellLink matches a link in a table cell, which glamour shows as its
+	// text and a reference number
+	cellLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+	// cellMarkup is the emphasis and code markup glamour leaves out of cells
+	cellMarkup = strings.NewReplacer("`", "", "**", "", "__", "", `\|`, "|")
+)
+
+// SetOverflow sets how wide code blocks and tables are shown and renders
+// the document again
+func (m *Model) SetOverflow(overflow Overflow) {
+	m.overflow = overflow
+	if m.tableHeader == "" {
+		m.xOffset = 0
+	}
+	if m.rawContent == "" || m.renderer == nil {
+		return
+	}
+	if err := m.render(); err == nil {
+		m.refreshContent()
+	}
+}
+
+// Overflow returns how wide code blocks and tables are shown
+func (m Model) Overflow() Overflow {
+	return m.overflow
+}
+
+// markWide replaces each fenced code block and table too wide for the view
+// with a marker, unless wide blocks wrap. It returns the markdown and the
+// blocks in marker order.
+func (m Model) markWide(markdown string) (string, []string) {
+	if m.overflow == WrapOverflow || m.renderer == nil {
+		return markdown, nil
+	}
+
+	lines := strings.Split(markdown, "\n")
+	var out, blocks []string
+	mark := func(indent string, block []string) {
+		out = append(out, "", indent+wideMarker+strconv.Itoa(len(blocks)), "")
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if indent, fence, _, ok := mdlinks.OpenFence(lines[i]); ok {
+			last := min(closingFence(lines, i, fence), len(lines)-1)
+			block := lines[i : last+1]
+			if codeWidth(block)+6 > m.renderer.Width() {
+				mark(indent, block)
+			} else {
+				out = append(out, block...)
+			}
+			i = last
+			continue
+		}
+
+		if end := tableEnd(lines, i); end > i {
+			block := lines[i:end]
+			if tableWidth(block)+4 > m.renderer.Width() {
+				indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " "))]
+				mark(indent, block)
+			} else {
+				out = append(out, block...)
+			}
+			i = end - 1
+			continue
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(out, "\n"), blocks
+}
+
+// codeWidth returns the width of the longest line inside a fenced block
+func codeWidth(block []string) int {
+	w := 0
+	for _, line := range block[1:] {
+		w = max(w, ansi.StringWidth(strings.ReplaceAll(line, "\t", "    ")))
+	}
+	return w
+}
+
+// tableEnd returns the line after the table starting on line i, or i when
+// no table starts there
+func tableEnd(lines []string, i int) int {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") ||
+		!strings.Contains(lines[i+1], "|") || !tableDelimiter.MatchString(lines[i+1]) {
+		return i
+	}
+	end := i + 2
+	for end < len(lines) && strings.Contains(lines[end], "|") && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	return end
+}
+
+// tableWidth estimates how wide glamour lays a table out without wrapping
+// its cells: each column as wide as its widest cell, plus padding and
+// separators
+func tableWidth(block []string) int {
+	var widths []int
+	for i, line := range block {
+		if i == 1 {
+			continue // delimiter row
+		}
+		for c, cell := range tableCells(line) {
+			cell = cellMarkup.Replace(cellLink.ReplaceAllString(cell, "$1[0]"))
+			if c == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[c] = max(widths[c], ansi.StringWidth(cell))
+		}
+	}
+	w := max(len(widths)-1, 0)
+	for _, cw := range widths {
+		w += cw + 2
+	}
+	return w
+}
+
+// tableCells splits a table row into its trimmed cells
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+// placeWide swaps the markers in the rendered markdown for the blocks laid
+// out at their full width, and records the rows they take up. A block
+// that can't be rendered wide keeps its wrapped rendering.
+func (m *Model) placeWide(rendered string, blocks []string) string {
+	return placeMarkers(rendered, widePattern, len(blocks), func(n, row int, pad string) []string {
+		var need int
+		lines := strings.Split(blocks[n], "\n")
+		if _, _, _, ok := mdlinks.OpenFence(lines[0]); ok {
+			need = codeWidth(lines) + 6
+		} else {
+			need = tableWidth(lines) + 4
+		}
+
+		wide, err := m.renderer.RenderWidth(renderWikiLinks(blocks[n]), need)
+		if err != nil {
+			wide, _ = m.renderer.Render(renderWikiLinks(blocks[n]))
+		}
+
+		var out []string
+		for _, line := range strings.Split(wide, "\n") {
+			if strings.TrimSpace(stripANSI(line)) == "" && len(out) == 0 {
+				continue // blank rows above the block
+			}
+			line = ansi.TruncateLeft(line, 2, "") // glamour's margin
+			line = ansi.Truncate(line, ansi.StringWidth(strings.TrimRight(stripANSI(line), " ")), "")
+			out = append(out, pad+line)
+		}
+		for len(out) > 0 && strings.TrimSpace(stripANSI(out[len(out)-1])) == "" {
+			out = out[:len(out)-1]
+		}
+
+		if m.wideRows == nil {
+			m.wideRows = make(map[int]bool)
+		}
+		for i := range out {
+			m.wideRows[row+i] = true
+		}
+		return out
+	})
+}
+
+// shiftWideRows moves the recorded wide rows below the pictures placed
+// after them, which take several rows in place of their marker's one
+func (m *Model) shiftWideRows() {
+	if len(m.wideRows) == 0 || len(m.imageBlocks) == 0 {
+		return
+	}
+	shifted := make(map[int]bool, len(m.wideRows))
+	for row := range m.wideRows {
+		extra := 0
+		for _, b := range m.imageBlocks {
+			// b.row-extra is the marker's row before the pictures were placed
+			if b.row-extra >= row {
+				break
+			}
+			extra += len(b.pic.Blocks) - 1
+		}
+		shifted[row+extra] = true
+	}
+	m.wideRows = shifted
+}
+
+// scrollsSideways reports whether the document has rows wider than the
+// view that scroll sideways
+func (m Model) scrollsSideways() bool {
+	return m.overflow != WrapOverflow && m.tableHeader == "" && len(m.wideRows) > 0 &&
+		m.scrollWidth() > m.columnWidth()
+}
+
+// CanScrollSideways returns whether the document has wide blocks that
+// scroll sideways
+func (m Model) CanScrollSideways() bool {
+	return m.scrollsSideways() && !m.rawMode && m.err == nil
+}
+
+// scrolled reports whether a rendered row moves when the view scrolls
+// sideways
+func (m Model) scrolled(row int) bool {
+	return m.overflow == ScrollDocument || m.wideRows[row]
+}
+
+// scrollWidth returns the width of the widest row that scrolls sideways
+func (m Model) scrollWidth() int {
+	w := 0
+	for row, line := range strings.Split(m.renderedContent, "\n") {
+		if m.scrolled(row) {
+			w = max(w, ansi.StringWidth(strings.TrimRight(stripANSI(line), " ")))
+		}
+	}
+	return w
+}
+
+// scrollSideways scrolls the wide rows by delta columns, stopping once the
+// end of the widest is in view
+func (m *Model) scrollSideways(delta int) {
+	m.xOffset = max(0, min(m.xOffset+delta, m.scrollWidth()-m.columnWidth()))
+	m.refreshContent()
+}
+
+// cutWide cuts the rows that scroll sideways to the view, marking the
+// edges that have more beyond them
+func (m Model) cutWide(content string) string {
+	if m.overflow == WrapOverflow || len(m.wideRows) == 0 || m.tableHeader != "" {
+		return content
+	}
+
+	width := m.columnWidth()
+	offset := max(0, min(m.xOffset, m.scrollWidth()-width))
+	left := styles.OverflowMarkStyle.Render("‹")
+	right := styles.OverflowMarkStyle.Render("›")
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if !m.scrolled(i) {
+			continue
+		}
+		plain := strings.TrimRight(stripANSI(line), " ")
+		cut := ansi.Cut(line, offset, offset+width)
+		if ansi.StringWidth(plain) > offset+width {
+			cut = ansi.Truncate(cut, width-1, "") + right
+		}
+		if offset > 0 && strings.TrimSpace(plain) != "" {
+			cut = left + ansi.TruncateLeft(cut, 1, "")
+		}
+		lines[i] = cut
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,238 @@
+package preview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		start    int
+		want     int
+	}{
+		{"table", "| a | b |\n| - | - |\n| 1 | 2 |", 0, 3},
+		{"no outer pipes", "a | b\n:-- | --:\n1 | 2\n\nafter", 0, 3},
+		{"ends at a line without pipes", "| a |\n|---|\n| 1 |\ntext", 0, 3},
+		{"header only", "| a |\n|---|", 0, 2},
+		{"no delimiter row", "| a | b |\n| 1 | 2 |", 0, 0},
+		{"not a table", "a - b\n---", 0, 0},
+		{"starts later", "text\n| a |\n|---|\n| 1 |", 1, 4},
+		{"last line", "| a |", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableEnd(strings.Split(tt.markdown, "\n"), tt.start); got != tt.want {
+				t.Errorf("tableEnd = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableCells(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"| a | b |", []string{"a", "b"}},
+		{"a | b", []string{"a", "b"}},
+		{"|  | b |", []string{"", "b"}},
+		{`| a \| b | c |`, []string{`a \| b`, "c"}},
+		{`| a | b \|`, []string{"a", `b \|`}},
+		{`| \\| b |`, []string{`\\`, "b"}}, // an escaped backslash, then a separator
+	}
+	for _, tt := range tests {
+		if got := tableCells(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tableCells(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTableWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		want  int
+	}{
+		// columns of 5 and 3, each padded by 2, and one separator
+		{"widest cell per column", "| a | b |\n|---|---|\n| hello | x |\n| hi | xyz |", 13},
+		{"escaped pipe counts once", "| a \\| b |\n|---|", 7},
+		{"code and emphasis markup is dropped", "| `code` | **bold** |\n|---|---|", 13},
+		{"links show their text and a reference", "| [docs](https://example.com/long/path) |\n|---|", 9},
+		{"wide characters", "| 日本語 |\n|---|", 8},
+		{"ragged rows", "| a |\n|---|\n| 1 | 22 |", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableWidth(strings.Split(tt.table, "\n")); got != tt.want {
+				t.Errorf("tableWidth = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkWide(t *testing.T) {
+	wideCode := "```go\n" + strings.Repeat("x", 40) + "\n```"
+	narrowCode := "```go\nx := 1\n```"
+	wideTable := "| " + strings.Repeat("a", 20) + " | " + strings.Repeat("b", 20) + " |\n|---|---|\n| 1 | 2 |"
+	narrowTable := "| a | b |\n|---|---|\n| 1 | 2 |"
+	escapedTable := "| a \\| b |\n|---|\n| " + strings.Repeat("c", 40) + " |"
+
+	tests := []struct {
+		name       string
+		overflow   Overflow
+		markdown   string
+		want       string
+		wantBlocks []string
+	}{
+		{
+			name:     "wrapping leaves everything",
+			overflow: WrapOverflow,
+			markdown: wideCode + "\n\n" + wideTable,
+			want:     wideCode + "\n\n" + wideTable,
+		},
+		{
+			name:       "wide code",
+			overflow:   ScrollBlocks,
+			markdown:   "before\n" + wideCode + "\nafter",
+			want:       "before\n\nSKIMWIDE0\n\nafter",
+			wantBlocks: []string{wideCode},
+		},
+		{
+			name:     "narrow code and tables stay",
+			overflow: ScrollBlocks,
+			markdown: narrowCode + "\n\n" + narrowTable,
+			want:     narrowCode + "\n\n" + narrowTable,
+		},
+		{
+			name:       "wide table",
+			overflow:   ScrollDocument,
+			markdown:   narrowTable + "\n\n" + wideTable + "\n\nafter",
+			want:       narrowTable + "\n\n\nSKIMWIDE0\n\n\nafter",
+			wantBlocks: []string{wideTable},
+		},
+		{
+			name:       "indented table with an escaped pipe",
+			overflow:   ScrollBlocks,
+			markdown:   "- item\n\n  " + strings.ReplaceAll(escapedTable, "\n", "\n  "),
+			want:       "- item\n\n\n  SKIMWIDE0\n",
+			wantBlocks: []string{"  " + strings.ReplaceAll(escapedTable, "\n", "\n  ")},
+		},
+		{
+			name:       "table inside code is code",
+			overflow:   ScrollBlocks,
+			markdown:   "```\n" + wideTable + "\n```",
+			want:       "\nSKIMWIDE0\n",
+			wantBlocks: []string{"```\n" + wideTable + "\n```"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(40, 20)
+			m.overflow = tt.overflow
+			got, blocks := m.markWide(tt.markdown)
+			if got != tt.want {
+				t.Errorf("markdown =\n%q\nwant\n%q", got, tt.want)
+			}
+			if !reflect.DeepEqual(blocks, tt.wantBlocks) {
+				t.Errorf("blocks = %q, want %q", blocks, tt.wantBlocks)
+			}
+		})
+	}
+}
+
+func TestCutWide(t *testing.T) {
+	content := strings.Join([]string{
+		"0123456789abcdefghij", // wide row
+		"wrapped text row that does not scroll",
+		"0123456789", // wide row that fits
+		"",           // blank wide row
+	}, "\n")
+
+	tests := []struct {
+		name     string
+		overflow Overflow
+		xOffset  int
+		want     []string
+	}{
+		{
+			name:     "start",
+			overflow: ScrollBlocks,
+			want:     []string{"012345678›", "wrapped text row that does not scroll", "0123456789", ""},
+		},
+		{
+			name:     "middle",
+			overflow: ScrollBlocks,
+			xOffset:  5,
+			want:     []string{"‹6789abcd›", "wrapped text row that does not scroll", "‹6789", ""},
+		},
+		{
+			// The short row is scrolled out of view but keeps its left marker
+			name:     "offset past the end is clamped",
+			overflow: ScrollBlocks,
+			xOffset:  50,
+			want:     []string{"‹bcdefghij", "wrapped text row that does not scroll", "‹", ""},
+		},
+		{
+			name:     "document scrolls every row",
+			overflow: ScrollDocument,
+			xOffset:  10,
+			want:     []string{"‹bcdefghij", "‹t row th›", "‹", ""},
+		},
+		{
+			name:     "wrapping cuts nothing",
+			overflow: WrapOverflow,
+			xOffset:  5,
+			want:     strings.Split(content, "\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(10, 5)
+			m.overflow = tt.overflow
+			m.wideRows = map[int]bool{0: true, 2: true, 3: true}
+			m.renderedContent = content
+			m.xOffset = tt.xOffset
+
+			got := strings.Split(stripANSI(m.cutWide(content)), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cutWide =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrollSideways(t *testing.T) {
+	m := New(10, 5)
+	m.overflow = ScrollBlocks
+	m.wideRows = map[int]bool{0: true}
+	m.renderedContent = "0123456789abcdefghij\nnarrow"
+
+	steps := []struct {
+		delta int
+		want  int
+	}{
+		{sideStep, 8},
+		{sideStep, 10}, // the end of the widest row is in view
+		{sideStep, 10},
+		{-sideStep, 2},
+		{-sideStep, 0},
+		{-sideStep, 0},
+	}
+	for _, step := range steps {
+		m.scrollSideways(step.delta)
+		if m.xOffset != step.want {
+			t.Fatalf("scrolling by %d: offset = %d, want %d", step.delta, m.xOffset, step.want)
+		}
+	}
+
+	if !m.scrollsSideways() {
+		t.Error("scrollsSideways = false with a row wider than the view")
+	}
+	m.renderedContent = "0123456789\nnarrow"
+	if m.scrollsSideways() {
+		t.Error("scrollsSideways = true when every row fits")
+	}
+}
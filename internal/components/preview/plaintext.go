@@ -0,0 +1,25 @@
+package preview
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// renderPlainText wraps plain text to width with the same margins glamour
+// gives markdown. Tabs are expanded and long words are broken.
+func renderPlainText(text string, width int) string {
+	if width < 10 {
+		width = 10
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		for _, part := range strings.Split(ansi.Wrap(line, width, ""), "\n") {
+			b.WriteString("  " + part + "\n")
+		}
+	}
+	return b.String()
+}
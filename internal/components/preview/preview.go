@@ -1,10 +1,17 @@
 package preview
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/images"
+	"github.com/Ayushlm10/skim/internal/lint"
 	"github.com/Ayushlm10/skim/internal/styles"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -17,7 +24,8 @@ import (
 // FileLoadedMsg is sent when a file has been loaded and rendered
 type FileLoadedMsg struct {
 	Path    string
-	Content string
+	Content string // Only the start of a binary file
+	Size    int64
 	Error   error
 }
 
@@ -28,6 +36,10 @@ type Model struct {
 
 	// Raw markdown content
 	rawContent string
+	fileSize   int64 // Size on disk; a binary file's content is only its start
+
+	// Format of the current file, which decides how it is rendered
+	docFormat format.Format
 
 	// Rendered content
 	renderedContent string
@@ -57,6 +69,63 @@ type Model struct {
 	searchQuery  string          // Current search query (after Enter)
 	matches      []int           // Line numbers in rawContent that match
 	currentMatch int             // Index into matches slice (0-based)
+
+	// Task list state
+	tasks       []Task // Task list items found in rawContent
+	taskMode    bool   // Whether task selection is active
+	currentTask int    // Index into tasks slice (0-based)
+
+	// Front matter state
+	matter          *frontmatter.Matter // Parsed front matter of the current file
+	matterCollapsed bool                // Whether the metadata table shows only a summary
+
+	// Raw source view state
+	rawMode    bool  // Whether the highlighted markdown source is shown instead
+	sourceRows []int // Rendered row of each source line in the raw view
+
+	// Split view state: the source pane on the left, the rendered view on the right
+	splitMode     bool           // Whether the source is shown beside the rendered view
+	sourcePane    viewport.Model // Viewport for the source pane
+	paneContent   string         // Highlighted source shown in the pane
+	paneRows      []int          // Pane row of each source line
+	lineMap       []int          // Rendered row of each source line, for scroll sync
+	sourceFocused bool           // Whether scroll keys move the source pane
+
+	// Data view state: CSV and TSV tables, JSON and YAML trees
+	table        *format.Table   // Parsed table (nil if not a table)
+	rowOrder     []int           // Table rows in display order
+	sortColumn   int             // Column the table is sorted by (-1 for file order)
+	sortDesc     bool            // Whether the sort order is descending
+	xOffset      int             // Columns of the table scrolled off to the left
+	columnStarts []int           // Display column each table column starts at
+	tableHeader  string          // Header rows kept above the scrolling table
+	tree         *format.Node    // Parsed data tree (nil if not a tree)
+	folded       map[string]bool // Paths of the folded maps and lists
+	treeRows     []string        // Path of the foldable node on each tree row
+	dataErr      error           // Why the table or tree couldn't be parsed
+
+	// Image state
+	imageProtocol images.Protocol // How images are drawn (Off leaves the alt text)
+	imageBlocks   []imageBlock    // Pictures in the rendered content
+	undecoded     []string        // Image files the last render found not yet decoded
+	decoding      map[string]bool // Image files being decoded in the background
+
+	// Reading mode state: rendered text kept to a centered column
+	readingMode bool // Whether the text column is limited to maxWidth
+	maxWidth    int  // Widest the text column gets in reading mode
+
+	// Wide block state: code blocks and tables laid out unwrapped
+	overflow Overflow     // How blocks wider than the view are shown
+	wideRows map[int]bool // Rendered rows of the unwrapped blocks, which scroll sideways
+
+	// Diagram state
+	diagramSource bool // Whether diagram blocks show their source instead of a drawing
+	diagramCount  int  // Diagram blocks in the rendered document
+
+	// Lint state
+	lintConfig     lint.Config       // Rules run on every load
+	diagnostics    []lint.Diagnostic // Findings for the current file
+	diagnosticRows map[int]bool      // Rendered rows of the lines with findings
 }
 
 // New creates a new preview component
@@ -85,6 +154,7 @@ func New(width, height int) Model {
 
 	return Model{
 		viewport:     vp,
+		sourcePane:   newSourcePane(width, height),
 		renderer:     renderer,
 		width:        width,
 		height:       height,
@@ -94,6 +164,8 @@ func New(width, height int) Model {
 		searchMode:   false,
 		matches:      nil,
 		currentMatch: 0,
+		sortColumn:   -1,
+		maxWidth:     defaultMaxWidth,
 	}
 }
 
@@ -112,6 +184,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m, nil
 
 	case FileLoadedMsg:
+		// A reload of the current file keeps the scroll position and task selection
+		reload := msg.Error == nil && msg.Path == m.filePath
+		yOffset := m.viewport.YOffset
+
 		// Clear any existing search when loading a new file
 		m.clearSearch()
 		m.searchMode = false
@@ -120,21 +196,61 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		if msg.Error != nil {
 			m.err = msg.Error
 			m.renderedContent = ""
+			m.tasks = nil
+			m.taskMode = false
+			m.diagnostics, m.diagnosticRows = nil, nil
+			m.tableHeader = ""
+			m.applyHeight()
 			m.viewport.SetContent(m.renderError(msg.Error))
 		} else {
 			m.filePath = msg.Path
 			m.rawContent = msg.Content
+			m.fileSize = msg.Size
+			m.docFormat = format.Detect(msg.Path, msg.Content)
 			m.err = nil
+			m.loadData(reload)
+
+			// Tasks and lint rules only apply to markdown
+			m.tasks, m.diagnostics = nil, nil
+			if m.docFormat.Markdown {
+				m.tasks = parseTasks(msg.Content)
+				m.diagnostics = lint.Check(msg.Content, m.lintConfig)
+			}
+			if !reload {
+				m.taskMode = false
+				m.currentTask = 0
+			}
+			if m.currentTask >= len(m.tasks) {
+				m.currentTask = len(m.tasks) - 1
+			}
+			if len(m.tasks) == 0 {
+				m.taskMode = false
+				m.currentTask = 0
+			}
 
 			// Render the content
-			rendered, err := m.renderer.Render(msg.Content)
-			if err != nil {
+			if err := m.render(); err != nil {
 				m.err = err
 				m.viewport.SetContent(m.renderError(err))
 			} else {
-				m.renderedContent = rendered
-				m.viewport.SetContent(rendered)
-				m.viewport.GotoTop()
+				m.refreshContent()
+				if reload {
+					m.viewport.SetYOffset(yOffset)
+				} else {
+					m.viewport.GotoTop()
+				}
+				m.syncSourcePane()
+			}
+		}
+		return m, nil
+
+	case ImagesDecodedMsg:
+		for _, path := range msg.Paths {
+			delete(m.decoding, path)
+		}
+		if m.rawContent != "" && m.renderer != nil && m.err == nil {
+			if err := m.render(); err == nil {
+				m.refreshContent()
 			}
 		}
 		return m, nil
@@ -154,11 +270,36 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 // HandleKey handles keyboard input when focused
 func (m Model) HandleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	// The split view has its own pane keys
+	if m.splitMode && !m.searchMode && (&m).handleSplitKey(msg) {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m, cmd = m.handleKey(msg)
+	(&m).syncSourcePane()
+	return m, cmd
+}
+
+// handleKey handles keys for the rendered view
+func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 	// Handle search mode input
 	if m.searchMode {
 		return m.handleSearchKey(msg)
 	}
 
+	// Handle task selection keys
+	if m.taskMode {
+		if handled, cmd := (&m).handleTaskKey(msg); handled {
+			return m, cmd
+		}
+	}
+
+	// Tables and data trees have their own keys
+	if (&m).handleDataKey(msg) {
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "up", "k":
 		m.viewport.LineUp(1)
@@ -218,6 +359,48 @@ func (m Model) HandleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "h", "left":
+		// Scroll wide code blocks and tables
+		if m.scrollsSideways() {
+			(&m).scrollSideways(-sideStep)
+		}
+		return m, nil
+
+	case "l", "right":
+		if m.scrollsSideways() {
+			(&m).scrollSideways(sideStep)
+		}
+		return m, nil
+
+	case "r":
+		// Switch between the rendered and raw source views
+		m.toggleRaw()
+		return m, nil
+
+	case "v":
+		// Show the source beside the rendered view
+		m.toggleSplit()
+		return m, nil
+
+	case "m":
+		// Collapse or expand the front matter table
+		m.toggleMatter()
+		return m, nil
+
+	case "d":
+		// Switch between drawn diagrams and their source
+		m.toggleDiagrams()
+		return m, nil
+
+	case "t":
+		// Enter task selection mode
+		if len(m.tasks) > 0 {
+			m.taskMode = true
+			(&m).refreshContent()
+			(&m).scrollToCurrentTask()
+		}
+		return m, nil
+
 	case "esc":
 		// Clear search if active
 		if m.searchQuery != "" {
@@ -335,16 +518,41 @@ func stripANSI(s string) string {
 
 // applySearchHighlight highlights search matches in the rendered content
 func (m *Model) applySearchHighlight() {
-	if m.searchQuery == "" || m.renderedContent == "" {
-		m.viewport.SetContent(m.renderedContent)
-		return
+	m.refreshContent()
+}
+
+// refreshContent sets the viewport content from the rendered markdown,
+// layering search highlights, lint gutter marks and the selected task marker
+// on top, centered in reading mode
+func (m *Model) refreshContent() {
+	m.applyHeight()
+	content := m.renderedContent
+	if m.searchQuery != "" && content != "" {
+		content = highlightMatches(content, m.searchQuery)
+	}
+	content = m.cutWide(content)
+	if len(m.diagnosticRows) > 0 && content != "" {
+		content = markDiagnosticLines(content, m.diagnosticRows)
+	}
+	if m.taskMode && content != "" {
+		content = markTaskLine(content, m.renderedTaskLine())
+	}
+	m.viewport.SetContent(centerColumn(content, m.columnMargin()))
+
+	// Tables scroll in the viewport; wide blocks are cut above
+	if m.tableHeader != "" {
+		m.viewport.SetXOffset(m.xOffset)
+	} else {
+		m.viewport.SetXOffset(0)
 	}
 
-	highlighted := highlightMatches(m.renderedContent, m.searchQuery)
-	// Debug: Log that highlighting is being applied
-	// Remove this after debugging
-	_ = highlighted // Ensure we're using the variable
-	m.viewport.SetContent(highlighted)
+	if m.splitMode {
+		pane := m.paneContent
+		if m.searchQuery != "" {
+			pane = highlightMatches(pane, m.searchQuery)
+		}
+		m.sourcePane.SetContent(pane)
+	}
 }
 
 // highlightMatches applies reverse video highlighting to all occurrences of query
@@ -458,21 +666,48 @@ func (m *Model) scrollToCurrentMatch() {
 	// Get the line number in raw content
 	rawLine := m.matches[m.currentMatch]
 
+	// Matches inside folded tree nodes are unfolded first
+	if m.IsTree() {
+		m.revealTreeLine(rawLine)
+	}
+
+	row := m.estimateRenderedLine(rawLine)
+	m.centerOnLine(row)
+	if m.IsTable() {
+		m.revealColumn(row)
+	}
+}
+
+// estimateRenderedLine estimates where a raw content line appears in the rendered content
+func (m Model) estimateRenderedLine(rawLine int) int {
 	// The viewport shows rendered content, which may have different line counts
-	// We need to estimate where in the rendered content this line appears
 	// Since Glamour can add blank lines, headers, etc., we estimate by ratio
+	// The raw view knows exactly where each line is
+	if m.sourceRows != nil && rawLine >= 0 && rawLine < len(m.sourceRows) {
+		return m.sourceRows[rawLine]
+	}
+	// The split view maps every line
+	if m.lineMap != nil && rawLine >= 0 && rawLine < len(m.lineMap) {
+		return m.lineMap[rawLine]
+	}
+
 	rawLineCount := len(strings.Split(m.rawContent, "\n"))
 	renderedLineCount := m.viewport.TotalLineCount()
+	if m.renderedContent != "" {
+		// The viewport may not show the latest render yet
+		renderedLineCount = strings.Count(m.renderedContent, "\n") + 1
+	}
 
 	if rawLineCount == 0 {
-		return
+		return 0
 	}
 
-	// Estimate the rendered line position
 	ratio := float64(rawLine) / float64(rawLineCount)
-	targetLine := int(ratio * float64(renderedLineCount))
+	return int(ratio * float64(renderedLineCount))
+}
 
-	// Center the match in the viewport
+// centerOnLine scrolls the viewport so the given rendered line is centered
+func (m *Model) centerOnLine(targetLine int) {
 	halfView := m.viewport.VisibleLineCount() / 2
 	scrollTo := targetLine - halfView
 	if scrollTo < 0 {
@@ -491,18 +726,49 @@ func (m *Model) clearSearch() {
 
 	// Restore original rendered content (without highlights)
 	if m.renderedContent != "" {
-		m.viewport.SetContent(m.renderedContent)
+		m.refreshContent()
 	}
 }
 
-// HandleMouse handles mouse input (scrolling)
+// HandleMouse handles mouse input (scrolling). msg.X is relative to the component.
 func (m Model) HandleMouse(msg tea.MouseMsg) (Model, tea.Cmd) {
-	// Forward to viewport - it handles mouse wheel natively
+	// In the split view the pane under the pointer scrolls and the other follows
 	var cmd tea.Cmd
+	if m.splitMode && msg.X < m.sourceWidth() {
+		m.sourcePane, cmd = m.sourcePane.Update(msg)
+		m.syncRenderedPane()
+		return m, cmd
+	}
+
+	// Shift+wheel and horizontal wheels scroll tables and wide blocks sideways
+	if delta := sideways(msg); delta != 0 {
+		switch {
+		case m.IsTable():
+			m.scrollColumns(delta)
+		case m.scrollsSideways():
+			m.scrollSideways(delta * sideStep)
+		}
+		return m, nil
+	}
+
+	// Forward to viewport - it handles mouse wheel natively
 	m.viewport, cmd = m.viewport.Update(msg)
+	m.syncSourcePane()
 	return m, cmd
 }
 
+// sideways returns which way a wheel event scrolls sideways: -1 left, 1
+// right, or 0 for vertical scrolling
+func sideways(msg tea.MouseMsg) int {
+	switch {
+	case msg.Button == tea.MouseButtonWheelLeft, msg.Shift && msg.Button == tea.MouseButtonWheelUp:
+		return -1
+	case msg.Button == tea.MouseButtonWheelRight, msg.Shift && msg.Button == tea.MouseButtonWheelDown:
+		return 1
+	}
+	return 0
+}
+
 // View renders the component
 func (m Model) View() string {
 	if !m.ready {
@@ -510,10 +776,15 @@ func (m Model) View() string {
 	}
 
 	if m.filePath == "" {
-		return m.renderWelcome()
+		return centerColumn(m.renderWelcome(), m.columnMargin())
 	}
 
-	viewportContent := m.viewport.View()
+	viewportContent := m.viewportView()
+	if m.splitMode && m.err == nil {
+		viewportContent = m.viewSplit()
+	} else if m.tableHeader != "" && m.err == nil {
+		viewportContent = m.viewTableHeader() + "\n" + viewportContent
+	}
 
 	// If search mode is active, show the search input at the bottom
 	if m.searchMode {
@@ -614,36 +885,67 @@ func (m Model) renderError(err error) string {
 		Render(content)
 }
 
+// renderBinary renders the notice shown instead of a binary file
+func (m Model) renderBinary() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Muted).
+		Bold(true)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(styles.Subtle).
+		Italic(true).
+		MarginTop(1)
+
+	title := titleStyle.Render("Binary file not shown")
+	hint := hintStyle.Render(strconv.FormatInt(m.fileSize, 10) + " bytes")
+
+	return lipgloss.NewStyle().
+		Padding(2, 3).
+		Render(title + "\n" + hint)
+}
+
 // SetSize updates the component size
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
-	m.viewport.Width = width
 	m.viewport.Height = height
+	m.sourcePane.Height = height
 
-	// Update renderer width for word wrap
-	if m.renderer != nil {
-		_ = m.renderer.SetWidth(width - 4) // Account for padding
-	}
+	// Update viewport and renderer widths for word wrap
+	m.applyWidth()
 
 	// Update search input width
 	m.searchInput.Width = width - 10
 
 	// Re-render content if we have any
 	if m.rawContent != "" && m.renderer != nil {
-		rendered, err := m.renderer.Render(m.rawContent)
-		if err == nil {
-			m.renderedContent = rendered
-			// If there's an active search, apply highlighting
-			if m.searchQuery != "" {
-				m.applySearchHighlight()
-			} else {
-				m.viewport.SetContent(rendered)
-			}
+		if err := m.render(); err == nil {
+			// Re-apply search highlighting and task marker
+			m.refreshContent()
 		}
 	}
 }
 
+// Clear resets the preview to the welcome screen
+func (m *Model) Clear() {
+	m.clearSearch()
+	m.filePath = ""
+	m.rawContent = ""
+	m.renderedContent = ""
+	m.tasks = nil
+	m.taskMode = false
+	m.matter = nil
+	m.diagnostics, m.diagnosticRows = nil, nil
+	m.paneContent, m.paneRows, m.lineMap = "", nil, nil
+	m.table, m.tree, m.dataErr, m.folded = nil, nil, nil, nil
+	m.tableHeader, m.treeRows = "", nil
+	m.imageBlocks, m.undecoded, m.diagramCount, m.wideRows = nil, nil, 0, nil
+	m.sortColumn, m.sortDesc, m.xOffset = -1, false, 0
+	m.err = nil
+	m.viewport.SetContent("")
+	m.sourcePane.SetContent("")
+}
+
 // SetFocused sets the focus state
 func (m *Model) SetFocused(focused bool) {
 	m.focused = focused
@@ -725,20 +1027,80 @@ func (m Model) HasSearchNoMatches() bool {
 	return m.searchQuery != "" && len(m.matches) == 0
 }
 
+// Format returns the format of the current file
+func (m Model) Format() format.Format {
+	return m.docFormat
+}
+
+// IsRawMode returns whether the raw source view is shown
+func (m Model) IsRawMode() bool {
+	return m.rawMode
+}
+
+// IsTaskMode returns whether task selection is active
+func (m Model) IsTaskMode() bool {
+	return m.taskMode
+}
+
+// TaskProgress returns the number of checked tasks and the total task count
+func (m Model) TaskProgress() (done, total int) {
+	for _, t := range m.tasks {
+		if t.Checked {
+			done++
+		}
+	}
+	return done, len(m.tasks)
+}
+
+// maxFileSize is the largest file the preview reads
+const maxFileSize = 16 << 20
+
 // LoadFile creates a command to load a file
 func LoadFile(path string) tea.Cmd {
 	return func() tea.Msg {
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return FileLoadedMsg{
-				Path:  path,
-				Error: err,
-			}
-		}
-
+		content, size, err := readFile(path)
 		return FileLoadedMsg{
 			Path:    path,
-			Content: string(content),
+			Content: content,
+			Size:    size,
+			Error:   err,
 		}
 	}
 }
+
+// readFile reads a file and returns its content and size. Only the start
+// of a binary file is read, which is enough to tell it's binary, and text
+// files over maxFileSize are refused.
+func readFile(path string) (string, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	head := make([]byte, format.SniffSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", 0, err
+	}
+	head = head[:n]
+
+	// Registered formats are detected by name, whatever their bytes
+	if _, ok := format.Lookup(path); !ok && format.IsBinary(string(head)) {
+		return string(head), info.Size(), nil
+	}
+	if info.Size() > maxFileSize {
+		return "", info.Size(), fmt.Errorf("file is too large to preview (%d MB, the limit is %d MB)",
+			info.Size()>>20, maxFileSize>>20)
+	}
+
+	rest, err := io.ReadAll(f)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(head) + string(rest), info.Size(), nil
+}
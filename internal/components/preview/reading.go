@@ -0,0 +1,72 @@
+package preview
+
+import "strings"
+
+// defaultMaxWidth is the widest the text column gets in reading mode when
+// no width is configured
+const defaultMaxWidth = 100
+
+// SetMaxWidth sets the widest the text column gets in reading mode; 0 uses
+// the default
+func (m *Model) SetMaxWidth(width int) {
+	if width <= 0 {
+		width = defaultMaxWidth
+	}
+	m.maxWidth = width
+	m.relayout()
+}
+
+// SetReadingMode turns the centered text column on or off
+func (m *Model) SetReadingMode(on bool) {
+	m.readingMode = on
+	m.relayout()
+}
+
+// IsReadingMode returns whether rendered text is kept to a centered column
+func (m Model) IsReadingMode() bool {
+	return m.readingMode
+}
+
+// columnWidth returns the width rendered text is laid out in: the view, or
+// at most the max width in reading mode
+func (m Model) columnWidth() int {
+	if m.readingMode && m.maxWidth > 0 {
+		return min(m.contentWidth(), m.maxWidth)
+	}
+	return m.contentWidth()
+}
+
+// columnMargin returns the columns left of the centered text column. Raw
+// source, code and data files keep the full width.
+func (m Model) columnMargin() int {
+	if !m.readingMode || m.rawMode || !m.hasRenderedView() || m.isData() || m.err != nil {
+		return 0
+	}
+	return (m.contentWidth() - m.columnWidth()) / 2
+}
+
+// relayout sizes the renderer for the text column and renders again
+func (m *Model) relayout() {
+	m.applyWidth()
+	if m.rawContent == "" || m.renderer == nil {
+		return
+	}
+	if err := m.render(); err == nil {
+		m.refreshContent()
+	}
+}
+
+// centerColumn moves each line right by margin columns
+func centerColumn(content string, margin int) string {
+	if margin <= 0 {
+		return content
+	}
+	pad := strings.Repeat(" ", margin)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,95 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestColumnLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		reading    bool
+		maxWidth   int
+		split      bool
+		raw        bool
+		wantWidth  int
+		wantMargin int
+	}{
+		{name: "off", width: 160, maxWidth: 100, wantWidth: 160},
+		{name: "wide panel", width: 160, reading: true, maxWidth: 100, wantWidth: 100, wantMargin: 30},
+		{name: "odd leftover", width: 161, reading: true, maxWidth: 100, wantWidth: 100, wantMargin: 30},
+		{name: "narrow panel", width: 80, reading: true, maxWidth: 100, wantWidth: 80},
+		{name: "split view uses its pane", width: 241, reading: true, maxWidth: 100, split: true, wantWidth: 100, wantMargin: 10},
+		{name: "raw source isn't centered", width: 160, reading: true, maxWidth: 100, raw: true, wantWidth: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.width, 20)
+			m.readingMode = tt.reading
+			m.maxWidth = tt.maxWidth
+			m.splitMode = tt.split
+			m.rawMode = tt.raw
+			if got := m.columnWidth(); got != tt.wantWidth {
+				t.Errorf("columnWidth = %d, want %d", got, tt.wantWidth)
+			}
+			if got := m.columnMargin(); got != tt.wantMargin {
+				t.Errorf("columnMargin = %d, want %d", got, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestCenterColumn(t *testing.T) {
+	tests := []struct {
+		content string
+		margin  int
+		want    string
+	}{
+		{"a\n\nb", 2, "  a\n\n  b"},
+		{"a\nb", 0, "a\nb"},
+		{"a", -1, "a"},
+	}
+	for _, tt := range tests {
+		if got := centerColumn(tt.content, tt.margin); got != tt.want {
+			t.Errorf("centerColumn(%q, %d) = %q, want %q", tt.content, tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestReadingModeCentersText(t *testing.T) {
+	m := New(160, 40)
+	m.SetMaxWidth(60)
+	m.SetReadingMode(true)
+	m, _ = m.Update(FileLoadedMsg{
+		Path:    "notes.md",
+		Content: "# Title\n\n" + strings.Repeat("reading mode keeps lines short ", 20),
+	})
+
+	var widths []int
+	for _, line := range strings.Split(m.viewport.View(), "\n") {
+		plain := strings.TrimRight(ansi.Strip(line), " ")
+		if strings.TrimSpace(plain) == "" {
+			continue
+		}
+		if indent := len(plain) - len(strings.TrimLeft(plain, " ")); indent < 50 {
+			t.Errorf("line %q starts at column %d, want the 50 column margin", plain, indent)
+		}
+		widths = append(widths, ansi.StringWidth(plain))
+	}
+	if len(widths) < 3 {
+		t.Fatalf("rendered %d lines, want the paragraph wrapped over several", len(widths))
+	}
+	for _, w := range widths {
+		if w > 50+60 {
+			t.Errorf("line ends at column %d, past the 60 column text column", w)
+		}
+	}
+
+	m.SetReadingMode(false)
+	if got := m.columnMargin(); got != 0 {
+		t.Errorf("margin after turning reading mode off = %d, want 0", got)
+	}
+}
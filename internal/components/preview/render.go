@@ -0,0 +1,78 @@
+package preview
+
+import (
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+)
+
+// render renders rawContent into renderedContent for the file's format and
+// the current view, recording the rows that pictures and wide blocks take up
+func (m *Model) render() error {
+	m.tableHeader, m.imageBlocks, m.diagramCount, m.wideRows = "", nil, 0, nil
+	m.diagnosticRows, m.undecoded = nil, nil
+	switch {
+	case m.docFormat.Binary:
+		m.matter, m.sourceRows = nil, nil
+		m.renderedContent = m.renderBinary()
+		return nil
+	case m.docFormat.Code:
+		m.matter = nil
+		m.renderedContent, m.sourceRows = renderSource(highlightLines(m.rawContent, m.docFormat.Lexer), m.contentWidth())
+		return nil
+	case m.isData() && !m.rawMode:
+		m.matter = nil
+		m.renderedContent, m.sourceRows = m.renderData()
+		return nil
+	}
+
+	var matter *frontmatter.Matter
+	body := m.rawContent
+	if m.docFormat.Markdown {
+		matter, body = frontmatter.Split(m.rawContent)
+	}
+
+	if m.rawMode {
+		m.matter = matter
+		m.renderedContent, m.sourceRows = renderSource(highlightLines(m.rawContent, m.docFormat.Lexer), m.contentWidth())
+		m.diagnosticRows = m.renderedDiagnosticLines()
+		m.renderSplitSource()
+		return nil
+	}
+	m.sourceRows = nil
+
+	var rendered string
+	if m.docFormat.ToMarkdown == nil {
+		rendered = renderPlainText(body, m.columnWidth()-4)
+	} else {
+		markdown, diagrams := m.markDiagrams(m.docFormat.ToMarkdown(body))
+		markdown, maths := m.markMath(markdown)
+		markdown, wide := m.markWide(markdown)
+		markdown, marked := m.markImages(markdown)
+		var err error
+		rendered, err = m.renderer.Render(renderWikiLinks(markdown))
+		if err != nil {
+			return err
+		}
+		rendered = m.placeImages(m.placeWide(m.placeMath(m.placeDiagrams(rendered, diagrams), maths), wide), marked)
+		m.shiftWideRows()
+	}
+
+	m.matter = matter
+	matterRows := m.renderMatter()
+	m.renderedContent = matterRows + rendered
+	shift := strings.Count(matterRows, "\n")
+	for i := range m.imageBlocks {
+		m.imageBlocks[i].row += shift
+	}
+	if shift > 0 && len(m.wideRows) > 0 {
+		wideRows := make(map[int]bool, len(m.wideRows))
+		for row := range m.wideRows {
+			wideRows[row+shift] = true
+		}
+		m.wideRows = wideRows
+	}
+	m.diagnosticRows = m.renderedDiagnosticLines()
+	m.renderSplitSource()
+	return nil
+}
@@ -8,6 +8,11 @@ import (
 type Renderer struct {
 	renderer *glamour.TermRenderer
 	width    int
+
+	// Renderer for blocks laid out wider than the view, kept for the last
+	// width asked for
+	wide      *glamour.TermRenderer
+	wideWidth int
 }
 
 // NewRenderer creates a new markdown renderer
@@ -31,6 +36,22 @@ func (r *Renderer) Render(content string) (string, error) {
 	return r.renderer.Render(content)
 }
 
+// RenderWidth renders markdown content wrapped at the given width instead
+// of the view's
+func (r *Renderer) RenderWidth(content string, width int) (string, error) {
+	if r.wide == nil || r.wideWidth != width {
+		wide, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return "", err
+		}
+		r.wide, r.wideWidth = wide, width
+	}
+	return r.wide.Render(content)
+}
+
 // SetWidth updates the word wrap width and recreates the renderer
 func (r *Renderer) SetWidth(width int) error {
 	if r.width == width {
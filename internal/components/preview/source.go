@@ -0,0 +1,171 @@
+package preview
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// darkBackground caches the terminal background query, which is slow
+var darkBackground = sync.OnceValue(lipgloss.HasDarkBackground)
+
+// toggleRaw switches between the rendered and raw source views, keeping
+// the same part of the document at the top of the view
+func (m *Model) toggleRaw() {
+	if m.filePath == "" || m.err != nil || !m.hasRenderedView() {
+		return
+	}
+
+	top := m.topSourceLine()
+	m.rawMode = !m.rawMode
+	if m.splitMode {
+		m.splitMode = false
+		m.applyWidth()
+	}
+	if err := m.render(); err != nil {
+		m.err = err
+		m.viewport.SetContent(m.renderError(err))
+		return
+	}
+	m.refreshContent()
+	m.viewport.SetYOffset(m.estimateRenderedLine(top))
+}
+
+// hasRenderedView reports whether the file has a rendered view besides its
+// source; source and binary files don't
+func (m Model) hasRenderedView() bool {
+	return !m.docFormat.Code && !m.docFormat.Binary
+}
+
+// topSourceLine returns the source line shown at the top of the view
+func (m Model) topSourceLine() int {
+	offset := m.viewport.YOffset
+	if m.sourceRows != nil {
+		line := 0
+		for i, row := range m.sourceRows {
+			if row > offset {
+				break
+			}
+			line = i
+		}
+		return line
+	}
+	if m.lineMap != nil {
+		line := sort.SearchInts(m.lineMap, offset+1) - 1
+		for line > 0 && m.lineMap[line-1] == m.lineMap[line] {
+			line--
+		}
+		return max(line, 0)
+	}
+
+	// Invert the ratio estimate used for the rendered view
+	rendered := strings.Count(m.renderedContent, "\n") + 1
+	raw := strings.Count(m.rawContent, "\n") + 1
+	return offset * raw / rendered
+}
+
+// highlightLines syntax highlights source with the chroma lexer for
+// language, returning one styled string per source line. Unknown
+// languages are returned unstyled.
+func highlightLines(source, language string) []string {
+	lines := strings.Split(source, "\n")
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return lines
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	style := chromastyles.Get("monokailight")
+	if darkBackground() {
+		style = chromastyles.Get("monokai")
+	}
+	formatter := formatters.Get("terminal256")
+
+	iterator, err := lexer.Tokenise(nil, source)
+	if err != nil {
+		return lines
+	}
+
+	// Format line by line so colors never run across the gutter
+	var highlighted []string
+	var current []chroma.Token
+	flush := func() {
+		var b strings.Builder
+		if err := formatter.Format(&b, style, chroma.Literator(current...)); err != nil {
+			b.Reset()
+			for _, token := range current {
+				b.WriteString(token.Value)
+			}
+		}
+		highlighted = append(highlighted, b.String())
+		current = current[:0]
+	}
+	for _, token := range iterator.Tokens() {
+		parts := strings.Split(token.Value, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				flush()
+			}
+			if part != "" {
+				current = append(current, chroma.Token{Type: token.Type, Value: part})
+			}
+		}
+	}
+	flush()
+
+	// Lexers add a trailing newline to the last line
+	if len(highlighted) > len(lines) {
+		highlighted = highlighted[:len(lines)]
+	}
+	return highlighted
+}
+
+// renderSource lays out highlighted lines with a line number gutter,
+// wrapping long lines to width. It returns the content and the rendered
+// row each source line starts on.
+func renderSource(lines []string, width int) (string, []int) {
+	numberWidth := len(strconv.Itoa(len(lines)))
+	gutter := 2 + numberWidth + 3 // margin, number, " │ "
+	textWidth := width - gutter
+	if textWidth < 10 {
+		textWidth = 10
+	}
+
+	continuation := strings.Repeat(" ", 2+numberWidth) + styles.SourceGutterStyle.Render(" │ ")
+
+	var b strings.Builder
+	rows := make([]int, len(lines))
+	row := 0
+	for i, line := range lines {
+		rows[i] = row
+		number := strconv.Itoa(i + 1)
+		prefix := "  " + styles.SourceLineNumberStyle.Render(strings.Repeat(" ", numberWidth-len(number))+number) +
+			styles.SourceGutterStyle.Render(" │ ")
+
+		line = strings.ReplaceAll(strings.TrimSuffix(line, "\r"), "\t", "    ")
+		wrapped := strings.Split(ansi.Hardwrap(line, textWidth, true), "\n")
+		for j, part := range wrapped {
+			if row > 0 {
+				b.WriteString("\n")
+			}
+			if j == 0 {
+				b.WriteString(prefix)
+			} else {
+				b.WriteString(continuation)
+			}
+			b.WriteString(part)
+			row++
+		}
+	}
+	return b.String(), rows
+}
@@ -0,0 +1,117 @@
+package preview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHighlightLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		language string
+	}{
+		{"markdown", "# Title\n\nSome *text* and `code`\n\n```go\nx := 1\n```", "markdown"},
+		{"go", "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n", "go"},
+		{"multi-line tokens", "/* one\ntwo */\nx = 1", "c"},
+		{"unknown language", "plain\ntext", "no-such-language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightLines(tt.source, tt.language)
+			var plain []string
+			for _, line := range got {
+				plain = append(plain, stripANSI(line))
+			}
+			if want := strings.Split(tt.source, "\n"); !reflect.DeepEqual(plain, want) {
+				t.Errorf("highlighted text =\n%q\nwant\n%q", plain, want)
+			}
+		})
+	}
+}
+
+func TestRenderSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		width    int
+		want     []string
+		wantRows []int
+	}{
+		{
+			name:     "gutter",
+			lines:    []string{"# Title", "", "text"},
+			width:    40,
+			want:     []string{"  1 │ # Title", "  2 │ ", "  3 │ text"},
+			wantRows: []int{0, 1, 2},
+		},
+		{
+			name:  "numbers are right aligned",
+			lines: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"},
+			width: 40,
+			want: []string{
+				"   1 │ a", "   2 │ b", "   3 │ c", "   4 │ d", "   5 │ e",
+				"   6 │ f", "   7 │ g", "   8 │ h", "   9 │ i", "  10 │ j",
+			},
+			wantRows: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:     "long lines wrap under the gutter",
+			lines:    []string{strings.Repeat("a", 25), "b"},
+			width:    16,
+			want:     []string{"  1 │ aaaaaaaaaa", "    │ aaaaaaaaaa", "    │ aaaaa", "  2 │ b"},
+			wantRows: []int{0, 3},
+		},
+		{
+			name:     "tabs and carriage returns",
+			lines:    []string{"\tx\r"},
+			width:    40,
+			want:     []string{"  1 │     x"},
+			wantRows: []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, rows := renderSource(tt.lines, tt.width)
+			got := strings.Split(stripANSI(content), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("content =\n%q\nwant\n%q", got, tt.want)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestTopSourceLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceRows []int
+		lineMap    []int
+		rendered   string
+		raw        string
+		offset     int
+		want       int
+	}{
+		{name: "raw view rows", sourceRows: []int{0, 1, 4, 5}, offset: 3, want: 1},
+		{name: "raw view exact row", sourceRows: []int{0, 1, 4, 5}, offset: 4, want: 2},
+		{name: "line map", lineMap: []int{0, 2, 2, 6}, offset: 3, want: 1},
+		{name: "estimate", rendered: strings.Repeat("\n", 99), raw: strings.Repeat("\n", 49), offset: 20, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(40, 5)
+			m.sourceRows = tt.sourceRows
+			m.lineMap = tt.lineMap
+			m.renderedContent = tt.rendered
+			m.rawContent = tt.raw
+			m.viewport.SetContent(strings.Repeat("\n", 200))
+			m.viewport.SetYOffset(tt.offset)
+			if got := m.topSourceLine(); got != tt.want {
+				t.Errorf("topSourceLine = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
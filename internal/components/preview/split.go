@@ -0,0 +1,275 @@
+package preview
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// taskBoxes strips task list checkboxes, which glamour replaces
+var taskBoxes = strings.NewReplacer("[ ] ", "", "[x] ", "", "[X] ", "")
+
+// newSourcePane creates the viewport for the source side of the split view
+func newSourcePane(width, height int) viewport.Model {
+	vp := viewport.New(width, height)
+	vp.MouseWheelEnabled = true
+	vp.MouseWheelDelta = 5
+	return vp
+}
+
+// sourceWidth returns the width of the source pane in the split view
+func (m Model) sourceWidth() int {
+	return (m.width - 1) / 2
+}
+
+// contentWidth returns the width of the rendered view, which is the right
+// pane when the view is split
+func (m Model) contentWidth() int {
+	if m.splitMode {
+		return m.width - m.sourceWidth() - 1 // divider
+	}
+	return m.width
+}
+
+// applyWidth sizes the viewports and renderer for the current view mode
+func (m *Model) applyWidth() {
+	m.viewport.Width = m.contentWidth()
+	m.sourcePane.Width = m.sourceWidth()
+	if m.renderer != nil {
+		_ = m.renderer.SetWidth(m.columnWidth() - 4) // Account for padding
+	}
+}
+
+// toggleSplit shows or hides the markdown source beside the rendered view,
+// keeping the same part of the document at the top
+func (m *Model) toggleSplit() {
+	// Tables and trees have no source lines to line up with
+	if m.filePath == "" || m.err != nil || !m.hasRenderedView() || m.isData() {
+		return
+	}
+
+	top := m.topSourceLine()
+	m.splitMode = !m.splitMode
+	m.rawMode = false
+	m.sourceFocused = false
+	m.applyWidth()
+	if err := m.render(); err != nil {
+		m.err = err
+		m.viewport.SetContent(m.renderError(err))
+		return
+	}
+	m.refreshContent()
+	m.viewport.SetYOffset(m.estimateRenderedLine(top))
+	m.syncSourcePane()
+}
+
+// renderSplitSource renders the source pane and maps each source line to the
+// rendered row showing it
+func (m *Model) renderSplitSource() {
+	if !m.splitMode {
+		m.paneContent, m.paneRows, m.lineMap = "", nil, nil
+		return
+	}
+
+	m.paneContent, m.paneRows = renderSource(highlightLines(m.rawContent, m.docFormat.Lexer), m.sourceWidth())
+
+	skip := 0
+	if m.matter != nil {
+		skip = m.matter.Lines
+	}
+	m.lineMap = buildLineMap(m.rawContent, m.renderedContent, skip)
+}
+
+// buildLineMap returns the rendered row of every source line. Lines are
+// matched by their leading text in document order; lines that can't be
+// found, such as blank lines, are placed between their matched neighbours.
+// The first skip lines (the front matter) map to the top.
+func buildLineMap(raw, rendered string, skip int) []int {
+	sourceLines := strings.Split(raw, "\n")
+	renderedLines := strings.Split(rendered, "\n")
+	for i, line := range renderedLines {
+		renderedLines[i] = strings.ToLower(strings.Join(strings.Fields(stripANSI(line)), " "))
+	}
+
+	rows := make([]int, len(sourceLines))
+	found := make([]bool, len(sourceLines))
+	prevLine, prevRow := skip-1, 0
+	var fence mdlinks.Fence
+	for i := skip; i < len(sourceLines); i++ {
+		line := sourceLines[i]
+		if fence.Scan(line) {
+			continue
+		}
+
+		needle := lineNeedle(line, fence.Open())
+		if len(needle) < 3 {
+			continue
+		}
+
+		// Rendered blocks rarely grow by more than a few rows per source line,
+		// which keeps repeated text from matching far ahead
+		limit := prevRow + (i-prevLine)*3 + 8
+		for row := prevRow; row < len(renderedLines) && row <= limit; row++ {
+			if strings.Contains(renderedLines[row], needle) {
+				rows[i], found[i] = row, true
+				prevLine, prevRow = i, row
+				break
+			}
+		}
+	}
+
+	// Place unmatched lines between the matched lines around them
+	lastLine, lastRow := -1, 0
+	for i := 0; i <= len(sourceLines); i++ {
+		if i < len(sourceLines) && !found[i] {
+			continue
+		}
+		nextRow := len(renderedLines)
+		if i < len(sourceLines) {
+			nextRow = rows[i]
+		}
+		for j := lastLine + 1; j < i; j++ {
+			if j < skip {
+				continue
+			}
+			rows[j] = lastRow + (j-lastLine)*(nextRow-lastRow)/(i-lastLine)
+		}
+		lastLine, lastRow = i, nextRow
+	}
+	return rows
+}
+
+// lineNeedle returns the leading text of a source line as glamour prints it,
+// lowercased with whitespace collapsed. Text is cut before links and inline
+// HTML, whose rendering differs from the source.
+func lineNeedle(line string, code bool) string {
+	text := strings.TrimSpace(line)
+	if !code {
+		text = strings.TrimLeft(text, "#>|")
+		text = strings.TrimSpace(text)
+		if len(text) > 2 && strings.ContainsRune("-*+", rune(text[0])) && text[1] == ' ' {
+			text = text[2:]
+		} else if dot := strings.Index(text, ". "); dot > 0 && dot <= 3 && strings.Trim(text[:dot], "0123456789") == "" {
+			text = text[dot+2:]
+		}
+		text = taskBoxes.Replace(text)
+		if cut := strings.IndexAny(text, "[<!|"); cut != -1 {
+			text = text[:cut]
+		}
+		text = plainTaskText(text)
+	}
+
+	needle := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if len(needle) > 20 {
+		needle = strings.TrimSpace(needle[:20])
+	}
+	return needle
+}
+
+// mapOffset converts a scroll offset between the panes of the split view.
+// from and to hold the row of every source line in each pane; offsets
+// between two lines are interpolated.
+func mapOffset(offset int, from, to []int) int {
+	if len(from) == 0 || len(from) != len(to) {
+		return 0
+	}
+
+	next := sort.SearchInts(from, offset+1) // first line below the offset
+	i := next - 1
+	if i < 0 {
+		return 0
+	}
+	for i > 0 && from[i-1] == from[i] {
+		i--
+	}
+	if next >= len(from) {
+		return to[i] + offset - from[i]
+	}
+	return to[i] + (offset-from[i])*(to[next]-to[i])/(from[next]-from[i])
+}
+
+// syncSourcePane scrolls the source pane to match the rendered view
+func (m *Model) syncSourcePane() {
+	if m.splitMode && m.lineMap != nil {
+		m.sourcePane.SetYOffset(mapOffset(m.viewport.YOffset, m.lineMap, m.paneRows))
+	}
+}
+
+// syncRenderedPane scrolls the rendered view to match the source pane
+func (m *Model) syncRenderedPane() {
+	if m.splitMode && m.lineMap != nil {
+		m.viewport.SetYOffset(mapOffset(m.sourcePane.YOffset, m.paneRows, m.lineMap))
+	}
+}
+
+// handleSplitKey handles the split view keys: switching panes, and
+// scrolling while the source pane is focused. Returns false for other keys.
+func (m *Model) handleSplitKey(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "h", "left":
+		m.sourceFocused = true
+		return true
+	case "l", "right":
+		m.sourceFocused = false
+		return true
+	}
+
+	if !m.sourceFocused || m.taskMode {
+		return false
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.sourcePane.LineUp(1)
+	case "down", "j":
+		m.sourcePane.LineDown(1)
+	case "pgup", "ctrl+u":
+		m.sourcePane.HalfViewUp()
+	case "pgdown", "ctrl+d":
+		m.sourcePane.HalfViewDown()
+	case "g", "home":
+		m.sourcePane.GotoTop()
+	case "G", "end":
+		m.sourcePane.GotoBottom()
+	default:
+		return false
+	}
+	m.syncRenderedPane()
+	return true
+}
+
+// viewSplit joins the source and rendered panes side by side
+func (m Model) viewSplit() string {
+	width := m.sourceWidth()
+	left := strings.Split(m.sourcePane.View(), "\n")
+	right := strings.Split(m.viewportView(), "\n")
+	divider := styles.SplitDividerStyle.Render("│")
+
+	rows := make([]string, max(len(left), len(right)))
+	for i := range rows {
+		var l, r string
+		if i < len(left) {
+			l = ansi.Truncate(left[i], width, "")
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		rows[i] = l + strings.Repeat(" ", max(0, width-ansi.StringWidth(l))) + divider + r
+	}
+	return strings.Join(rows, "\n")
+}
+
+// IsSplitMode returns whether the source is shown beside the rendered view
+func (m Model) IsSplitMode() bool {
+	return m.splitMode
+}
+
+// IsSourceFocused returns whether scroll keys move the source pane of the split view
+func (m Model) IsSourceFocused() bool {
+	return m.splitMode && m.sourceFocused
+}
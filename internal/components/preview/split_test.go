@@ -0,0 +1,98 @@
+package preview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineNeedle(t *testing.T) {
+	tests := []struct {
+		line string
+		code bool
+		want string
+	}{
+		{"# Getting Started", false, "getting started"},
+		{"> Quoted   text", false, "quoted text"},
+		{"- item one", false, "item one"},
+		{"12. twelfth step", false, "twelfth step"},
+		{"- [x] done task", false, "done task"},
+		{"See [the docs](x.md) first", false, "see"},
+		{"Text <br> more", false, "text"},
+		{"A very long paragraph line that goes on", false, "a very long paragrap"},
+		{"  - not a list in code", true, "- not a list in code"},
+		{"| a | b |", false, "a"},
+	}
+	for _, tt := range tests {
+		if got := lineNeedle(tt.line, tt.code); got != tt.want {
+			t.Errorf("lineNeedle(%q, %v) = %q, want %q", tt.line, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestBuildLineMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		rendered string
+		skip     int
+		want     []int
+	}{
+		{
+			name:     "matched lines and blank lines between them",
+			raw:      "# Title\n\nFirst paragraph\n\n- item",
+			rendered: "\n  TITLE\n\n  First paragraph\n\n  • item\n",
+			want:     []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "code blocks match their content",
+			raw:      "Intro text\n```go\nfmt.Println(1)\n```\nOutro text",
+			rendered: "  Intro text\n\n    fmt.Println(1)\n\n  Outro text",
+			want:     []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:     "front matter maps to the top",
+			raw:      "---\ntitle: x\n---\nBody text",
+			rendered: "  title  x\n\n  Body text",
+			skip:     3,
+			want:     []int{0, 0, 0, 2},
+		},
+		{
+			name:     "paragraph lines joined into one row",
+			raw:      "first half of a\nparagraph continues\n\nNext one",
+			rendered: "  first half of a paragraph continues\n\n  Next one",
+			want:     []int{0, 0, 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildLineMap(tt.raw, tt.rendered, tt.skip); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildLineMap = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapOffset(t *testing.T) {
+	source := []int{0, 1, 2, 5}
+	rendered := []int{0, 4, 4, 10}
+
+	tests := []struct {
+		offset   int
+		from, to []int
+		want     int
+	}{
+		{0, source, rendered, 0},
+		{1, source, rendered, 4},
+		{3, source, rendered, 6},  // between lines 2 and 3
+		{7, source, rendered, 12}, // past the last line
+		{4, rendered, source, 1},  // lines 1 and 2 share a row; the first wins
+		{7, rendered, source, 3},
+		{3, nil, nil, 0},
+		{3, source, rendered[:2], 0},
+	}
+	for _, tt := range tests {
+		if got := mapOffset(tt.offset, tt.from, tt.to); got != tt.want {
+			t.Errorf("mapOffset(%d, %v, %v) = %d, want %d", tt.offset, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package preview
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxCellWidth keeps one long field from pushing the other columns off screen
+const maxCellWidth = 40
+
+// tableSeparator separates the columns of a table
+const tableSeparator = " │ "
+
+// cellText flattens a field to one line
+var cellText = strings.NewReplacer("\r\n", "↵", "\n", "↵", "\t", " ")
+
+// renderTable lays out the table with aligned columns, sorted by the
+// selected column. The header row and its rule are kept in tableHeader,
+// shown above the scrolling rows. It returns the rows and the row each
+// source line belongs to.
+func (m *Model) renderTable() (string, []int) {
+	t := m.table
+	columns := len(t.Header)
+	if columns == 0 {
+		m.tableHeader = ""
+		return "\n  " + styles.DataNullStyle.Render("Empty table"), nil
+	}
+
+	// Size each column to its widest cell; numeric columns align right
+	header := make([]string, columns)
+	widths := make([]int, columns)
+	numeric := make([]bool, columns)
+	for c, name := range t.Header {
+		header[c] = cellText.Replace(name)
+		if c == m.sortColumn {
+			header[c] += sortArrow(m.sortDesc)
+		}
+		widths[c] = ansi.StringWidth(header[c])
+		numeric[c] = len(t.Rows) > 0
+	}
+	for _, row := range t.Rows {
+		for c, cell := range row {
+			widths[c] = max(widths[c], ansi.StringWidth(cellText.Replace(cell)))
+			if cell != "" && !isNumber(cell) {
+				numeric[c] = false
+			}
+		}
+	}
+
+	m.columnStarts = make([]int, columns)
+	pos := 2 // margin
+	for c := range widths {
+		widths[c] = min(widths[c], maxCellWidth)
+		m.columnStarts[c] = pos
+		pos += widths[c] + len(tableSeparator)
+	}
+
+	separator := styles.TableRuleStyle.Render(tableSeparator)
+	layout := func(cells []string, style func(string) string) string {
+		parts := make([]string, columns)
+		for c, cell := range cells {
+			text := ansi.Truncate(cellText.Replace(cell), widths[c], "…")
+			padding := strings.Repeat(" ", widths[c]-ansi.StringWidth(text))
+			text = style(text)
+			if numeric[c] {
+				parts[c] = padding + text
+			} else {
+				parts[c] = text + padding
+			}
+		}
+		return "  " + strings.Join(parts, separator)
+	}
+
+	rules := make([]string, columns)
+	for c, w := range widths {
+		rules[c] = strings.Repeat("─", w)
+	}
+	m.tableHeader = layout(header, func(text string) string { return styles.TableHeaderStyle.Render(text) }) + "\n" +
+		styles.TableRuleStyle.Render("  "+strings.Join(rules, "─┼─"))
+
+	m.sortRows()
+	lines := make([]string, len(m.rowOrder))
+	displayRow := make([]int, len(t.Rows))
+	for i, r := range m.rowOrder {
+		displayRow[r] = i
+		lines[i] = layout(t.Rows[r], func(text string) string { return text })
+	}
+
+	// Each record covers the source lines up to the next one; the header
+	// maps to the first row
+	rows := make([]int, strings.Count(m.rawContent, "\n")+1)
+	for r, start := range t.Lines {
+		end := len(rows)
+		if r+1 < len(t.Lines) {
+			end = t.Lines[r+1]
+		}
+		for line := start; line < end && line < len(rows); line++ {
+			rows[line] = displayRow[r]
+		}
+	}
+	return strings.Join(lines, "\n"), rows
+}
+
+// sortRows orders the table rows by the sort column, keeping file order
+// for equal values. Empty cells sort last in either direction.
+func (m *Model) sortRows() {
+	m.rowOrder = make([]int, len(m.table.Rows))
+	for i := range m.rowOrder {
+		m.rowOrder[i] = i
+	}
+	if m.sortColumn < 0 || m.sortColumn >= len(m.table.Header) {
+		return
+	}
+
+	rows, column := m.table.Rows, m.sortColumn
+	sort.SliceStable(m.rowOrder, func(a, b int) bool {
+		x, y := rows[m.rowOrder[a]][column], rows[m.rowOrder[b]][column]
+		if (x == "") != (y == "") {
+			return y == ""
+		}
+		if m.sortDesc {
+			return compareCells(y, x) < 0
+		}
+		return compareCells(x, y) < 0
+	})
+}
+
+// sortArrow marks the sort column with its direction
+func sortArrow(desc bool) string {
+	if desc {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// compareCells compares two fields as numbers when both are, and as
+// case-insensitive text otherwise
+func compareCells(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// isNumber reports whether a field holds a number
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+// cycleSort sorts the table by the next column, returning to file order
+// after the last one
+func (m *Model) cycleSort() {
+	m.sortColumn++
+	if m.sortColumn >= len(m.table.Header) {
+		m.sortColumn = -1
+	}
+	m.sortDesc = false
+	m.rerenderData()
+}
+
+// reverseSort flips the sort direction, sorting by the first column if
+// the table isn't sorted yet
+func (m *Model) reverseSort() {
+	if m.sortColumn < 0 {
+		m.sortColumn = 0
+	}
+	m.sortDesc = !m.sortDesc
+	m.rerenderData()
+}
+
+// sortByColumn sorts by a column, reversing the order when it is already
+// the sort column
+func (m *Model) sortByColumn(column int) {
+	if column == m.sortColumn {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortColumn, m.sortDesc = column, false
+	}
+	m.rerenderData()
+}
+
+// columnAt returns the table column shown at column x of the preview, or -1
+func (m Model) columnAt(x int) int {
+	x += m.xOffset
+	column := -1
+	for c, start := range m.columnStarts {
+		if x >= start-1 {
+			column = c
+		}
+	}
+	return column
+}
+
+// tableWidth returns the width of the widest table line
+func (m Model) tableWidth() int {
+	width := 0
+	for _, line := range strings.Split(m.tableHeader, "\n") {
+		width = max(width, ansi.StringWidth(line))
+	}
+	return width
+}
+
+// scrollColumns scrolls the table horizontally by whole columns, stopping
+// once the last column is in view
+func (m *Model) scrollColumns(delta int) {
+	maxOffset := max(0, m.tableWidth()-m.contentWidth())
+	offset := m.xOffset
+	if delta > 0 {
+		for _, start := range m.columnStarts {
+			if start-2 > m.xOffset {
+				offset = start - 2
+				break
+			}
+		}
+	} else {
+		offset = 0
+		for _, start := range m.columnStarts {
+			if start-2 < m.xOffset {
+				offset = start - 2
+			}
+		}
+	}
+	m.xOffset = max(0, min(offset, maxOffset))
+	m.refreshContent()
+}
+
+// revealColumn scrolls the table horizontally so the first match of the
+// search query on a row is in view
+func (m *Model) revealColumn(row int) {
+	lines := strings.Split(m.renderedContent, "\n")
+	if row < 0 || row >= len(lines) {
+		return
+	}
+	line := strings.ToLower(stripANSI(lines[row]))
+	idx := strings.Index(line, strings.ToLower(m.searchQuery))
+	if idx == -1 {
+		return
+	}
+
+	start := ansi.StringWidth(line[:idx])
+	end := start + ansi.StringWidth(m.searchQuery)
+	if start >= m.xOffset && end <= m.xOffset+m.contentWidth() {
+		return
+	}
+	offset := 0
+	for _, s := range m.columnStarts {
+		if s-2 <= start {
+			offset = s - 2
+		}
+	}
+	m.xOffset = max(0, min(offset, m.tableWidth()-m.contentWidth()))
+	m.refreshContent()
+}
+
+// cutColumns cuts the horizontally scrolled part of the table out of each line
+func cutColumns(content string, offset, width int) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Cut(line, offset, offset+width)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,242 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+	"github.com/Ayushlm10/skim/internal/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Task is a GitHub-style task list item (- [ ] / - [x]) in the raw markdown
+type Task struct {
+	// Line is the 0-based line number in the raw content
+	Line int
+
+	// Checked indicates if the box is ticked
+	Checked bool
+
+	// Text is the item text after the checkbox
+	Text string
+
+	// boxOffset is the byte offset of the character inside the brackets
+	boxOffset int
+}
+
+// TaskToggledMsg is sent after a task checkbox has been written back to disk
+type TaskToggledMsg struct {
+	Path  string
+	Line  int
+	Error error
+}
+
+// taskPattern matches list items with a checkbox: indent, marker, space, [ ], space
+var taskPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])\](?:\s|$)`)
+
+// parseTasks finds all task list items in raw markdown, skipping fenced code blocks
+func parseTasks(content string) []Task {
+	var tasks []Task
+	var fence mdlinks.Fence
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		// Track fenced code blocks so examples aren't treated as tasks
+		if fence.Scan(line) || fence.Open() {
+			continue
+		}
+
+		loc := taskPattern.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+
+		boxOffset := loc[4]
+		tasks = append(tasks, Task{
+			Line:      i,
+			Checked:   line[boxOffset] != ' ',
+			Text:      strings.TrimSpace(line[loc[1]:]),
+			boxOffset: boxOffset,
+		})
+	}
+
+	return tasks
+}
+
+// errTaskMoved is returned when the file on disk no longer has the task on the expected line
+var errTaskMoved = errors.New("file changed on disk, reload and try again")
+
+// ToggleTask creates a command that flips a single checkbox in the file on disk.
+// Only the character inside the brackets is rewritten; every other byte is
+// preserved. The line must still hold the same task, text and state included,
+// and the file is replaced in one rename.
+func ToggleTask(path string, task Task) tea.Cmd {
+	return func() tea.Msg {
+		info, err := os.Stat(path)
+		if err != nil {
+			return TaskToggledMsg{Path: path, Line: task.Line, Error: err}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return TaskToggledMsg{Path: path, Line: task.Line, Error: err}
+		}
+
+		// Locate the start of the task's line
+		start := 0
+		for n := 0; n < task.Line; n++ {
+			idx := bytes.IndexByte(content[start:], '\n')
+			if idx == -1 {
+				return TaskToggledMsg{Path: path, Line: task.Line, Error: errTaskMoved}
+			}
+			start += idx + 1
+		}
+		end := len(content)
+		if idx := bytes.IndexByte(content[start:], '\n'); idx != -1 {
+			end = start + idx
+		}
+
+		// Verify the line is still the same task before touching it
+		line := strings.TrimSuffix(string(content[start:end]), "\r")
+		loc := taskPattern.FindStringSubmatchIndex(line)
+		if loc == nil || loc[4] != task.boxOffset ||
+			(line[loc[4]] != ' ') != task.Checked || strings.TrimSpace(line[loc[1]:]) != task.Text {
+			return TaskToggledMsg{Path: path, Line: task.Line, Error: errTaskMoved}
+		}
+
+		pos := start + task.boxOffset
+		if content[pos] == ' ' {
+			content[pos] = 'x'
+		} else {
+			content[pos] = ' '
+		}
+
+		if err := fsutil.WriteFile(path, content, info.Mode().Perm()); err != nil {
+			return TaskToggledMsg{Path: path, Line: task.Line, Error: err}
+		}
+
+		return TaskToggledMsg{Path: path, Line: task.Line}
+	}
+}
+
+// handleTaskKey handles keys while task selection is active.
+// Returns false for keys that should fall through to normal preview handling.
+func (m *Model) handleTaskKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.currentTask > 0 {
+			m.currentTask--
+			m.refreshContent()
+			m.scrollToCurrentTask()
+		}
+		return true, nil
+
+	case "down", "j":
+		if m.currentTask < len(m.tasks)-1 {
+			m.currentTask++
+			m.refreshContent()
+			m.scrollToCurrentTask()
+		}
+		return true, nil
+
+	case " ":
+		if m.currentTask < len(m.tasks) && m.filePath != "" {
+			return true, ToggleTask(m.filePath, m.tasks[m.currentTask])
+		}
+		return true, nil
+
+	case "t", "esc":
+		m.taskMode = false
+		m.refreshContent()
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// scrollToCurrentTask keeps the selected task in view
+func (m *Model) scrollToCurrentTask() {
+	line := m.renderedTaskLine()
+	if line < 0 {
+		return
+	}
+
+	top := m.viewport.YOffset
+	bottom := top + m.viewport.VisibleLineCount()
+	if line < top || line >= bottom {
+		m.centerOnLine(line)
+	}
+}
+
+// renderedTaskLine finds the rendered line showing the selected task
+func (m Model) renderedTaskLine() int {
+	if m.currentTask < 0 || m.currentTask >= len(m.tasks) {
+		return -1
+	}
+	task := m.tasks[m.currentTask]
+	return m.findRenderedLine(task.Line, plainTaskText(task.Text))
+}
+
+// findRenderedLine finds the rendered line showing text from a raw line.
+// It searches for the text near the ratio-based estimate, falling back to the estimate.
+func (m Model) findRenderedLine(rawLine int, text string) int {
+	estimate := m.estimateRenderedLine(rawLine)
+	if m.sourceRows != nil {
+		return estimate
+	}
+
+	needle := strings.ToLower(text)
+	if len(needle) > 24 {
+		needle = needle[:24]
+	}
+	if needle == "" {
+		return estimate
+	}
+
+	best := -1
+	for i, line := range strings.Split(m.renderedContent, "\n") {
+		if !strings.Contains(strings.ToLower(stripANSI(line)), needle) {
+			continue
+		}
+		if best == -1 || abs(i-estimate) < abs(best-estimate) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return estimate
+	}
+	return best
+}
+
+// markdownInline matches the inline markup that glamour hides when rendering
+var markdownInline = regexp.MustCompile("[*_`~]|\\]\\([^)]*\\)|\\[")
+
+// plainTaskText strips inline markdown so task text can be matched against rendered output
+func plainTaskText(text string) string {
+	return strings.TrimSpace(markdownInline.ReplaceAllString(text, ""))
+}
+
+// markTaskLine replaces the left margin of a rendered line with a selection marker
+func markTaskLine(content string, line int) string {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return content
+	}
+
+	marker := styles.TaskMarkerStyle.Render(styles.TaskMarker + " ")
+	lines[line] = marker + ansi.TruncateLeft(lines[line], 2, "")
+	return strings.Join(lines, "\n")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package preview
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTasks(t *testing.T) {
+	content := "- [ ] one\n* [x] two\n  1. [X] three\n- [] no\n```\n- [ ] code\n```\n+ [ ]\n-  [ ]  spaced  "
+	want := []Task{
+		{Line: 0, Text: "one", boxOffset: 3},
+		{Line: 1, Checked: true, Text: "two", boxOffset: 3},
+		{Line: 2, Checked: true, Text: "three", boxOffset: 6},
+		{Line: 7, Text: "", boxOffset: 3},
+		{Line: 8, Text: "spaced", boxOffset: 4},
+	}
+	if got := parseTasks(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTasks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	const original = "# Deploy\n\n- [ ] deploy staging\r\n- [x] deploy prod\n"
+	tests := []struct {
+		name    string
+		ondisk  string // file content when the toggle runs
+		task    int    // index into the tasks parsed from original
+		want    string
+		changed bool
+	}{
+		{"tick", original, 0, "# Deploy\n\n- [x] deploy staging\r\n- [x] deploy prod\n", false},
+		{"untick", original, 1, "# Deploy\n\n- [ ] deploy staging\r\n- [ ] deploy prod\n", false},
+		{"line inserted above", "# Deploy\n\n- [ ] rollback\n- [ ] deploy staging\r\n- [x] deploy prod\n", 0, "", true},
+		{"other text at the same offset", "# Deploy\n\n* [ ] deploy prod\n", 0, "", true},
+		{"toggled elsewhere", "# Deploy\n\n- [x] deploy staging\r\n- [x] deploy prod\n", 0, "", true},
+		{"file shortened", "# Deploy\n", 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.md")
+			if err := os.WriteFile(path, []byte(tt.ondisk), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			msg := ToggleTask(path, parseTasks(original)[tt.task])().(TaskToggledMsg)
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.changed {
+				if msg.Error != errTaskMoved {
+					t.Errorf("error = %v, want %v", msg.Error, errTaskMoved)
+				}
+				if string(got) != tt.ondisk {
+					t.Errorf("file = %q, want it untouched", got)
+				}
+				return
+			}
+			if msg.Error != nil {
+				t.Fatalf("error = %v", msg.Error)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+			if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+				t.Errorf("mode = %v, %v; want 0600 kept", info.Mode().Perm(), err)
+			}
+		})
+	}
+}
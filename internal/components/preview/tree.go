@@ -0,0 +1,248 @@
+package preview
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// treeNode is a node of a data tree with its place in the tree
+type treeNode struct {
+	node  *format.Node
+	path  string // keys and indexes from the root, which survive reloads
+	label string // key or list index shown before the value
+	depth int
+}
+
+// eachTreeNode calls fn for every node below the root in document order.
+// fn returns false to skip the children of a node.
+func eachTreeNode(root *format.Node, fn func(treeNode) bool) {
+	var walk func(n *format.Node, path string, depth int)
+	walk = func(n *format.Node, path string, depth int) {
+		for i, child := range n.Children {
+			label := child.Key
+			if n.Kind == format.List {
+				label = "[" + strconv.Itoa(i) + "]"
+			}
+			childPath := path + "\x00" + label
+			if fn(treeNode{node: child, path: childPath, label: label, depth: depth}) {
+				walk(child, childPath, depth+1)
+			}
+		}
+	}
+	walk(root, "", 0)
+}
+
+// isContainer reports whether a node is a map or list with entries to fold
+func isContainer(n *format.Node) bool {
+	return n.Kind != format.Scalar && len(n.Children) > 0
+}
+
+// renderTree renders the data tree one entry per line, with folded maps and
+// lists summarised on their key's line. It returns the content and the row
+// each source line belongs to; lines inside folded nodes belong to the
+// node's row.
+func (m *Model) renderTree() (string, []int) {
+	width := max(m.contentWidth()-2, 20)
+	rowOf := make(map[*format.Node]int)
+	var lines []string
+	m.treeRows = nil
+
+	if m.tree.Kind == format.Scalar || len(m.tree.Children) == 0 {
+		lines = append(lines, "  "+treeValue(m.tree, false))
+		m.treeRows = append(m.treeRows, "")
+	}
+
+	folded, foldedRow := "", 0
+	eachTreeNode(m.tree, func(t treeNode) bool {
+		if folded != "" && strings.HasPrefix(t.path, folded+"\x00") {
+			// Hidden inside a folded node
+			rowOf[t.node] = foldedRow
+			return true
+		}
+		folded = ""
+
+		marker := "  "
+		if isContainer(t.node) {
+			marker = "▾ "
+			if m.folded[t.path] {
+				marker = "▸ "
+				folded, foldedRow = t.path, len(lines)
+			}
+		}
+		label := styles.DataKeyStyle.Render(t.label)
+		if strings.HasPrefix(t.label, "[") {
+			label = styles.DataPunctStyle.Render(t.label)
+		}
+		prefix := "  " + strings.Repeat("  ", t.depth) + styles.DataPunctStyle.Render(marker) + label + styles.DataPunctStyle.Render(": ")
+		indent := strings.Repeat(" ", ansi.StringWidth(prefix))
+
+		rowOf[t.node] = len(lines)
+		value := treeValue(t.node, m.folded[t.path])
+		for i, part := range strings.Split(ansi.Wrap(value, max(width-len(indent), 10), ""), "\n") {
+			if i == 0 {
+				lines = append(lines, prefix+part)
+				if isContainer(t.node) {
+					m.treeRows = append(m.treeRows, t.path)
+				} else {
+					m.treeRows = append(m.treeRows, "")
+				}
+				continue
+			}
+			lines = append(lines, indent+part)
+			m.treeRows = append(m.treeRows, "")
+		}
+		return true
+	})
+
+	// Map each source line to the row of the last entry starting at or above it
+	rows := make([]int, strings.Count(m.rawContent, "\n")+1)
+	for i := range rows {
+		rows[i] = -1
+	}
+	set := func(n *format.Node, row int) {
+		if n.Line >= 0 && n.Line < len(rows) && rows[n.Line] == -1 {
+			rows[n.Line] = row
+		}
+	}
+	set(m.tree, 0)
+	eachTreeNode(m.tree, func(t treeNode) bool {
+		set(t.node, rowOf[t.node])
+		return true
+	})
+	last := 0
+	for i, row := range rows {
+		if row == -1 {
+			rows[i] = last
+		}
+		last = rows[i]
+	}
+
+	return "\n" + strings.Join(lines, "\n"), shiftRows(rows, 1)
+}
+
+// treeValue renders a scalar colored by its type, or a summary of a map or list
+func treeValue(n *format.Node, folded bool) string {
+	switch n.Kind {
+	case format.Map:
+		summary := countLabel(len(n.Children), "key")
+		if len(n.Children) == 0 {
+			return styles.DataPunctStyle.Render("{}")
+		}
+		if folded {
+			return styles.DataPunctStyle.Render("{…} " + summary)
+		}
+		return styles.DataPunctStyle.Render(summary)
+	case format.List:
+		summary := countLabel(len(n.Children), "item")
+		if len(n.Children) == 0 {
+			return styles.DataPunctStyle.Render("[]")
+		}
+		if folded {
+			return styles.DataPunctStyle.Render("[…] " + summary)
+		}
+		return styles.DataPunctStyle.Render(summary)
+	}
+
+	switch n.Type {
+	case format.Number:
+		return styles.DataNumberStyle.Render(n.Value)
+	case format.Bool:
+		return styles.DataBoolStyle.Render(n.Value)
+	case format.Null:
+		return styles.DataNullStyle.Render(n.Value)
+	}
+	return styles.DataStringStyle.Render(strconv.Quote(n.Value))
+}
+
+// countLabel formats a count, e.g. "1 key" or "4 items"
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// shiftRows moves every row down by n
+func shiftRows(rows []int, n int) []int {
+	for i := range rows {
+		rows[i] += n
+	}
+	return rows
+}
+
+// toggleFold folds or unfolds the map or list at a path
+func (m *Model) toggleFold(path string) {
+	if m.folded == nil {
+		m.folded = make(map[string]bool)
+	}
+	if m.folded[path] {
+		delete(m.folded, path)
+	} else {
+		m.folded[path] = true
+	}
+	m.rerenderData()
+}
+
+// foldToDepth folds every map and list at depth levels below the top, or
+// unfolds everything when depth is 0
+func (m *Model) foldToDepth(depth int) {
+	m.folded = make(map[string]bool)
+	if depth > 0 {
+		eachTreeNode(m.tree, func(t treeNode) bool {
+			if isContainer(t.node) && t.depth >= depth {
+				m.folded[t.path] = true
+				return false
+			}
+			return true
+		})
+	}
+	m.rerenderData()
+}
+
+// foldAtTop folds or unfolds the first map or list shown in the view
+func (m *Model) foldAtTop() {
+	for row := m.viewport.YOffset; row < len(m.treeRows)+1 && row < m.viewport.YOffset+m.viewport.Height; row++ {
+		if path := m.treeRow(row); path != "" {
+			m.toggleFold(path)
+			return
+		}
+	}
+}
+
+// treeRow returns the path of the foldable node on a rendered row, or ""
+func (m Model) treeRow(row int) string {
+	row-- // blank first line
+	if row < 0 || row >= len(m.treeRows) {
+		return ""
+	}
+	return m.treeRows[row]
+}
+
+// revealTreeLine unfolds the maps and lists hiding a source line
+func (m *Model) revealTreeLine(line int) {
+	// The last entry starting at or above the line holds it; chain is that
+	// entry and its ancestors
+	var chain []treeNode
+	eachTreeNode(m.tree, func(t treeNode) bool {
+		if t.node.Line > line {
+			return false
+		}
+		chain = append(chain[:t.depth], t)
+		return true
+	})
+
+	changed := false
+	for _, t := range chain {
+		if t.node.Line < line && m.folded[t.path] {
+			delete(m.folded, t.path)
+			changed = true
+		}
+	}
+	if changed {
+		m.rerenderData()
+	}
+}
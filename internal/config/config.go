@@ -0,0 +1,88 @@
+// Package config persists user preferences between sessions
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+)
+
+// Config holds preferences saved in the user's config directory
+type Config struct {
+	// Layout is the panel arrangement: "auto", "side" or "stacked"
+	Layout string `json:"layout,omitempty"`
+
+	// TreeRatio is the file tree's share of the split (0 uses the default)
+	TreeRatio float64 `json:"treeRatio,omitempty"`
+
+	// TreeHidden hides the file tree panel
+	TreeHidden bool `json:"treeHidden,omitempty"`
+
+	// Extensions limits the tree to these document extensions, e.g.
+	// [".md", ".rst"] (empty shows every supported format)
+	Extensions []string `json:"extensions,omitempty"`
+
+	// Images picks how images are drawn: "auto" (the default) detects the
+	// terminal, or "kitty", "iterm", "sixel", "blocks" or "off"
+	Images string `json:"images,omitempty"`
+
+	// Overflow picks how code blocks and tables wider than the preview are
+	// shown: "wrap" (the default), "blocks" to scroll them sideways on
+	// their own, or "document" to scroll the whole document with them
+	Overflow string `json:"overflow,omitempty"`
+
+	// Reading keeps the preview's rendered text to a column no wider than
+	// MaxWidth, centered in the panel
+	Reading bool `json:"reading,omitempty"`
+
+	// MaxWidth is the widest the text column gets in reading mode (0 uses
+	// 100 columns)
+	MaxWidth int `json:"maxWidth,omitempty"`
+}
+
+// Path returns the location of the config file
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "skim", "config.json"), nil
+}
+
+// Load reads the config file. A missing or unreadable file gives the defaults.
+func Load() Config {
+	var c Config
+
+	path, err := Path()
+	if err != nil {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Config{}
+	}
+	return c
+}
+
+// Save writes the config file atomically, creating its directory if needed
+func Save(c Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, append(data, '\n'), 0o644)
+}
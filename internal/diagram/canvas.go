@@ -0,0 +1,220 @@
+package diagram
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Directions a line leaves a cell in
+const (
+	up uint8 = 1 << iota
+	down
+	left
+	right
+)
+
+// lineStyle is how an edge is drawn
+type lineStyle int
+
+const (
+	solid lineStyle = iota
+	dotted
+	thick
+)
+
+// cell is one character of a drawing. Lines crossing a cell are merged
+// into the right box-drawing character; an explicit character wins.
+type cell struct {
+	ch    rune // explicit character (0 for none, -1 for the tail of a wide one)
+	lines uint8
+	style lineStyle
+}
+
+// canvas is a grid of cells that grows as it is drawn on
+type canvas struct {
+	rows [][]cell
+}
+
+// at returns the cell at (x, y), growing the canvas to hold it
+func (c *canvas) at(x, y int) *cell {
+	for len(c.rows) <= y {
+		c.rows = append(c.rows, nil)
+	}
+	for len(c.rows[y]) <= x {
+		c.rows[y] = append(c.rows[y], cell{})
+	}
+	return &c.rows[y][x]
+}
+
+// put sets the character at (x, y)
+func (c *canvas) put(x, y int, r rune) {
+	if x < 0 || y < 0 {
+		return
+	}
+	c.at(x, y).ch = r
+}
+
+// text writes a string from (x, y), with wide characters taking two cells
+func (c *canvas) text(x, y int, s string) {
+	for _, r := range s {
+		c.put(x, y, r)
+		if ansi.StringWidth(string(r)) == 2 {
+			c.put(x+1, y, -1)
+			x++
+		}
+		x++
+	}
+}
+
+// link adds line directions to the cell at (x, y)
+func (c *canvas) link(x, y int, dirs uint8, style lineStyle) {
+	if x < 0 || y < 0 {
+		return
+	}
+	cl := c.at(x, y)
+	cl.lines |= dirs
+	cl.style = style
+}
+
+// hline draws a horizontal line between two columns, inclusive
+func (c *canvas) hline(x0, x1, y int, style lineStyle) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	for x := x0; x <= x1; x++ {
+		var dirs uint8
+		if x > x0 || x0 == x1 {
+			dirs |= left
+		}
+		if x < x1 || x0 == x1 {
+			dirs |= right
+		}
+		c.link(x, y, dirs, style)
+	}
+}
+
+// vline draws a vertical line between two rows, inclusive
+func (c *canvas) vline(x, y0, y1 int, style lineStyle) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	for y := y0; y <= y1; y++ {
+		var dirs uint8
+		if y > y0 || y0 == y1 {
+			dirs |= up
+		}
+		if y < y1 || y0 == y1 {
+			dirs |= down
+		}
+		c.link(x, y, dirs, style)
+	}
+}
+
+// border is the set of characters a box is drawn with
+type border struct {
+	topLeft, top, topRight, side, bottomLeft, bottomRight rune
+}
+
+var (
+	squareBorder  = border{'┌', '─', '┐', '│', '└', '┘'}
+	roundBorder   = border{'╭', '─', '╮', '│', '╰', '╯'}
+	doubleBorder  = border{'╔', '═', '╗', '║', '╚', '╝'}
+	diamondBorder = border{'╱', '─', '╲', '│', '╲', '╱'}
+)
+
+// box draws a box of w by h cells at (x, y) with a label centered inside
+func (c *canvas) box(x, y, w, h int, b border, label string) {
+	bottom := b.top
+	if b == doubleBorder {
+		bottom = '═'
+	}
+	for i := 1; i < w-1; i++ {
+		c.put(x+i, y, b.top)
+		c.put(x+i, y+h-1, bottom)
+	}
+	for j := 1; j < h-1; j++ {
+		c.put(x, y+j, b.side)
+		c.put(x+w-1, y+j, b.side)
+		for i := 1; i < w-1; i++ {
+			c.put(x+i, y+j, ' ')
+		}
+	}
+	c.put(x, y, b.topLeft)
+	c.put(x+w-1, y, b.topRight)
+	c.put(x, y+h-1, b.bottomLeft)
+	c.put(x+w-1, y+h-1, b.bottomRight)
+
+	for j, line := range strings.Split(label, "\n") {
+		c.text(x+(w-ansi.StringWidth(line))/2, y+1+j, line)
+	}
+}
+
+// lineChars maps line directions to characters for each style
+var lineChars = map[lineStyle]map[uint8]rune{
+	solid: {
+		up | down: '│', left | right: '─',
+		down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+		up | down | right: '├', up | down | left: '┤',
+		down | left | right: '┬', up | left | right: '┴',
+		up | down | left | right: '┼',
+	},
+	thick: {
+		up | down: '┃', left | right: '━',
+		down | right: '┏', down | left: '┓', up | right: '┗', up | left: '┛',
+		up | down | right: '┣', up | down | left: '┫',
+		down | left | right: '┳', up | left | right: '┻',
+		up | down | left | right: '╋',
+	},
+}
+
+// lineChar returns the character for the lines crossing a cell
+func lineChar(cl cell) rune {
+	dirs := cl.lines
+	switch dirs {
+	case up, down:
+		dirs = up | down
+	case left, right:
+		dirs = left | right
+	}
+	if cl.style == dotted {
+		switch dirs {
+		case up | down:
+			return '┆'
+		case left | right:
+			return '┄'
+		}
+	}
+	chars := lineChars[cl.style]
+	if chars == nil {
+		chars = lineChars[solid]
+	}
+	if r, ok := chars[dirs]; ok {
+		return r
+	}
+	return ' '
+}
+
+// lines returns the drawing, without trailing spaces or blank last rows
+func (c *canvas) lines() []string {
+	out := make([]string, 0, len(c.rows))
+	for _, row := range c.rows {
+		var b strings.Builder
+		for _, cl := range row {
+			switch {
+			case cl.ch == -1:
+			case cl.ch != 0:
+				b.WriteRune(cl.ch)
+			case cl.lines != 0:
+				b.WriteRune(lineChar(cl))
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		out = append(out, strings.TrimRight(b.String(), " "))
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+// Package diagram draws mermaid flowcharts and sequence diagrams, and
+// subsets of Graphviz dot and PlantUML, as box-drawing text
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// UnsupportedError reports a diagram type that can't be drawn as text
+type UnsupportedError struct {
+	Kind string // e.g. "mermaid pie"
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Kind + " diagrams can't be drawn as text"
+}
+
+// Supported reports whether a fenced code block language is a diagram
+func Supported(lang string) bool {
+	switch strings.ToLower(lang) {
+	case "mermaid", "dot", "graphviz", "plantuml", "puml":
+		return true
+	}
+	return false
+}
+
+// Render draws the diagram in a fenced code block of the given language.
+// It returns the lines of the drawing, or an error naming what isn't
+// supported.
+func Render(lang, source string) ([]string, error) {
+	switch strings.ToLower(lang) {
+	case "mermaid":
+		return renderMermaid(source)
+	case "dot", "graphviz":
+		g, err := parseDot(source)
+		if err != nil {
+			return nil, err
+		}
+		return g.draw(), nil
+	case "plantuml", "puml":
+		d, err := parsePlantUML(source)
+		if err != nil {
+			return nil, err
+		}
+		return d.draw(), nil
+	}
+	return nil, &UnsupportedError{Kind: lang}
+}
+
+// mermaidHeader matches the diagram type on the first line of a mermaid block
+var mermaidHeader = regexp.MustCompile(`^\s*([A-Za-z][\w-]*)`)
+
+// renderMermaid draws a mermaid flowchart or sequence diagram
+func renderMermaid(source string) ([]string, error) {
+	kind := ""
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%%") || line == "---" {
+			continue
+		}
+		if m := mermaidHeader.FindStringSubmatch(line); m != nil {
+			kind = m[1]
+		}
+		break
+	}
+
+	switch kind {
+	case "graph", "flowchart":
+		g, err := parseFlowchart(source)
+		if err != nil {
+			return nil, err
+		}
+		return g.draw(), nil
+	case "sequenceDiagram":
+		d, err := parseSequence(source)
+		if err != nil {
+			return nil, err
+		}
+		return d.draw(), nil
+	case "":
+		return nil, fmt.Errorf("empty mermaid diagram")
+	}
+	return nil, &UnsupportedError{Kind: "mermaid " + kind}
+}
+
+// cleanLabel unquotes a label and turns <br> tags into line breaks
+func cleanLabel(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	s = brTag.ReplaceAllString(s, "\n")
+	s = strings.ReplaceAll(s, `\n`, "\n")
+	return strings.ReplaceAll(s, "#quot;", `"`)
+}
+
+// brTag matches HTML line breaks in labels
+var brTag = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// textWidth returns the widest line of a label
+func textWidth(label string) int {
+	width := 0
+	for _, line := range strings.Split(label, "\n") {
+		width = max(width, ansi.StringWidth(line))
+	}
+	return width
+}
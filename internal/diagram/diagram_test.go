@@ -0,0 +1,162 @@
+package diagram
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "flowchart left to right",
+			lang:   "mermaid",
+			source: "graph LR\n  A --> B",
+			want: []string{
+				"┌───┐  ┌───┐",
+				"│ A │─▶│ B │",
+				"└───┘  └───┘",
+			},
+		},
+		{
+			name:   "flowchart with shapes, labels and a loop back",
+			lang:   "mermaid",
+			source: "flowchart TD\n  start([Start]) --> check{OK?}\n  check -->|yes| done[Done]\n  check -->|no| start",
+			want: []string{
+				"╭───────╮",
+				"│ Start │",
+				"╰───────╯",
+				"    ▲",
+				"    │ no",
+				"    ▼",
+				" ╱─────╲",
+				" │ OK? │",
+				" ╲─────╱",
+				"    │",
+				"    │ yes",
+				"    ▼",
+				"┌──────┐",
+				"│ Done │",
+				"└──────┘",
+			},
+		},
+		{
+			name:   "sequence with a reply",
+			lang:   "mermaid",
+			source: "sequenceDiagram\n  Alice->>Bob: Hi\n  Bob-->>Alice: Hello",
+			want: []string{
+				"┌───────┐  ┌─────┐",
+				"│ Alice │  │ Bob │",
+				"└───────┘  └─────┘",
+				"    │   Hi    │",
+				"    ├────────▶│",
+				"    │  Hello  │",
+				"    │◀┄┄┄┄┄┄┄┄┤",
+				"    │         │",
+				"┌───────┐  ┌─────┐",
+				"│ Alice │  │ Bob │",
+				"└───────┘  └─────┘",
+			},
+		},
+		{
+			name:   "sequence with aliases and a note",
+			lang:   "mermaid",
+			source: "sequenceDiagram\n  participant A as Client\n  participant S as Server\n  A->>S: GET /\n  Note over S: checks cache\n  S-->>A: 200",
+			want: []string{
+				"┌────────┐  ┌────────┐",
+				"│ Client │  │ Server │",
+				"└────────┘  └────────┘",
+				"     │   GET /   │",
+				"     ├──────────▶│",
+				"     │   ┌──────────────┐",
+				"     │   │ checks cache │",
+				"     │   └──────────────┘",
+				"     │    200    │",
+				"     │◀┄┄┄┄┄┄┄┄┄┄┤",
+				"     │           │",
+				"┌────────┐  ┌────────┐",
+				"│ Client │  │ Server │",
+				"└────────┘  └────────┘",
+			},
+		},
+		{
+			name:   "dot fan out",
+			lang:   "dot",
+			source: "digraph { a -> b; a -> c }",
+			want: []string{
+				"    ╭───╮",
+				"    │ a │",
+				"    ╰───╯",
+				"      │",
+				"  ┌───┴───┐",
+				"  ▼       ▼",
+				"╭───╮   ╭───╮",
+				"│ b │   │ c │",
+				"╰───╯   ╰───╯",
+			},
+		},
+		{
+			name:   "plantuml sequence",
+			lang:   "plantuml",
+			source: "@startuml\nAlice -> Bob: hi\n@enduml",
+			want: []string{
+				"┌───────┐  ┌─────┐",
+				"│ Alice │  │ Bob │",
+				"└───────┘  └─────┘",
+				"    │   hi    │",
+				"    ├────────▶│",
+				"    │         │",
+				"┌───────┐  ┌─────┐",
+				"│ Alice │  │ Bob │",
+				"└───────┘  └─────┘",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.lang, tt.source)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render(%q, %q) =\n%q\nwant\n%q", tt.lang, tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		lang        string
+		source      string
+		want        string
+		unsupported bool
+	}{
+		{"mermaid pie", "mermaid", "pie\n  \"a\": 1", "mermaid pie diagrams can't be drawn as text", true},
+		{"unknown language", "vega", "{}", "vega diagrams can't be drawn as text", true},
+		{"empty mermaid", "mermaid", "%% only a comment\n", "empty mermaid diagram", false},
+		{"dangling edge", "mermaid", "graph LR\n  A --> ", `expected a node at ""`, false},
+		{"unclosed dot graph", "dot", "digraph {", "missing }", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := Render(tt.lang, tt.source)
+			if err == nil {
+				t.Fatalf("Render drew %q, want an error", lines)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+			var unsupported *UnsupportedError
+			if errors.As(err, &unsupported) != tt.unsupported {
+				t.Errorf("error %T, unsupported = %v", err, tt.unsupported)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package diagram
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// dotToken is a word, quoted string or punctuation in a dot file
+type dotToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeDot splits a dot file into tokens, dropping comments
+func tokenizeDot(source string) []dotToken {
+	var toks []dotToken
+	r := []rune(source)
+	for i := 0; i < len(r); {
+		switch c := r[i]; {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#' && (i == 0 || r[i-1] == '\n'), c == '/' && i+1 < len(r) && r[i+1] == '/':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			i += 2
+			for i+1 < len(r) && (r[i] != '*' || r[i+1] != '/') {
+				i++
+			}
+			i += 2
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(r) && r[j] != '"'; j++ {
+				if r[j] == '\\' && j+1 < len(r) && r[j+1] == '"' {
+					j++
+				}
+				b.WriteRune(r[j])
+			}
+			toks = append(toks, dotToken{text: b.String(), quoted: true})
+			i = j + 1
+		case c == '<':
+			// An HTML label, kept as text
+			depth, j := 0, i
+			for ; j < len(r); j++ {
+				if r[j] == '<' {
+					depth++
+				} else if r[j] == '>' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			toks = append(toks, dotToken{text: string(r[i+1 : min(j, len(r))]), quoted: true})
+			i = j + 1
+		case c == '-' && i+1 < len(r) && (r[i+1] == '>' || r[i+1] == '-'):
+			toks = append(toks, dotToken{text: string(r[i : i+2])})
+			i += 2
+		case strings.ContainsRune("{}[];,=", c):
+			toks = append(toks, dotToken{text: string(c)})
+			i++
+		default:
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.') {
+				j++
+			}
+			if j == i {
+				j++ // skip characters dot doesn't use
+			} else {
+				toks = append(toks, dotToken{text: string(r[i:j])})
+			}
+			i = j
+		}
+	}
+	return toks
+}
+
+// dotParser reads the statements of a dot graph into a graph
+type dotParser struct {
+	toks     []dotToken
+	pos      int
+	g        *graph
+	directed bool
+	nodeAttr map[string]string // defaults from "node [...]"
+	edgeAttr map[string]string // defaults from "edge [...]"
+}
+
+// parseDot reads a Graphviz graph or digraph. Nodes, edges, labels, a few
+// shapes and edge styles, and rankdir are used; clusters are flattened.
+func parseDot(source string) (*graph, error) {
+	p := &dotParser{
+		toks:     tokenizeDot(source),
+		g:        newGraph(),
+		nodeAttr: make(map[string]string),
+		edgeAttr: make(map[string]string),
+	}
+
+	if p.peek().text == "strict" {
+		p.next()
+	}
+	switch p.next().text {
+	case "digraph":
+		p.directed = true
+	case "graph":
+	default:
+		return nil, fmt.Errorf("expected graph or digraph")
+	}
+	if p.peek().text != "{" {
+		p.next() // graph name
+	}
+	if p.next().text != "{" {
+		return nil, fmt.Errorf("expected {")
+	}
+	if err := p.statements(); err != nil {
+		return nil, err
+	}
+	if len(p.g.nodes) == 0 {
+		return nil, fmt.Errorf("the graph has no nodes")
+	}
+	return p.g, nil
+}
+
+func (p *dotParser) peek() dotToken {
+	if p.pos >= len(p.toks) {
+		return dotToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *dotParser) next() dotToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// isPunct reports whether the next token is the given unquoted punctuation
+func (p *dotParser) isPunct(s string) bool {
+	t := p.peek()
+	return !t.quoted && t.text == s
+}
+
+// statements reads statements up to the closing brace
+func (p *dotParser) statements() error {
+	for {
+		switch {
+		case p.pos >= len(p.toks):
+			return fmt.Errorf("missing }")
+		case p.isPunct("}"):
+			p.next()
+			return nil
+		case p.isPunct(";"), p.isPunct(","):
+			p.next()
+		default:
+			if err := p.statement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// statement reads one attribute, node, edge or subgraph statement
+func (p *dotParser) statement() error {
+	t := p.next()
+	if !t.quoted {
+		switch t.text {
+		case "graph":
+			p.setGraphAttrs(p.attrs())
+			return nil
+		case "node":
+			for k, v := range p.attrs() {
+				p.nodeAttr[k] = v
+			}
+			return nil
+		case "edge":
+			for k, v := range p.attrs() {
+				p.edgeAttr[k] = v
+			}
+			return nil
+		case "subgraph":
+			if !p.isPunct("{") {
+				p.next()
+			}
+			if !p.isPunct("{") {
+				return nil
+			}
+			p.next()
+			return p.statements()
+		case "{":
+			return p.statements()
+		}
+	}
+
+	if p.isPunct("=") {
+		p.next()
+		p.setGraphAttrs(map[string]string{t.text: p.next().text})
+		return nil
+	}
+
+	ids := []string{t.text}
+	for p.isPunct("->") || p.isPunct("--") {
+		p.next()
+		if p.isPunct("{") {
+			return fmt.Errorf("edges to subgraphs aren't supported")
+		}
+		ids = append(ids, p.next().text)
+	}
+	attrs := p.attrs()
+
+	if len(ids) == 1 {
+		p.applyNode(p.node(ids[0]), attrs)
+		return nil
+	}
+	for i := 1; i < len(ids); i++ {
+		p.addEdge(p.node(ids[i-1]), p.node(ids[i]), attrs)
+	}
+	return nil
+}
+
+// attrs reads any [key=value, ...] lists
+func (p *dotParser) attrs() map[string]string {
+	attrs := make(map[string]string)
+	for p.isPunct("[") {
+		p.next()
+		for p.pos < len(p.toks) && !p.isPunct("]") {
+			key := p.next()
+			if key.text == "," || key.text == ";" {
+				continue
+			}
+			value := "true"
+			if p.isPunct("=") {
+				p.next()
+				value = p.next().text
+			}
+			attrs[key.text] = value
+		}
+		p.next()
+	}
+	return attrs
+}
+
+// setGraphAttrs applies graph attributes; only the direction matters
+func (p *dotParser) setGraphAttrs(attrs map[string]string) {
+	switch attrs["rankdir"] {
+	case "LR":
+		p.g.dir = leftRight
+	case "RL":
+		p.g.dir = rightLeft
+	case "BT":
+		p.g.dir = bottomUp
+	case "TB":
+		p.g.dir = topDown
+	}
+}
+
+// node returns a node by id, giving new ones the default attributes
+func (p *dotParser) node(id string) int {
+	if i, ok := p.g.byID[id]; ok {
+		return i
+	}
+	i := p.g.node(id)
+	p.g.nodes[i].shape = roundShape // dot's default ellipse
+	p.applyNode(i, p.nodeAttr)
+	return i
+}
+
+// applyNode sets a node's label and shape
+func (p *dotParser) applyNode(i int, attrs map[string]string) {
+	n := p.g.nodes[i]
+	if label, ok := attrs["label"]; ok {
+		n.label = dotLabel(label)
+	}
+	switch attrs["shape"] {
+	case "box", "rect", "rectangle", "square", "record", "Mrecord", "plaintext", "plain", "none", "note", "tab", "folder", "component":
+		n.shape = rectShape
+	case "diamond", "Mdiamond":
+		n.shape = diamondShape
+	case "doublecircle", "doubleoctagon", "tripleoctagon":
+		n.shape = doubleShape
+	case "":
+	default:
+		n.shape = roundShape
+	}
+}
+
+// addEdge adds an edge with the default edge attributes under its own
+func (p *dotParser) addEdge(from, to int, attrs map[string]string) {
+	merged := make(map[string]string, len(p.edgeAttr)+len(attrs))
+	for k, v := range p.edgeAttr {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+
+	e := edge{from: from, to: to, label: dotLabel(merged["label"])}
+	if p.directed {
+		e.head = arrowMark
+	}
+	switch merged["dir"] {
+	case "both":
+		e.head, e.tail = arrowMark, arrowMark
+	case "back":
+		e.head, e.tail = noMark, arrowMark
+	case "none":
+		e.head = noMark
+	}
+	if merged["arrowhead"] == "none" {
+		e.head = noMark
+	}
+	switch {
+	case strings.Contains(merged["style"], "dashed"), strings.Contains(merged["style"], "dotted"):
+		e.style = dotted
+	case strings.Contains(merged["style"], "bold"):
+		e.style = thick
+	}
+	p.g.edges = append(p.g.edges, e)
+}
+
+// dotLabel turns dot's line break escapes into line breaks
+func dotLabel(s string) string {
+	s = strings.NewReplacer(`\l`, `\n`, `\r`, `\n`).Replace(s)
+	return strings.TrimRight(cleanLabel(s), "\n")
+}
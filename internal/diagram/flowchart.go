@@ -0,0 +1,273 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// flowchartHeader matches "graph TD" or "flowchart LR"
+	flowchartHeader = regexp.MustCompile(`^\s*(?:graph|flowchart)\b\s*(TB|TD|BT|RL|LR)?\s*;?`)
+
+	// nodeID matches a node id; dashes are allowed between words
+	nodeID = regexp.MustCompile(`^[\p{L}\p{N}_]+(?:-[\p{L}\p{N}_]+)*`)
+
+	// labelledLink matches a link with its text in the middle, e.g. "-- yes -->"
+	labelledLink = regexp.MustCompile(`^(<|x|o)?(--|==|-\.)\s*([^-=.>|\s][^|]*?)\s*(-{2,}|={2,}|\.-+)(>|x|o)?`)
+
+	// plainLink matches a link without text, e.g. "-->", "-.->" or "==>"
+	plainLink = regexp.MustCompile(`^(<|x|o)?(?:(-{2,}|={2,})|(-\.+-))(>|x|o)?`)
+
+	// pipeLabel matches the |text| that can follow a link
+	pipeLabel = regexp.MustCompile(`^\|([^|]*)\|`)
+)
+
+// shapeOpeners pairs the brackets around node text with the shape they
+// give, longest first so "((" wins over "("
+var shapeOpeners = []struct {
+	open, close string
+	shape       shape
+}{
+	{"(((", ")))", roundShape},
+	{"([", "])", roundShape},
+	{"((", "))", roundShape},
+	{"[[", "]]", doubleShape},
+	{"[(", ")]", roundShape},
+	{"{{", "}}", diamondShape},
+	{"[/", "/]", rectShape},
+	{"[\\", "\\]", rectShape},
+	{"[/", "\\]", rectShape},
+	{"[\\", "/]", rectShape},
+	{"(", ")", roundShape},
+	{"[", "]", rectShape},
+	{"{", "}", diamondShape},
+	{">", "]", rectShape},
+}
+
+// skippedStatements are the keywords of flowchart statements that group
+// or style nodes rather than adding them
+var skippedStatements = map[string]bool{
+	"subgraph": true, "end": true, "classDef": true, "class": true,
+	"style": true, "linkStyle": true, "click": true, "direction": true,
+}
+
+// parseFlowchart reads a mermaid flowchart
+func parseFlowchart(source string) (*graph, error) {
+	g := newGraph()
+	header := false
+	for _, line := range strings.Split(source, "\n") {
+		if i := strings.Index(line, "%%"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !header {
+			m := flowchartHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("expected graph or flowchart")
+			}
+			switch m[1] {
+			case "BT":
+				g.dir = bottomUp
+			case "LR":
+				g.dir = leftRight
+			case "RL":
+				g.dir = rightLeft
+			}
+			header = true
+			line = strings.TrimSpace(line[len(m[0]):])
+		}
+
+		for _, stmt := range splitStatements(line) {
+			if err := g.parseStatement(stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(g.nodes) == 0 {
+		return nil, fmt.Errorf("the flowchart has no nodes")
+	}
+	return g, nil
+}
+
+// splitStatements splits a line at semicolons outside quotes and brackets
+func splitStatements(line string) []string {
+	var stmts []string
+	depth, quoted, start := 0, false, 0
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case strings.ContainsRune("[({", r):
+			depth++
+		case strings.ContainsRune("])}", r):
+			depth--
+		case r == ';' && depth <= 0:
+			stmts = append(stmts, line[start:i])
+			start = i + 1
+		}
+	}
+	stmts = append(stmts, line[start:])
+
+	var out []string
+	for _, s := range stmts {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseStatement reads a chain of nodes and links, e.g.
+// "A[Start] --> B{ok?} -->|yes| C & D"
+func (g *graph) parseStatement(stmt string) error {
+	if skippedStatements[strings.Fields(stmt)[0]] {
+		return nil
+	}
+
+	rest := stmt
+	from, err := g.parseNodeGroup(&rest)
+	if err != nil {
+		return err
+	}
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return nil
+		}
+		e, ok := parseLink(&rest)
+		if !ok {
+			return fmt.Errorf("can't read %q", stmt)
+		}
+		to, err := g.parseNodeGroup(&rest)
+		if err != nil {
+			return err
+		}
+		for _, a := range from {
+			for _, b := range to {
+				link := e
+				link.from, link.to = a, b
+				g.edges = append(g.edges, link)
+			}
+		}
+		from = to
+	}
+}
+
+// parseNodeGroup reads nodes joined with "&"
+func (g *graph) parseNodeGroup(rest *string) ([]int, error) {
+	var nodes []int
+	for {
+		*rest = strings.TrimSpace(*rest)
+		n, err := g.parseNode(rest)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+
+		trimmed := strings.TrimSpace(*rest)
+		if !strings.HasPrefix(trimmed, "&") {
+			return nodes, nil
+		}
+		*rest = trimmed[1:]
+	}
+}
+
+// parseNode reads a node id and the text and shape that can follow it
+func (g *graph) parseNode(rest *string) (int, error) {
+	id := nodeID.FindString(*rest)
+	if id == "" {
+		return 0, fmt.Errorf("expected a node at %q", *rest)
+	}
+	*rest = (*rest)[len(id):]
+	n := g.node(id)
+
+	for _, opener := range shapeOpeners {
+		if !strings.HasPrefix(*rest, opener.open) {
+			continue
+		}
+		body := (*rest)[len(opener.open):]
+		var text string
+		if strings.HasPrefix(body, `"`) {
+			end := strings.Index(body[1:], `"`)
+			if end == -1 {
+				return 0, fmt.Errorf("unclosed quote in node %s", id)
+			}
+			text = body[1 : end+1]
+			body = body[end+2:]
+			if !strings.HasPrefix(body, opener.close) {
+				continue
+			}
+		} else {
+			end := strings.Index(body, opener.close)
+			if end == -1 {
+				continue
+			}
+			text = body[:end]
+			body = body[end:]
+		}
+		*rest = body[len(opener.close):]
+		g.nodes[n].label = cleanLabel(text)
+		g.nodes[n].shape = opener.shape
+		break
+	}
+
+	// Skip a ":::className" suffix
+	if strings.HasPrefix(*rest, ":::") {
+		*rest = (*rest)[3:]
+		name := nodeID.FindString(*rest)
+		*rest = (*rest)[len(name):]
+	}
+	return n, nil
+}
+
+// parseLink reads a link and its text
+func parseLink(rest *string) (edge, bool) {
+	var e edge
+	var m []string
+	if m = labelledLink.FindStringSubmatch(*rest); m != nil {
+		e.label = cleanLabel(m[3])
+		e.tail, e.head = endMark(m[1]), endMark(m[5])
+		e.style = linkStyle(m[2] + m[4])
+	} else if m = plainLink.FindStringSubmatch(*rest); m != nil {
+		e.tail, e.head = endMark(m[1]), endMark(m[4])
+		e.style = linkStyle(m[2] + m[3])
+	} else {
+		return e, false
+	}
+	*rest = strings.TrimSpace((*rest)[len(m[0]):])
+
+	if p := pipeLabel.FindStringSubmatch(*rest); p != nil {
+		e.label = cleanLabel(p[1])
+		*rest = (*rest)[len(p[0]):]
+	}
+	return e, true
+}
+
+// endMark returns the mark for the character ending a link
+func endMark(s string) mark {
+	switch s {
+	case ">", "<":
+		return arrowMark
+	case "x":
+		return crossMark
+	case "o":
+		return circleMark
+	}
+	return noMark
+}
+
+// linkStyle returns how a link drawn with these characters looks
+func linkStyle(s string) lineStyle {
+	switch {
+	case strings.Contains(s, "."):
+		return dotted
+	case strings.Contains(s, "="):
+		return thick
+	}
+	return solid
+}
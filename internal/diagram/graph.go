@@ -0,0 +1,574 @@
+package diagram
+
+import (
+	"sort"
+	"strings"
+)
+
+// direction is the way a graph flows
+type direction int
+
+const (
+	topDown direction = iota
+	bottomUp
+	leftRight
+	rightLeft
+)
+
+// shape is the outline of a node
+type shape int
+
+const (
+	rectShape shape = iota
+	roundShape
+	doubleShape
+	diamondShape
+)
+
+// mark is what an edge ends with
+type mark int
+
+const (
+	noMark mark = iota
+	arrowMark
+	crossMark
+	circleMark
+)
+
+// node is a box in a graph
+type node struct {
+	id    string
+	label string
+	shape shape
+}
+
+// edge connects two nodes, with an optional label
+type edge struct {
+	from, to   int
+	label      string
+	style      lineStyle
+	head, tail mark // ends at to and from
+}
+
+// graph is a flowchart: boxes joined by edges
+type graph struct {
+	dir   direction
+	nodes []*node
+	byID  map[string]int
+	edges []edge
+}
+
+func newGraph() *graph {
+	return &graph{byID: make(map[string]int)}
+}
+
+// node returns the index of the node with an id, adding it if needed
+func (g *graph) node(id string) int {
+	if i, ok := g.byID[id]; ok {
+		return i
+	}
+	g.nodes = append(g.nodes, &node{id: id, label: id})
+	g.byID[id] = len(g.nodes) - 1
+	return len(g.nodes) - 1
+}
+
+// vertex is a node, or a point an edge passes through on a layer it skips
+type vertex struct {
+	node  *node // nil for edge points
+	style lineStyle
+	layer int
+	order int // position within the layer
+	cross int // position across the flow
+	size  int // extent across the flow
+	depth int // extent along the flow
+}
+
+// segment is the part of an edge between two adjacent layers
+type segment struct {
+	upper, lower int // vertices, upper on the earlier layer
+	style        lineStyle
+	upperMark    mark
+	lowerMark    mark
+	label        string
+	track        int // row or column of the jog between the two, -1 for none
+}
+
+// Gaps between vertices on the same layer
+const (
+	columnGap = 3
+	rowGap    = 1
+)
+
+// draw lays the graph out in layers along its direction and draws it
+func (g *graph) draw() []string {
+	if len(g.nodes) == 0 {
+		return nil
+	}
+	horizontal := g.dir == leftRight || g.dir == rightLeft
+
+	// Nodes with a loop to themselves are marked instead
+	for _, e := range g.edges {
+		if e.from == e.to && !strings.HasSuffix(g.nodes[e.from].label, " ↻") {
+			g.nodes[e.from].label += " ↻"
+		}
+	}
+
+	layers := g.assignLayers()
+	verts := make([]vertex, len(g.nodes))
+	for i, n := range g.nodes {
+		verts[i] = vertex{node: n, layer: layers[i]}
+		w, h := textWidth(n.label)+4, strings.Count(n.label, "\n")+3
+		if horizontal {
+			verts[i].size, verts[i].depth = h, w
+		} else {
+			verts[i].size, verts[i].depth = w, h
+		}
+	}
+
+	// Split edges into one segment per layer, through points on the layers
+	// they skip
+	var segs []segment
+	for _, e := range g.edges {
+		if e.from == e.to {
+			continue
+		}
+		upper, lower := e.from, e.to
+		upperMark, lowerMark := e.tail, e.head
+		if verts[upper].layer > verts[lower].layer {
+			upper, lower = lower, upper
+			upperMark, lowerMark = lowerMark, upperMark
+		}
+		labelAtLower := lower == e.to
+
+		prev := upper
+		for layer := verts[upper].layer + 1; layer <= verts[lower].layer; layer++ {
+			next := lower
+			if layer < verts[lower].layer {
+				verts = append(verts, vertex{layer: layer, style: e.style, size: 1})
+				next = len(verts) - 1
+			}
+			s := segment{upper: prev, lower: next, style: e.style, track: -1}
+			if prev == upper {
+				s.upperMark = upperMark
+			}
+			if next == lower {
+				s.lowerMark = lowerMark
+			}
+			if (next == lower && labelAtLower) || (prev == upper && !labelAtLower) {
+				s.label = e.label
+			}
+			segs = append(segs, s)
+			prev = next
+		}
+	}
+
+	byLayer := orderLayers(verts, segs)
+	placeAcross(verts, segs, byLayer, horizontal)
+
+	// Size each layer and the gap after it, where edges jog across and
+	// labels sit
+	depths := make([]int, len(byLayer))
+	for l, layer := range byLayer {
+		for _, v := range layer {
+			depths[l] = max(depths[l], verts[v].depth)
+		}
+	}
+	gaps := make([]int, len(byLayer))
+	labelSpace := make([]int, len(byLayer))
+	tracks := make([]int, len(byLayer))
+	for l := range byLayer {
+		tracks[l] = assignTracks(verts, segs, l)
+	}
+	for _, s := range segs {
+		if s.label == "" {
+			continue
+		}
+		l := verts[s.upper].layer
+		if horizontal {
+			labelSpace[l] = max(labelSpace[l], textWidth(s.label)+2)
+		} else {
+			labelSpace[l] = max(labelSpace[l], 1)
+		}
+	}
+	starts := make([]int, len(byLayer))
+	pos := 0
+	for l := range byLayer {
+		starts[l] = pos
+		gaps[l] = 2 + tracks[l] + labelSpace[l]
+		pos += depths[l] + gaps[l]
+	}
+
+	var c canvas
+	// point maps a position along and across the flow to the canvas
+	point := func(along, across int) (int, int) {
+		if horizontal {
+			return along, across
+		}
+		return across, along
+	}
+	alongLine := func(across, from, to int, style lineStyle) {
+		if horizontal {
+			c.hline(from, to, across, style)
+		} else {
+			c.vline(across, from, to, style)
+		}
+	}
+	acrossLine := func(along, from, to int, style lineStyle) {
+		if horizontal {
+			c.vline(along, from, to, style)
+		} else {
+			c.hline(from, to, along, style)
+		}
+	}
+	anchor := func(v int) int {
+		if verts[v].node == nil {
+			return verts[v].cross
+		}
+		return verts[v].cross + verts[v].size/2
+	}
+
+	// Edge points carry the line through their layer
+	for v := range verts {
+		if verts[v].node == nil {
+			l := verts[v].layer
+			alongLine(verts[v].cross, starts[l], starts[l]+depths[l]-1, verts[v].style)
+		}
+	}
+
+	forward, backward := '▼', '▲'
+	if horizontal {
+		forward, backward = '▶', '◀'
+	}
+	for _, s := range segs {
+		l := verts[s.upper].layer
+		gapStart := starts[l] + depths[l]
+		gapEnd := gapStart + gaps[l] - 1
+		from := starts[l] + depths[l]
+		if verts[s.upper].node != nil {
+			from = starts[l] + verts[s.upper].depth
+		}
+		ua, la := anchor(s.upper), anchor(s.lower)
+
+		if s.track < 0 {
+			alongLine(ua, from, gapEnd, s.style)
+		} else {
+			t := gapStart + 1 + s.track
+			alongLine(ua, from, t, s.style)
+			acrossLine(t, ua, la, s.style)
+			alongLine(la, t, gapEnd, s.style)
+		}
+
+		if r := markRune(s.lowerMark, forward); r != 0 {
+			x, y := point(gapEnd, la)
+			c.put(x, y, r)
+		}
+		if r := markRune(s.upperMark, backward); r != 0 {
+			x, y := point(from, ua)
+			c.put(x, y, r)
+		}
+	}
+
+	// Labels go beside the line near the lower end, or on it when the
+	// graph flows sideways
+	for _, s := range segs {
+		if s.label == "" {
+			continue
+		}
+		l := verts[s.upper].layer
+		at := starts[l] + depths[l] + 1 + tracks[l]
+		label := strings.ReplaceAll(s.label, "\n", " ")
+		if horizontal {
+			c.text(at+1, anchor(s.lower), label)
+		} else {
+			c.text(anchor(s.lower)+2, at, label)
+		}
+	}
+
+	for v := range verts {
+		n := verts[v].node
+		if n == nil {
+			continue
+		}
+		x, y := point(starts[verts[v].layer], verts[v].cross)
+		w, h := verts[v].size, verts[v].depth
+		if horizontal {
+			w, h = h, w
+		}
+		c.box(x, y, w, h, shapeBorder(n.shape), n.label)
+	}
+
+	return c.lines()
+}
+
+// markRune returns the character for an edge end, pointing the given way
+func markRune(m mark, arrow rune) rune {
+	switch m {
+	case arrowMark:
+		return arrow
+	case crossMark:
+		return '×'
+	case circleMark:
+		return '○'
+	}
+	return 0
+}
+
+// shapeBorder returns the border a node shape is drawn with
+func shapeBorder(s shape) border {
+	switch s {
+	case roundShape:
+		return roundBorder
+	case doubleShape:
+		return doubleBorder
+	case diamondShape:
+		return diamondBorder
+	}
+	return squareBorder
+}
+
+// assignLayers puts every node on a layer after the nodes with edges into
+// it. Edges closing a cycle are ignored, and graphs flowing up or left are
+// numbered from the far end.
+func (g *graph) assignLayers() []int {
+	n := len(g.nodes)
+	out := make([][]int, n)
+	for _, e := range g.edges {
+		if e.from != e.to {
+			out[e.from] = append(out[e.from], e.to)
+		}
+	}
+
+	// Find the edges that close cycles with a depth-first search
+	back := make(map[[2]int]bool)
+	state := make([]int, n) // 0 unvisited, 1 on the stack, 2 done
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = 1
+		for _, w := range out[v] {
+			switch state[w] {
+			case 0:
+				visit(w)
+			case 1:
+				back[[2]int{v, w}] = true
+			}
+		}
+		state[v] = 2
+	}
+	for v := range n {
+		if state[v] == 0 {
+			visit(v)
+		}
+	}
+
+	// Longest path from the sources, in topological order
+	in := make([]int, n)
+	for v := range n {
+		for _, w := range out[v] {
+			if !back[[2]int{v, w}] {
+				in[w]++
+			}
+		}
+	}
+	layers := make([]int, n)
+	var queue []int
+	for v := range n {
+		if in[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range out[v] {
+			if back[[2]int{v, w}] {
+				continue
+			}
+			layers[w] = max(layers[w], layers[v]+1)
+			if in[w]--; in[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	if g.dir == bottomUp || g.dir == rightLeft {
+		last := 0
+		for _, l := range layers {
+			last = max(last, l)
+		}
+		for v := range layers {
+			layers[v] = last - layers[v]
+		}
+	}
+	return layers
+}
+
+// orderLayers groups the vertices by layer and orders each layer to cut
+// down on crossing edges, moving vertices toward the average position of
+// their neighbours on the layer before
+func orderLayers(verts []vertex, segs []segment) [][]int {
+	count := 0
+	for _, v := range verts {
+		count = max(count, v.layer+1)
+	}
+	byLayer := make([][]int, count)
+	for v := range verts {
+		l := verts[v].layer
+		verts[v].order = len(byLayer[l])
+		byLayer[l] = append(byLayer[l], v)
+	}
+
+	uppers := make([][]int, len(verts))
+	lowers := make([][]int, len(verts))
+	for _, s := range segs {
+		lowers[s.upper] = append(lowers[s.upper], s.lower)
+		uppers[s.lower] = append(uppers[s.lower], s.upper)
+	}
+
+	sortLayer := func(layer []int, neighbours [][]int) {
+		weight := make(map[int]float64, len(layer))
+		for _, v := range layer {
+			weight[v] = float64(verts[v].order)
+			if len(neighbours[v]) > 0 {
+				sum := 0.0
+				for _, w := range neighbours[v] {
+					sum += float64(verts[w].order)
+				}
+				weight[v] = sum / float64(len(neighbours[v]))
+			}
+		}
+		sort.SliceStable(layer, func(a, b int) bool {
+			return weight[layer[a]] < weight[layer[b]]
+		})
+		for i, v := range layer {
+			verts[v].order = i
+		}
+	}
+
+	for range 4 {
+		for l := 1; l < count; l++ {
+			sortLayer(byLayer[l], uppers)
+		}
+		for l := count - 2; l >= 0; l-- {
+			sortLayer(byLayer[l], lowers)
+		}
+	}
+	for l := 1; l < count; l++ {
+		sortLayer(byLayer[l], uppers)
+	}
+	return byLayer
+}
+
+// placeAcross positions the vertices of each layer across the flow, in
+// order and centred on their neighbours where there is room
+func placeAcross(verts []vertex, segs []segment, byLayer [][]int, horizontal bool) {
+	gap := columnGap
+	if horizontal {
+		gap = rowGap
+	}
+	uppers := make([][]int, len(verts))
+	lowers := make([][]int, len(verts))
+	for _, s := range segs {
+		lowers[s.upper] = append(lowers[s.upper], s.lower)
+		uppers[s.lower] = append(uppers[s.lower], s.upper)
+	}
+	center := func(v int) int {
+		if verts[v].node == nil {
+			return verts[v].cross
+		}
+		return verts[v].cross + verts[v].size/2
+	}
+	// place packs a layer left to right, putting each vertex as close to
+	// the centre of its neighbours as the ones before it allow
+	place := func(layer []int, neighbours [][]int) {
+		next := 0
+		for _, v := range layer {
+			want := next
+			if len(neighbours[v]) > 0 {
+				sum := 0
+				for _, w := range neighbours[v] {
+					sum += center(w)
+				}
+				want = sum / len(neighbours[v])
+				if verts[v].node != nil {
+					want -= verts[v].size / 2
+				}
+			}
+			verts[v].cross = max(want, next)
+			next = verts[v].cross + verts[v].size + gap
+		}
+	}
+
+	for l, layer := range byLayer {
+		if l == 0 {
+			place(layer, make([][]int, len(verts)))
+			continue
+		}
+		place(layer, uppers)
+	}
+	for l := len(byLayer) - 2; l >= 0; l-- {
+		place(byLayer[l], lowers)
+	}
+
+	// Shift everything back to the left edge
+	least := -1
+	for _, v := range verts {
+		if least == -1 || v.cross < least {
+			least = v.cross
+		}
+	}
+	for v := range verts {
+		verts[v].cross -= least
+	}
+}
+
+// assignTracks gives each segment leaving a layer that has to jog across
+// the flow a track of its own in the gap, sharing one with segments from
+// the same vertex or to the same vertex. It returns the number of tracks.
+func assignTracks(verts []vertex, segs []segment, layer int) int {
+	type span struct {
+		lo, hi       int
+		upper, lower int
+	}
+	var tracks [][]span
+	anchor := func(v int) int {
+		if verts[v].node == nil {
+			return verts[v].cross
+		}
+		return verts[v].cross + verts[v].size/2
+	}
+
+	var jogs []int
+	for i, s := range segs {
+		if verts[s.upper].layer == layer && anchor(s.upper) != anchor(s.lower) {
+			jogs = append(jogs, i)
+		}
+	}
+	sort.SliceStable(jogs, func(a, b int) bool {
+		return min(anchor(segs[jogs[a]].upper), anchor(segs[jogs[a]].lower)) <
+			min(anchor(segs[jogs[b]].upper), anchor(segs[jogs[b]].lower))
+	})
+
+	for _, i := range jogs {
+		s := &segs[i]
+		sp := span{lo: min(anchor(s.upper), anchor(s.lower)), hi: max(anchor(s.upper), anchor(s.lower)), upper: s.upper, lower: s.lower}
+		for t := 0; ; t++ {
+			if t == len(tracks) {
+				tracks = append(tracks, nil)
+			}
+			fits := true
+			for _, other := range tracks[t] {
+				apart := sp.lo > other.hi+1 || other.lo > sp.hi+1
+				if !apart && other.upper != sp.upper && other.lower != sp.lower {
+					fits = false
+					break
+				}
+			}
+			if fits {
+				tracks[t] = append(tracks[t], sp)
+				s.track = t
+				break
+			}
+		}
+	}
+	return len(tracks)
+}
@@ -0,0 +1,137 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// umlParticipant matches `participant "Long Name" as L`, `actor Bob` and
+	// the other lifeline kinds
+	umlParticipant = regexp.MustCompile(`^(participant|actor|boundary|control|entity|database|collections|queue)\s+("[^"]+"|\S+)(?:\s+as\s+(\S+))?`)
+
+	// umlMessage matches "A -> B : text" and the other arrows
+	umlMessage = regexp.MustCompile(`^("[^"]+"|[\w.]+)\s*(<<?|x)?(-{1,2})(?:\[[^\]]*\])?(>>?|x|\\\\|//)?\s*("[^"]+"|[\w.]+)\s*(?::\s*(.*))?$`)
+
+	// umlNote matches "note left of A : text" and "note over A, B"
+	umlNote = regexp.MustCompile(`(?i)^note\s+(left of|right of|left|right|over)\s*([^:]*?)\s*(?::\s*(.*))?$`)
+
+	// umlBlock matches the keywords that open or divide a group
+	umlBlock = regexp.MustCompile(`^(alt|else|opt|loop|par|break|critical|group)\b\s*(.*)$`)
+
+	// umlDivider matches "== Section =="
+	umlDivider = regexp.MustCompile(`^==\s*(.*?)\s*==$`)
+
+	// umlIgnored matches lines that don't change a text drawing
+	umlIgnored = regexp.MustCompile(`^(?:@startuml|@enduml|skinparam|title|hide|show|activate|deactivate|destroy|return|autoactivate|header|footer|legend|end legend|\.\.\.|\|\|\||!)`)
+)
+
+// parsePlantUML reads a PlantUML sequence diagram. Other PlantUML diagrams
+// are reported as unsupported.
+func parsePlantUML(source string) (*sequence, error) {
+	d := newSequence()
+	var note *event // multi-line note being read
+	messages := 0
+
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if note != nil {
+			if strings.EqualFold(line, "end note") {
+				note.text = strings.TrimSpace(note.text)
+				d.events = append(d.events, *note)
+				note = nil
+			} else {
+				note.text += " " + line
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "'") {
+			continue
+		}
+		if strings.HasPrefix(line, "@start") && line != "@startuml" {
+			return nil, &UnsupportedError{Kind: "PlantUML " + strings.TrimPrefix(line, "@start")}
+		}
+
+		switch {
+		case line == "autonumber" || strings.HasPrefix(line, "autonumber "):
+			d.autonumber = true
+		case umlIgnored.MatchString(line):
+		case line == "end":
+			d.events = append(d.events, event{kind: ruleEvent})
+		case umlDivider.MatchString(line):
+			d.events = append(d.events, event{kind: ruleEvent, text: umlDivider.FindStringSubmatch(line)[1]})
+		case umlBlock.MatchString(line):
+			m := umlBlock.FindStringSubmatch(line)
+			d.events = append(d.events, event{kind: ruleEvent, text: strings.TrimSpace(m[1] + " " + m[2])})
+		case umlParticipant.MatchString(line):
+			m := umlParticipant.FindStringSubmatch(line)
+			id, label := unquote(m[2]), unquote(m[2])
+			if m[3] != "" {
+				id = m[3]
+			}
+			p := d.participants[d.participant(id)]
+			p.label = label
+			p.actor = m[1] == "actor"
+		case umlNote.MatchString(line):
+			m := umlNote.FindStringSubmatch(line)
+			place := strings.ToLower(m[1])
+			if !strings.HasSuffix(place, " of") && place != "over" {
+				place += " of"
+			}
+			e := d.note(place, m[2], m[3])
+			if !strings.Contains(line, ":") {
+				// The text follows on the next lines, up to "end note"
+				note = &e
+				continue
+			}
+			d.events = append(d.events, e)
+		case umlMessage.MatchString(line):
+			d.addUMLMessage(umlMessage.FindStringSubmatch(line))
+			messages++
+		default:
+			return nil, &UnsupportedError{Kind: "PlantUML non-sequence"}
+		}
+	}
+
+	if messages == 0 {
+		return nil, fmt.Errorf("the PlantUML diagram has no messages")
+	}
+	return d, nil
+}
+
+// addUMLMessage adds a message from a matched arrow, which can point
+// either way
+func (d *sequence) addUMLMessage(m []string) {
+	a, b := d.participant(unquote(m[1])), d.participant(unquote(m[5]))
+	e := event{kind: messageEvent, from: a, to: b, text: cleanLabel(m[6])}
+	if m[3] == "--" {
+		e.style = dotted
+	}
+
+	left, right := endUMLMark(m[2]), endUMLMark(m[4])
+	if left != noMark && right == noMark {
+		// "A <- B" is a message from B to A
+		e.from, e.to = b, a
+		e.head = left
+	} else {
+		e.head, e.tail = right, left
+	}
+	d.events = append(d.events, e)
+}
+
+// endUMLMark returns the mark for the characters ending a PlantUML arrow
+func endUMLMark(s string) mark {
+	switch s {
+	case "":
+		return noMark
+	case "x":
+		return crossMark
+	}
+	return arrowMark
+}
+
+// unquote removes the quotes around a PlantUML name
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
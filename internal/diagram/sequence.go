@@ -0,0 +1,355 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// participant is a lifeline in a sequence diagram
+type participant struct {
+	label string
+	actor bool
+}
+
+// eventKind is what a row of a sequence diagram shows
+type eventKind int
+
+const (
+	messageEvent eventKind = iota
+	noteEvent
+	ruleEvent // the start, middle or end of a loop, alt or other block
+)
+
+// notePlace is where a note sits against its lifelines
+type notePlace int
+
+const (
+	noteOver notePlace = iota
+	noteLeft
+	noteRight
+)
+
+// event is a message, note or block rule, in diagram order
+type event struct {
+	kind       eventKind
+	from, to   int
+	text       string
+	style      lineStyle
+	head, tail mark
+	place      notePlace
+}
+
+// sequence is a sequence diagram
+type sequence struct {
+	participants []*participant
+	byID         map[string]int
+	events       []event
+	autonumber   bool
+}
+
+func newSequence() *sequence {
+	return &sequence{byID: make(map[string]int)}
+}
+
+// participant returns the index of a participant, adding it if needed
+func (d *sequence) participant(id string) int {
+	if i, ok := d.byID[id]; ok {
+		return i
+	}
+	d.participants = append(d.participants, &participant{label: id})
+	d.byID[id] = len(d.participants) - 1
+	return len(d.participants) - 1
+}
+
+// draw lays the lifelines out far enough apart for the messages between
+// them and draws one event after another down the page
+func (d *sequence) draw() []string {
+	n := len(d.participants)
+	if n == 0 {
+		return nil
+	}
+
+	widths := make([]int, n)
+	for i, p := range d.participants {
+		widths[i] = textWidth(p.label) + 4
+	}
+	labels := make([]string, len(d.events))
+	number := 0
+	for i, e := range d.events {
+		labels[i] = strings.ReplaceAll(e.text, "\n", " ")
+		if e.kind == messageEvent && d.autonumber {
+			number++
+			labels[i] = strconv.Itoa(number) + ". " + labels[i]
+		}
+	}
+
+	// Space the lifelines: boxes side by side, then wide enough for each
+	// message label and note, narrowest spans first
+	type need struct{ a, b, dist int }
+	var needs []need
+	for i := 1; i < n; i++ {
+		needs = append(needs, need{i - 1, i, (widths[i-1]+widths[i])/2 + 2})
+	}
+	margin := widths[0] / 2
+	for i, e := range d.events {
+		w := ansi.StringWidth(labels[i])
+		switch {
+		case e.kind == messageEvent && e.from != e.to:
+			needs = append(needs, need{min(e.from, e.to), max(e.from, e.to), w + 4})
+		case e.kind == messageEvent && e.to+1 < n:
+			needs = append(needs, need{e.to, e.to + 1, w + 7})
+		case e.kind == noteEvent && e.place == noteRight && e.from+1 < n:
+			needs = append(needs, need{e.from, e.from + 1, w + 7})
+		case e.kind == noteEvent && e.place == noteLeft && e.from > 0:
+			needs = append(needs, need{e.from - 1, e.from, w + 7})
+		case e.kind == noteEvent && e.place == noteLeft:
+			margin = max(margin, w+6)
+		case e.kind == noteEvent && e.place == noteOver && e.from != e.to:
+			needs = append(needs, need{min(e.from, e.to), max(e.from, e.to), w})
+		case e.kind == noteEvent && e.place == noteOver && e.from == 0:
+			margin = max(margin, (w+4)/2)
+		}
+	}
+	sort.SliceStable(needs, func(i, j int) bool {
+		return needs[i].b-needs[i].a < needs[j].b-needs[j].a
+	})
+	centers := make([]int, n)
+	centers[0] = margin
+	for i := 1; i < n; i++ {
+		centers[i] = centers[i-1] + 1
+	}
+	for _, nd := range needs {
+		if short := nd.dist - (centers[nd.b] - centers[nd.a]); short > 0 {
+			for i := nd.b; i < n; i++ {
+				centers[i] += short
+			}
+		}
+	}
+
+	width := centers[n-1] + widths[n-1]/2 + 1
+	for i, e := range d.events {
+		w := ansi.StringWidth(labels[i])
+		if e.kind == messageEvent && e.from == e.to {
+			width = max(width, centers[e.from]+w+6)
+		}
+		if e.kind == noteEvent && e.place == noteRight {
+			width = max(width, centers[e.from]+w+6)
+		}
+	}
+
+	var c canvas
+	heads := func(y int) {
+		for i, p := range d.participants {
+			b := squareBorder
+			if p.actor {
+				b = roundBorder
+			}
+			c.box(centers[i]-widths[i]/2, y, widths[i], 3, b, p.label)
+		}
+	}
+
+	y := 3
+	var rows []int // first row of each event
+	for _, e := range d.events {
+		rows = append(rows, y)
+		switch {
+		case e.kind == ruleEvent:
+			y++
+		case e.kind == noteEvent:
+			y += 3
+		default:
+			y += 2
+		}
+	}
+	bottom := y + 1
+
+	for i := range d.participants {
+		c.vline(centers[i], 3, bottom-1, solid)
+	}
+
+	for i, e := range d.events {
+		y := rows[i]
+		switch e.kind {
+		case ruleEvent:
+			for x := 0; x < width; x++ {
+				c.put(x, y, '┄')
+			}
+			if labels[i] != "" {
+				c.text(1, y, " "+labels[i]+" ")
+			}
+
+		case noteEvent:
+			w := ansi.StringWidth(labels[i]) + 4
+			var x int
+			switch {
+			case e.place == noteRight:
+				x = centers[e.from] + 2
+			case e.place == noteLeft:
+				x = centers[e.from] - 1 - w
+			default:
+				lo, hi := centers[min(e.from, e.to)], centers[max(e.from, e.to)]
+				w = max(w, hi-lo+4)
+				x = (lo+hi)/2 - w/2
+			}
+			c.box(max(x, 0), y, w, 3, squareBorder, labels[i])
+
+		case messageEvent:
+			a, b := centers[e.from], centers[e.to]
+			if e.from == e.to {
+				// A message to itself loops out to the right and back
+				c.hline(a, a+3, y, e.style)
+				c.vline(a+3, y, y+1, e.style)
+				c.hline(a+1, a+3, y+1, e.style)
+				if r := markRune(e.head, '◀'); r != 0 {
+					c.put(a+1, y+1, r)
+				}
+				c.text(a+5, y, labels[i])
+				continue
+			}
+
+			forward, backward := '▶', '◀'
+			lo, hi := a, b-1
+			if b < a {
+				forward, backward = '◀', '▶'
+				lo, hi = b+1, a
+			}
+			c.hline(lo, hi, y+1, e.style)
+			if r := markRune(e.head, forward); r != 0 {
+				c.put(b+sign(a-b), y+1, r)
+			}
+			if r := markRune(e.tail, backward); r != 0 {
+				c.put(a+sign(b-a), y+1, r)
+			}
+			label := labels[i]
+			c.text(max(min(a, b)+2, (a+b)/2-ansi.StringWidth(label)/2), y, label)
+		}
+	}
+
+	heads(0)
+	heads(bottom)
+	return c.lines()
+}
+
+// sign returns -1, 0 or 1
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+var (
+	// seqParticipant matches "participant A as Alice" or "actor B"
+	seqParticipant = regexp.MustCompile(`^(?:create\s+)?(participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+
+	// seqMessage matches "A->>B: text" and the other arrows
+	seqMessage = regexp.MustCompile(`^(.+?)\s*(<<)?(--?)(>>|>|x|\))\s*[+-]?\s*(.+?)\s*(?::\s*(.*))?$`)
+
+	// seqNote matches "Note right of A: text" or "Note over A,B: text"
+	seqNote = regexp.MustCompile(`(?i)^note\s+(left of|right of|over)\s+([^:]+?)\s*:\s*(.*)$`)
+
+	// seqBlock matches the keywords that open or divide a block
+	seqBlock = regexp.MustCompile(`^(loop|alt|opt|par|critical|break|rect|box|else|and|option)\b\s*(.*)$`)
+)
+
+// parseSequence reads a mermaid sequence diagram
+func parseSequence(source string) (*sequence, error) {
+	d := newSequence()
+	var blocks []string // open block keywords
+	for _, line := range strings.Split(source, "\n") {
+		if i := strings.Index(line, "%%"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line == "sequenceDiagram" {
+			continue
+		}
+
+		if m := seqParticipant.FindStringSubmatch(line); m != nil {
+			p := d.participants[d.participant(m[2])]
+			p.actor = m[1] == "actor"
+			if m[3] != "" {
+				p.label = cleanLabel(m[3])
+			}
+			continue
+		}
+		if m := seqNote.FindStringSubmatch(line); m != nil {
+			d.events = append(d.events, d.note(m[1], m[2], m[3]))
+			continue
+		}
+		if line == "end" {
+			if len(blocks) > 0 {
+				kind := blocks[len(blocks)-1]
+				blocks = blocks[:len(blocks)-1]
+				if kind == "rect" || kind == "box" {
+					continue
+				}
+			}
+			d.events = append(d.events, event{kind: ruleEvent})
+			continue
+		}
+		if m := seqBlock.FindStringSubmatch(line); m != nil {
+			switch m[1] {
+			case "rect", "box":
+				blocks = append(blocks, m[1])
+				continue
+			case "else", "and", "option":
+			default:
+				blocks = append(blocks, m[1])
+			}
+			d.events = append(d.events, event{kind: ruleEvent, text: strings.TrimSpace(m[1] + " " + cleanLabel(m[2]))})
+			continue
+		}
+		if line == "autonumber" || strings.HasPrefix(line, "autonumber ") {
+			d.autonumber = true
+			continue
+		}
+		if m := seqMessage.FindStringSubmatch(line); m != nil {
+			e := event{kind: messageEvent, from: d.participant(m[1]), to: d.participant(m[5]), text: cleanLabel(m[6])}
+			if m[3] == "--" {
+				e.style = dotted
+			}
+			switch m[4] {
+			case ">>", ")":
+				e.head = arrowMark
+			case "x":
+				e.head = crossMark
+			}
+			if m[2] != "" {
+				e.tail = arrowMark
+			}
+			d.events = append(d.events, e)
+		}
+		// activate, deactivate, title and links don't change the drawing
+	}
+	if len(d.participants) == 0 {
+		return nil, fmt.Errorf("the sequence diagram has no participants")
+	}
+	return d, nil
+}
+
+// note returns a note next to or over one or two participants
+func (d *sequence) note(place, who, text string) event {
+	e := event{kind: noteEvent, text: cleanLabel(text)}
+	switch strings.ToLower(place) {
+	case "left of":
+		e.place = noteLeft
+	case "right of":
+		e.place = noteRight
+	}
+	names := strings.SplitN(who, ",", 2)
+	e.from = d.participant(strings.TrimSpace(names[0]))
+	e.to = e.from
+	if len(names) == 2 {
+		e.to = d.participant(strings.TrimSpace(names[1]))
+	}
+	return e
+}
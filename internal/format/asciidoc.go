@@ -0,0 +1,210 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// adocHeading matches "= Title" through "====== Title"
+	adocHeading = regexp.MustCompile(`^(={1,6})\s+(.+?)\s*=*\s*$`)
+
+	// adocAttribute matches ":name: value" document attributes
+	adocAttribute = regexp.MustCompile(`^:!?[\w-]+!?:`)
+
+	// adocBlockAttributes matches a "[source,go]" style attribute line
+	adocBlockAttributes = regexp.MustCompile(`^\[([^\]]*)\]\s*$`)
+
+	// adocAdmonition matches "NOTE: text" paragraphs
+	adocAdmonition = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+
+	// adocList matches "*", "**", "-", "." and ".." list items
+	adocList = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5})\s+(.*)$`)
+
+	// adocBlockTitle matches ".Title" lines above blocks
+	adocBlockTitle = regexp.MustCompile(`^\.([^.\s].*)$`)
+
+	// adocImage matches image::path[alt] and image:path[alt]
+	adocImage = regexp.MustCompile(`image::?([^\s\[]+)\[([^\],]*)[^\]]*\]`)
+
+	// adocLink matches link:path[text], xref:path[text] and URLs with [text]
+	adocLink = regexp.MustCompile(`(?:link:|xref:)?((?:https?|ftp|mailto):[^\s\[]+|[^\s\[:]+\.\w+(?:#[\w-]+)?)\[([^\]]*)\]`)
+
+	// adocCrossRef matches <<id>> and <<id,text>>
+	adocCrossRef = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]+))?>>`)
+
+	// adocStrong matches *strong* (constrained)
+	adocStrong = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*($|[^\w*])`)
+
+	// adocPassthrough matches +text+ and pass:[text]
+	adocPassthrough = regexp.MustCompile(`\+([^+\s][^+]*)\+|pass:\[([^\]]*)\]`)
+)
+
+// FromAsciiDoc converts the common parts of an AsciiDoc document to
+// markdown: headings, listing, literal and quote blocks, admonitions,
+// lists, tables, images, links and inline markup. Comments and document
+// attributes are dropped.
+func FromAsciiDoc(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	var out []string
+	language := "" // from the last [source,lang] line
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "////":
+			// Comment block
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "////"; i++ {
+			}
+			continue
+
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+
+		case adocAttribute.MatchString(line):
+			continue
+
+		case adocBlockAttributes.MatchString(trimmed):
+			attrs := strings.Split(adocBlockAttributes.FindStringSubmatch(trimmed)[1], ",")
+			if len(attrs) > 1 && strings.TrimSpace(attrs[0]) == "source" {
+				language = strings.TrimSpace(attrs[1])
+			}
+			continue
+
+		case trimmed == "----" || trimmed == "....":
+			// Listing and literal blocks
+			var body []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != trimmed; i++ {
+				body = append(body, lines[i])
+			}
+			out = append(out, fence(language, body)...)
+			language = ""
+			continue
+
+		case trimmed == "____":
+			// Quote block
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "____"; i++ {
+				out = append(out, "> "+adocInline(lines[i]))
+			}
+			continue
+
+		case trimmed == "====" || trimmed == "****" || trimmed == "--":
+			// Example, sidebar and open block delimiters
+			continue
+
+		case trimmed == "|===":
+			var rows []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "|==="; i++ {
+				rows = append(rows, lines[i])
+			}
+			out = append(out, adocTable(rows)...)
+			continue
+		}
+
+		if m := adocHeading.FindStringSubmatch(line); m != nil {
+			out = append(out, strings.Repeat("#", len(m[1]))+" "+adocInline(m[2]))
+			continue
+		}
+		if m := adocAdmonition.FindStringSubmatch(line); m != nil {
+			out = append(out, "> **"+m[1][:1]+strings.ToLower(m[1][1:])+":** "+adocInline(m[2]))
+			continue
+		}
+		if m := adocList.FindStringSubmatch(line); m != nil {
+			marker, depth := "-", len(m[1])-1
+			if m[1][0] == '.' {
+				marker = "1."
+			} else if m[1] == "-" {
+				depth = 0
+			}
+			out = append(out, strings.Repeat("  ", depth)+marker+" "+adocInline(m[2]))
+			continue
+		}
+		if m := adocBlockTitle.FindStringSubmatch(line); m != nil {
+			out = append(out, "**"+adocInline(m[1])+"**", "")
+			continue
+		}
+		if trimmed == "+" {
+			// List continuation
+			continue
+		}
+
+		out = append(out, adocInline(strings.TrimSuffix(line, " +")))
+	}
+	return strings.Join(out, "\n")
+}
+
+// adocInline converts inline markup outside `code`: images, links,
+// cross references, strong text and passthroughs
+func adocInline(text string) string {
+	var b strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteString("`")
+		}
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString(part)
+			continue
+		}
+		part = adocImage.ReplaceAllString(part, "![$2]($1)")
+		part = adocLink.ReplaceAllStringFunc(part, func(link string) string {
+			m := adocLink.FindStringSubmatch(link)
+			if m[2] == "" {
+				return m[1]
+			}
+			return "[" + m[2] + "](" + m[1] + ")"
+		})
+		part = adocCrossRef.ReplaceAllStringFunc(part, func(ref string) string {
+			m := adocCrossRef.FindStringSubmatch(ref)
+			if m[2] != "" {
+				return m[2]
+			}
+			return m[1]
+		})
+		part = adocStrong.ReplaceAllString(part, "$1**$2**$3")
+		part = adocPassthrough.ReplaceAllString(part, "$1$2")
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// adocTable converts the rows of a |=== table to a markdown table. The
+// first row is the header; the column count comes from it.
+func adocTable(rows []string) []string {
+	var cells []string
+	columns := 0
+	for _, row := range rows {
+		row = strings.TrimSpace(row)
+		if row == "" {
+			continue
+		}
+		rowCells := strings.Split(row, "|")[1:]
+		if columns == 0 {
+			columns = len(rowCells)
+		}
+		for _, cell := range rowCells {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+	}
+	if columns == 0 {
+		return nil
+	}
+
+	var out []string
+	for start := 0; start < len(cells); start += columns {
+		row := cells[start:min(start+columns, len(cells))]
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		for j, cell := range row {
+			row[j] = strings.ReplaceAll(adocInline(cell), "|", `\|`)
+		}
+		out = append(out, "| "+strings.Join(row, " | ")+" |")
+		if start == 0 {
+			out = append(out, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package format
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// CodeIcon marks source files in the tree
+const CodeIcon = "λ"
+
+// Binary is the format of files that aren't text; they are not shown
+var Binary = Format{
+	Name:   "Binary",
+	Icon:   "·",
+	Binary: true,
+}
+
+var (
+	codeMu    sync.Mutex
+	codeCache = make(map[string]*Format) // extension or file name -> code format (nil if none)
+)
+
+// codeFormat returns the source code format for a file name, if chroma
+// knows its language
+func codeFormat(name string) (Format, bool) {
+	key := strings.ToLower(filepath.Ext(name))
+	if key == "" {
+		key = filepath.Base(name)
+	}
+
+	codeMu.Lock()
+	defer codeMu.Unlock()
+
+	f, cached := codeCache[key]
+	if !cached {
+		if lexer := lexers.Match(filepath.Base(name)); lexer != nil {
+			f = codeFor(lexer)
+		}
+		codeCache[key] = f
+	}
+	if f == nil {
+		return Format{}, false
+	}
+	return *f, true
+}
+
+// codeFor builds the source code format for a chroma lexer
+func codeFor(lexer chroma.Lexer) *Format {
+	return &Format{
+		Name:  lexer.Config().Name,
+		Icon:  CodeIcon,
+		Lexer: lexer.Config().Name,
+		Code:  true,
+	}
+}
+
+// Detect returns the format for a file from its path and content: a
+// registered document format, source code recognised by extension, file
+// name or shebang line, binary data, or plain text
+func Detect(path, content string) Format {
+	if f, ok := Lookup(path); ok {
+		return f
+	}
+	if IsBinary(content) {
+		return Binary
+	}
+	if f, ok := codeFormat(path); ok {
+		return f
+	}
+	if lexer := shebangLexer(content); lexer != nil {
+		return *codeFor(lexer)
+	}
+	return PlainText
+}
+
+// shebangLexer returns the lexer for the interpreter named on a "#!" first
+// line, e.g. "#!/usr/bin/env python3"
+func shebangLexer(content string) chroma.Lexer {
+	if !strings.HasPrefix(content, "#!") {
+		return nil
+	}
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// Skip env options such as -S
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	// python3.12 -> python
+	interpreter = strings.TrimRight(interpreter, "0123456789.")
+	if interpreter == "" {
+		return nil
+	}
+	return lexers.Get(interpreter)
+}
+
+// SniffSize is how much of the start of a file IsBinary looks at
+const SniffSize = 8000
+
+// IsBinary reports whether content looks like binary data: it has a NUL
+// byte or invalid UTF-8 near the start
+func IsBinary(content string) bool {
+	sample := content
+	if len(sample) > SniffSize {
+		sample = sample[:SniffSize]
+		// Don't count a rune cut in half at the end of the sample
+		for i := 0; i < utf8.UTFMax && !utf8.ValidString(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return strings.IndexByte(sample, 0) != -1 || !utf8.ValidString(sample)
+}
@@ -0,0 +1,57 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path      string
+		content   string
+		wantName  string
+		wantLexer string
+		wantCode  bool
+	}{
+		{"README.md", "# hi", "Markdown", "markdown", false},
+		{"main.go", "package main", "Go", "Go", true},
+		{"Dockerfile", "FROM alpine", "Docker", "Docker", true},
+		{"Makefile", "all:\n\tgo build", "Makefile", "Makefile", true},
+		{"run", "#!/usr/bin/env python3\nprint(1)", "Python", "Python", true},
+		{"deploy", "#!/usr/bin/env -S bash -e\necho", "Bash", "Bash", true},
+		{"script", "#!/bin/sh\necho", "Bash", "Bash", true},
+		{"notes", "just text", "Plain text", "plaintext", false},
+		{"image.dat", "PNG\x00\x01", "Binary", "", false},
+		{"data.go", "\xff\xfe\x00", "Binary", "", false},
+	}
+	for _, tt := range tests {
+		f := Detect(tt.path, tt.content)
+		if f.Name != tt.wantName || f.Lexer != tt.wantLexer || f.Code != tt.wantCode {
+			t.Errorf("Detect(%q) = %q (lexer %q, code %v), want %q (lexer %q, code %v)",
+				tt.path, f.Name, f.Lexer, f.Code, tt.wantName, tt.wantLexer, tt.wantCode)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	text := strings.Repeat("a", SniffSize-1)
+
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"empty", "", false},
+		{"text", "hello\nworld", false},
+		{"utf-8", "héllo 日本", false},
+		{"nul byte", "abc\x00def", true},
+		{"invalid utf-8", "abc\xffdef", true},
+		{"rune cut at the end of the sample", text + "日本", false},
+		{"nul byte past the sample", strings.Repeat("a", SniffSize) + "\x00", false},
+	}
+	for _, tt := range tests {
+		if got := IsBinary(tt.content); got != tt.want {
+			t.Errorf("IsBinary(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
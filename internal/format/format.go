@@ -0,0 +1,222 @@
+// Package format maps document file extensions to the way skim renders them
+package format
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+)
+
+// Format describes a kind of document skim can preview
+type Format struct {
+	// Name is shown to the user, e.g. "AsciiDoc"
+	Name string
+
+	// Extensions are the lowercase file extensions, with the leading dot
+	Extensions []string
+
+	// Icon marks files of this format in the tree (one cell wide)
+	Icon string
+
+	// Lexer is the chroma lexer used for the highlighted source view
+	Lexer string
+
+	// Markdown is true when the source is markdown, so front matter, links,
+	// tasks and lint rules apply
+	Markdown bool
+
+	// ToMarkdown converts the source for rendering with glamour. Nil shows
+	// the source as wrapped plain text.
+	ToMarkdown func(source string) string
+
+	// Code is true for source files, shown highlighted with line numbers
+	Code bool
+
+	// Binary is true for files that can't be shown as text
+	Binary bool
+
+	// Delimiter separates the fields of tabular data files such as CSV,
+	// which are shown as a table (0 for other formats)
+	Delimiter rune
+
+	// Tree parses structured data files such as JSON, which are shown as a
+	// collapsible tree (nil for other formats)
+	Tree func(source string) (*Node, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry []Format
+	byExt    = make(map[string]int)
+)
+
+// PlainText is used for extensions without a registered format
+var PlainText = Format{
+	Name:       "Plain text",
+	Extensions: []string{".txt", ".text"},
+	Icon:       "≡",
+	Lexer:      "plaintext",
+}
+
+func init() {
+	Register(Format{
+		Name:       "Markdown",
+		Extensions: []string{".md", ".markdown"},
+		Icon:       "↓",
+		Lexer:      "markdown",
+		Markdown:   true,
+		ToMarkdown: func(source string) string { return source },
+	})
+	Register(Format{
+		Name:       "MDX",
+		Extensions: []string{".mdx"},
+		Icon:       "↓",
+		Lexer:      "markdown",
+		Markdown:   true,
+		ToMarkdown: stripESM,
+	})
+	Register(PlainText)
+	Register(Format{
+		Name:       "reStructuredText",
+		Extensions: []string{".rst"},
+		Icon:       "¶",
+		Lexer:      "rst",
+		ToMarkdown: FromRST,
+	})
+	Register(Format{
+		Name:       "AsciiDoc",
+		Extensions: []string{".adoc", ".asciidoc", ".asc"},
+		Icon:       "◇",
+		Lexer:      "asciidoc",
+		ToMarkdown: FromAsciiDoc,
+	})
+	Register(Format{
+		Name:       "Jupyter notebook",
+		Extensions: []string{".ipynb"},
+		Icon:       "▣",
+		Lexer:      "json",
+		ToMarkdown: FromNotebook,
+	})
+	Register(Format{
+		Name:       "CSV",
+		Extensions: []string{".csv"},
+		Icon:       "▦",
+		Lexer:      "plaintext",
+		Delimiter:  ',',
+	})
+	Register(Format{
+		Name:       "TSV",
+		Extensions: []string{".tsv", ".tab"},
+		Icon:       "▦",
+		Lexer:      "plaintext",
+		Delimiter:  '\t',
+	})
+	Register(Format{
+		Name:       "JSON",
+		Extensions: []string{".json"},
+		Icon:       "{",
+		Lexer:      "json",
+		Tree:       ParseJSON,
+	})
+	Register(Format{
+		Name:       "YAML",
+		Extensions: []string{".yaml", ".yml"},
+		Icon:       "≔",
+		Lexer:      "yaml",
+		Tree:       ParseYAML,
+	})
+	Register(Format{
+		Name:       "Org",
+		Extensions: []string{".org"},
+		Icon:       "◉",
+		Lexer:      "org",
+		ToMarkdown: FromOrg,
+	})
+}
+
+// Register adds a format, replacing any earlier format for its extensions
+func Register(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registry = append(registry, f)
+	for _, ext := range f.Extensions {
+		byExt[NormalizeExt(ext)] = len(registry) - 1
+	}
+}
+
+// Lookup returns the registered format for a file path
+func Lookup(path string) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	i, ok := byExt[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return Format{}, false
+	}
+	return registry[i], true
+}
+
+// For returns the format for a file path: a registered document format,
+// source code chroma recognises by name, or plain text. Use Detect when the
+// content is at hand.
+func For(path string) Format {
+	if f, ok := Lookup(path); ok {
+		return f
+	}
+	if f, ok := codeFormat(path); ok {
+		return f
+	}
+	return PlainText
+}
+
+// IsDocument reports whether a path has a registered document extension
+func IsDocument(path string) bool {
+	_, ok := Lookup(path)
+	return ok
+}
+
+// IsMarkdown reports whether a path is a markdown document
+func IsMarkdown(path string) bool {
+	f, ok := Lookup(path)
+	return ok && f.Markdown
+}
+
+// Extensions returns every registered extension
+func Extensions() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	exts := make([]string, 0, len(byExt))
+	for ext := range byExt {
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// NormalizeExt lowercases an extension and adds the leading dot if missing
+func NormalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// stripESM removes the import and export statements at the top level of
+// an MDX document, which glamour would show as text
+func stripESM(source string) string {
+	var out []string
+	var fence mdlinks.Fence
+	for _, line := range strings.Split(source, "\n") {
+		switch {
+		case fence.Scan(line) || fence.Open():
+		case strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "export "):
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,136 @@
+package format
+
+import "testing"
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		path     string
+		name     string
+		markdown bool
+	}{
+		{"notes.md", "Markdown", true},
+		{"NOTES.MD", "Markdown", true},
+		{"page.mdx", "MDX", true},
+		{"guide.rst", "reStructuredText", false},
+		{"book.asciidoc", "AsciiDoc", false},
+		{"todo.org", "Org", false},
+		{"data.tab", "TSV", false},
+		{"config.yml", "YAML", false},
+		{"README", "Plain text", false},
+		{"dir.md/file", "Plain text", false},
+	}
+	for _, tt := range tests {
+		f := For(tt.path)
+		if f.Name != tt.name {
+			t.Errorf("For(%q).Name = %q, want %q", tt.path, f.Name, tt.name)
+		}
+		if IsMarkdown(tt.path) != tt.markdown {
+			t.Errorf("IsMarkdown(%q) = %v, want %v", tt.path, !tt.markdown, tt.markdown)
+		}
+	}
+}
+
+func TestNormalizeExt(t *testing.T) {
+	tests := map[string]string{
+		"md":      ".md",
+		".MD":     ".md",
+		" rst ":   ".rst",
+		".tar.gz": ".tar.gz",
+		"":        "",
+	}
+	for ext, want := range tests {
+		if got := NormalizeExt(ext); got != want {
+			t.Errorf("NormalizeExt(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
+
+func TestStripESM(t *testing.T) {
+	source := "import X from './x'\nexport const meta = {}\n\n# Title\n\n```js\nimport y from 'y'\n```\n  import indented"
+	want := "\n# Title\n\n```js\nimport y from 'y'\n```\n  import indented"
+	if got := stripESM(source); got != want {
+		t.Errorf("stripESM() = %q, want %q", got, want)
+	}
+}
+
+func TestFromRST(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"heading levels follow adornment order", "Title\n=====\n\nPart\n----\n\nMore\n====", "# Title\n\n## Part\n\n# More"},
+		{"overlined title", "=====\nTitle\n=====\n\nSub\n===", "# Title\n\n## Sub"},
+		{"literal block", "Example::\n\n    code here\n\nAfter", "Example:\n\n```\ncode here\n```\n\nAfter"},
+		{"code directive", ".. code-block:: go\n   :linenos:\n\n   x := 1\n", "```go\nx := 1\n```\n"},
+		{"admonition", ".. note:: Read\n   this first.", "> **Note:** Read this first."},
+		{"comment", ".. a comment\n   continued\nText", "Text"},
+		{"inline markup", "See `docs <https://x.io>`_, ``a_b_`` and :func:`run`.", "See [docs](https://x.io), `a_b_` and `run`."},
+		{"references", "Read target_ and `the guide`_.", "Read target and the guide."},
+		{"field list", ":Author: Ann", "**Author:** Ann  "},
+		{"enumerated list", "#. one\n2) two", "1. one\n1. two"},
+		{"grid table", "+---+\n| a |\n+---+\n\nText", "```\n+---+\n| a |\n+---+\n```\n\nText"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromRST(tt.source); got != tt.want {
+				t.Errorf("FromRST() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromAsciiDoc(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"headings", "= Title\n:toc:\n\n== Section ==", "# Title\n\n## Section"},
+		{"source block", "[source,go]\n----\nx := *p\n----", "```go\nx := *p\n```"},
+		{"literal block", "....\nkept *as is*\n....", "```\nkept *as is*\n```"},
+		{"quote block", "____\nWise *words*\n____", "> Wise **words**"},
+		{"comments", "// line\n////\nblock\n////\nText", "Text"},
+		{"admonition", "WARNING: Hot", "> **Warning:** Hot"},
+		{"lists", "* one\n** nested\n. first\n- dash", "- one\n  - nested\n1. first\n- dash"},
+		{"block title", ".Results\nText", "**Results**\n\nText"},
+		{"links and images", "See https://x.io[the site], link:a.adoc#b[B] and <<intro,Intro>>. image::pic.png[Alt,200]", "See [the site](https://x.io), [B](a.adoc#b) and Intro. ![Alt](pic.png)"},
+		{"code spans are left alone", "Run `*p` not *p*", "Run `*p` not **p**"},
+		{"hard line break", "one +\ntwo", "one\ntwo"},
+		{"table", "|===\n|Name |Age\n|Ann |30\n|Bo\n|===", "| Name | Age |\n| --- | --- |\n| Ann | 30 |\n| Bo |  |"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromAsciiDoc(tt.source); got != tt.want {
+				t.Errorf("FromAsciiDoc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromOrg(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"title and headings", "#+TITLE: Notes\n#+AUTHOR: Ann\n* Top :tag:\n** Sub", "# Notes\n\n# Top\n## Sub"},
+		{"source block", "#+begin_src go\n  x := 1\n#+end_src", "```go\nx := 1\n```"},
+		{"quote block", "#+BEGIN_QUOTE\n  Said /this/\n#+END_QUOTE", "> Said *this*"},
+		{"drawer", "* Task\n:PROPERTIES:\n:ID: 1\n:END:\nText", "# Task\nText"},
+		{"comment", "# hidden\nText", "Text"},
+		{"fixed width", ": one\n: two\nText", "```\none\ntwo\n```\nText"},
+		{"emphasis", "*bold* /it/ =v= ~c~ +gone+ a*b*c", "**bold** *it* `v` `c` ~~gone~~ a*b*c"},
+		{"adjacent emphasis", "*a* *b*", "**a** **b**"},
+		{"links", "[[https://x.io][site]] and [[file:a.org]]", "[site](https://x.io) and [a.org](a.org)"},
+		{"ordered list", "1) one\n  2) two", "1. one\n  1. two"},
+		{"table", "| a | b |\n|---+---|\n| 1 | 2 |", "| a | b |\n| --- | --- |\n| 1 | 2 |"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromOrg(tt.source); got != tt.want {
+				t.Errorf("FromOrg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
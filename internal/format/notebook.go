@@ -0,0 +1,172 @@
+package format
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// notebook is the part of the Jupyter .ipynb format skim renders
+type notebook struct {
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []notebookCell `json:"cells"`
+}
+
+type notebookCell struct {
+	CellType       string           `json:"cell_type"`
+	Source         multiline        `json:"source"`
+	ExecutionCount *int             `json:"execution_count"`
+	Outputs        []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType     string               `json:"output_type"`
+	Name           string               `json:"name"` // stream name: stdout or stderr
+	Text           multiline            `json:"text"`
+	Data           map[string]multiline `json:"data"`
+	ExecutionCount *int                 `json:"execution_count"`
+	EName          string               `json:"ename"`
+	EValue         string               `json:"evalue"`
+	Traceback      []string             `json:"traceback"`
+}
+
+// multiline is notebook text, stored either as one string or a list of lines
+type multiline string
+
+// UnmarshalJSON accepts a string or a list of strings
+func (m *multiline) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*m = multiline(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Binary outputs such as images may be other JSON; they are only summarised
+		*m = ""
+		return nil
+	}
+	*m = multiline(s)
+	return nil
+}
+
+// ansiEscape matches the color and cursor codes in outputs and tracebacks
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// FromNotebook converts a Jupyter notebook to markdown: markdown cells as
+// written, code cells as highlighted code blocks labelled with their
+// execution count, and text outputs as preformatted blocks. Images, HTML
+// and other rich outputs are summarised by their type.
+func FromNotebook(source string) string {
+	var nb notebook
+	if err := json.Unmarshal([]byte(source), &nb); err != nil {
+		return "**Invalid notebook:** " + err.Error()
+	}
+
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.KernelSpec.Language
+	}
+
+	var out []string
+	for _, cell := range nb.Cells {
+		text := strings.TrimRight(string(cell.Source), "\n")
+		switch cell.CellType {
+		case "markdown":
+			out = append(out, text, "")
+
+		case "code":
+			out = append(out, "**In \\["+executionCount(cell.ExecutionCount)+"\\]:**", "")
+			out = append(out, fence(language, strings.Split(text, "\n"))...)
+			out = append(out, "")
+			for _, output := range cell.Outputs {
+				out = append(out, notebookOutputLines(output)...)
+			}
+
+		default:
+			// Raw cells
+			out = append(out, fence("", strings.Split(text, "\n"))...)
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// notebookOutputLines renders one output of a code cell
+func notebookOutputLines(output notebookOutput) []string {
+	var out []string
+	switch output.OutputType {
+	case "stream":
+		if output.Name == "stderr" {
+			out = append(out, "*stderr:*", "")
+		}
+		out = append(out, textBlock(string(output.Text))...)
+
+	case "execute_result", "display_data":
+		if output.OutputType == "execute_result" {
+			out = append(out, "**Out \\["+executionCount(output.ExecutionCount)+"\\]:**", "")
+		}
+		if text, ok := output.Data["text/plain"]; ok && len(output.Data) == 1 {
+			out = append(out, textBlock(string(text))...)
+			break
+		}
+		if text, ok := output.Data["text/markdown"]; ok {
+			out = append(out, string(text), "")
+			break
+		}
+		// Summarise rich outputs, keeping the text form when there is one
+		var kinds []string
+		for kind := range output.Data {
+			if kind != "text/plain" {
+				kinds = append(kinds, kind)
+			}
+		}
+		sort.Strings(kinds)
+		out = append(out, "*\\["+strings.Join(kinds, ", ")+" output\\]*", "")
+		if text, ok := output.Data["text/plain"]; ok && !strings.HasPrefix(string(text), "<") {
+			out = append(out, textBlock(string(text))...)
+		}
+
+	case "error":
+		lines := []string{output.EName + ": " + output.EValue}
+		if len(output.Traceback) > 0 {
+			lines = strings.Split(ansiEscape.ReplaceAllString(strings.Join(output.Traceback, "\n"), ""), "\n")
+		}
+		out = append(out, fence("", lines)...)
+		out = append(out, "")
+	}
+	return out
+}
+
+// textBlock wraps output text in a code block without highlighting. Color
+// codes are stripped, and a line redrawn with carriage returns (as progress
+// bars are) keeps only its last state.
+func textBlock(text string) []string {
+	text = strings.TrimRight(ansiEscape.ReplaceAllString(text, ""), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		lines[i] = line[strings.LastIndexByte(line, '\r')+1:]
+	}
+	return append(fence("text", lines), "")
+}
+
+// executionCount formats a cell's execution count, blank if it never ran
+func executionCount(count *int) string {
+	if count == nil {
+		return " "
+	}
+	return strconv.Itoa(*count)
+}
@@ -0,0 +1,44 @@
+package format
+
+import "testing"
+
+func TestFromNotebook(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "markdown and code cells",
+			source: `{"metadata": {"language_info": {"name": "python"}}, "cells": [{"cell_type": "markdown", "source": ["# Title\n", "text"]}, {"cell_type": "code", "execution_count": 3, "source": "x = 1", "outputs": []}]}`,
+			want:   "# Title\ntext\n\n**In \\[3\\]:**\n\n```python\nx = 1\n```\n",
+		},
+		{
+			name:   "colored stream output",
+			source: `{"cells": [{"cell_type": "code", "source": "", "outputs": [{"output_type": "stream", "name": "stdout", "text": ["\u001b[32mok\u001b[0m\n", "\u001b[?25l10%\r50%\r100%\n"]}]}]}`,
+			want:   "**In \\[ \\]:**\n\n```\n\n```\n\n```text\nok\n100%\n```\n",
+		},
+		{
+			name:   "stderr and results",
+			source: `{"cells": [{"cell_type": "code", "execution_count": 1, "source": "", "outputs": [{"output_type": "stream", "name": "stderr", "text": "warn"}, {"output_type": "execute_result", "execution_count": 1, "data": {"text/plain": "42"}}, {"output_type": "display_data", "data": {"image/png": "", "text/plain": "<Figure>"}}]}]}`,
+			want:   "**In \\[1\\]:**\n\n```\n\n```\n\n*stderr:*\n\n```text\nwarn\n```\n\n**Out \\[1\\]:**\n\n```text\n42\n```\n\n*\\[image/png output\\]*\n",
+		},
+		{
+			name:   "colored traceback",
+			source: `{"cells": [{"cell_type": "code", "source": "", "outputs": [{"output_type": "error", "ename": "E", "evalue": "v", "traceback": ["\u001b[31mTraceback\u001b[0m", "E: v"]}]}]}`,
+			want:   "**In \\[ \\]:**\n\n```\n\n```\n\n```\nTraceback\nE: v\n```\n",
+		},
+		{
+			name:   "invalid",
+			source: `{`,
+			want:   "**Invalid notebook:** unexpected end of JSON input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromNotebook(tt.source); got != tt.want {
+				t.Errorf("FromNotebook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
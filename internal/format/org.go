@@ -0,0 +1,199 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// orgHeading matches "* Heading" through "****** Heading"
+	orgHeading = regexp.MustCompile(`^(\*+)\s+(.*?)(?:\s+(:[\w@#%:]+:))?\s*$`)
+
+	// orgKeyword matches "#+KEY: value" lines
+	orgKeyword = regexp.MustCompile(`(?i)^\s*#\+(\w+):\s*(.*)$`)
+
+	// orgBlock matches "#+BEGIN_SRC go" and "#+END_SRC"
+	orgBlock = regexp.MustCompile(`(?i)^\s*#\+(begin|end)_(\w+)\s*(\S*)`)
+
+	// orgDrawer matches the start of a ":PROPERTIES:" style drawer
+	orgDrawer = regexp.MustCompile(`^\s*:[\w-]+:\s*$`)
+
+	// orgOrdered matches "1)" list items
+	orgOrdered = regexp.MustCompile(`^(\s*)\d+\)\s+`)
+
+	// orgLink matches [[target][description]] and [[target]]
+	orgLink = regexp.MustCompile(`\[\[([^\]]+)\](?:\[([^\]]+)\])?\]`)
+
+	// orgEmphasis matches *bold*, /italic/, =verbatim=, ~code~ and +strike+
+	orgEmphasis = regexp.MustCompile(`(^|[\s(\-'"{])([*/=~+])([^\s*/=~+](?:[^\n]*?[^\s])??)([*/=~+])($|[\s)\-.,:;!?'"}])`)
+
+	// orgTableRule matches a "|---+---|" table rule
+	orgTableRule = regexp.MustCompile(`^\s*\|[-+]+\|?\s*$`)
+)
+
+// FromOrg converts the common parts of an Org document to markdown:
+// headings, source, example and quote blocks, lists, tables, links and
+// emphasis. The title keyword becomes the first heading; drawers, comments
+// and other keywords are dropped.
+func FromOrg(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	var out []string
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if m := orgBlock.FindStringSubmatch(line); m != nil && strings.EqualFold(m[1], "begin") {
+			kind := strings.ToLower(m[2])
+			var body []string
+			for i++; i < len(lines); i++ {
+				if end := orgBlock.FindStringSubmatch(lines[i]); end != nil && strings.EqualFold(end[1], "end") {
+					break
+				}
+				body = append(body, lines[i])
+			}
+			switch kind {
+			case "quote", "verse":
+				for _, b := range body {
+					out = append(out, "> "+orgInline(strings.TrimSpace(b)))
+				}
+			case "src":
+				out = append(out, fence(m[3], dedent(body))...)
+			case "example":
+				out = append(out, fence("", dedent(body))...)
+			default:
+				for _, b := range body {
+					out = append(out, orgInline(b))
+				}
+			}
+			continue
+		}
+
+		if m := orgKeyword.FindStringSubmatch(line); m != nil {
+			if strings.EqualFold(m[1], "title") && m[2] != "" {
+				out = append(out, "# "+orgInline(m[2]), "")
+			}
+			continue
+		}
+
+		if orgDrawer.MatchString(line) && strings.ToUpper(trimmed) != ":END:" {
+			// Skip to the end of the drawer
+			for j := i + 1; j < len(lines); j++ {
+				if strings.EqualFold(strings.TrimSpace(lines[j]), ":END:") {
+					i = j
+					break
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "# ") || trimmed == "#" {
+			continue
+		}
+
+		if m := orgHeading.FindStringSubmatch(line); m != nil {
+			out = append(out, strings.Repeat("#", min(len(m[1]), 6))+" "+orgInline(m[2]))
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			var rows []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, lines[i])
+			}
+			i--
+			out = append(out, orgTable(rows)...)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ": ") || trimmed == ":" {
+			// Fixed-width lines
+			var body []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ": ") && t != ":" {
+					break
+				}
+				body = append(body, strings.TrimPrefix(strings.TrimPrefix(t, ":"), " "))
+			}
+			i--
+			out = append(out, fence("", body)...)
+			continue
+		}
+
+		line = orgOrdered.ReplaceAllString(line, "${1}1. ")
+		out = append(out, orgInline(line))
+	}
+	return strings.Join(out, "\n")
+}
+
+// orgMarkers maps Org emphasis markers to markdown
+var orgMarkers = map[string]string{"*": "**", "/": "*", "=": "`", "~": "`", "+": "~~"}
+
+// orgInline converts links and emphasis. Link targets are left alone.
+func orgInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range orgLink.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(orgEmphasize(text[last:m[0]]))
+		target := strings.TrimPrefix(text[m[2]:m[3]], "file:")
+		desc := target
+		if m[4] != -1 {
+			desc = text[m[4]:m[5]]
+		}
+		b.WriteString("[" + desc + "](" + target + ")")
+		last = m[1]
+	}
+	b.WriteString(orgEmphasize(text[last:]))
+	return b.String()
+}
+
+// orgEmphasize converts emphasis markers. Spans are matched one at a time so
+// the character after a span can start the next one.
+func orgEmphasize(text string) string {
+	var b strings.Builder
+	for {
+		m := orgEmphasis.FindStringSubmatchIndex(text)
+		if m == nil {
+			break
+		}
+		open, close := text[m[4]:m[5]], text[m[8]:m[9]]
+		b.WriteString(text[:m[4]])
+		if open != close {
+			b.WriteString(open)
+			text = text[m[5]:]
+			continue
+		}
+		marker := orgMarkers[open]
+		b.WriteString(marker + text[m[6]:m[7]] + marker)
+		text = text[m[9]:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// orgTable converts table rows to a markdown table, adding the header rule
+// markdown needs after the first row
+func orgTable(rows []string) []string {
+	var out []string
+	columns := 0
+	for _, row := range rows {
+		if orgTableRule.MatchString(row) {
+			continue
+		}
+		row = strings.TrimSpace(row)
+		if !strings.HasSuffix(row, "|") {
+			row += "|"
+		}
+		cells := strings.Split(row[1:len(row)-1], "|")
+		for j, cell := range cells {
+			cells[j] = orgInline(strings.TrimSpace(cell))
+		}
+		out = append(out, "| "+strings.Join(cells, " | ")+" |")
+		if columns == 0 {
+			columns = len(cells)
+			out = append(out, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return out
+}
@@ -0,0 +1,228 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// rstDirective matches ".. name:: argument"
+	rstDirective = regexp.MustCompile(`^\.\.\s+([\w-]+)::\s*(.*)$`)
+
+	// rstField matches ":name: value" field list items
+	rstField = regexp.MustCompile(`^:([^:]+):\s*(.*)$`)
+
+	// rstEnumerated matches "#." and "1)" list items
+	rstEnumerated = regexp.MustCompile(`^(\s*)(?:#|\d+)[.)]\s+`)
+
+	// rstLink matches `text <url>`_ and `text <url>`__
+	rstLink = regexp.MustCompile("`([^`<]+?)\\s*<([^>]+)>`__?")
+
+	// rstReference matches `text`_ and word_ references to link targets
+	rstReference = regexp.MustCompile("`([^`]+)`__?|\\b(\\w+)__?\\b")
+
+	// rstRole matches :role:`text`
+	rstRole = regexp.MustCompile(":[\\w-]+:`([^`]+)`")
+
+	// rstLiteral matches ``inline literals``
+	rstLiteral = regexp.MustCompile("``([^`]+)``")
+)
+
+// rstAdmonitions are the directives shown as quoted notes
+var rstAdmonitions = map[string]bool{
+	"note": true, "tip": true, "hint": true, "important": true, "warning": true,
+	"caution": true, "danger": true, "attention": true, "error": true, "admonition": true, "seealso": true,
+}
+
+// FromRST converts the common parts of a reStructuredText document to
+// markdown: section titles, literal and code blocks, admonitions, lists,
+// field lists, grid tables and inline markup. Other directives and
+// comments are dropped.
+func FromRST(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	var out []string
+	levels := map[string]int{} // adornment style -> heading level
+
+	heading := func(style, title string) {
+		level, ok := levels[style]
+		if !ok {
+			level = len(levels) + 1
+			levels[style] = level
+		}
+		out = append(out, strings.Repeat("#", min(level, 6))+" "+rstInline(strings.TrimSpace(title)))
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Overlined title: === / Title / ===
+		if isAdornment(line) && i+2 < len(lines) && trimmed != "" &&
+			strings.TrimSpace(lines[i+2]) == trimmed && strings.TrimSpace(lines[i+1]) != "" {
+			heading("over"+trimmed[:1], lines[i+1])
+			i += 2
+			continue
+		}
+
+		// Underlined title: Title / ===
+		if trimmed != "" && !isAdornment(line) && i+1 < len(lines) &&
+			isAdornment(lines[i+1]) && len(strings.TrimSpace(lines[i+1])) >= len(trimmed) &&
+			!strings.HasPrefix(line, " ") {
+			heading(strings.TrimSpace(lines[i+1])[:1], line)
+			i++
+			continue
+		}
+
+		// Directives and comments
+		if strings.HasPrefix(trimmed, "..") && !strings.HasPrefix(line, " ") {
+			body, next := indentedBlock(lines, i+1)
+			if m := rstDirective.FindStringSubmatch(trimmed); m != nil {
+				name := strings.ToLower(m[1])
+				switch {
+				case name == "code-block" || name == "code" || name == "sourcecode":
+					out = append(out, fence(m[2], dropOptions(body))...)
+				case rstAdmonitions[name]:
+					title := strings.ToUpper(name[:1]) + name[1:]
+					if name == "admonition" && m[2] != "" {
+						title = m[2]
+						m[2] = ""
+					}
+					text := strings.TrimSpace(m[2] + " " + strings.Join(dropOptions(body), " "))
+					out = append(out, "> **"+title+":** "+rstInline(text))
+				case name == "image" || name == "figure":
+					out = append(out, "![]("+m[2]+")")
+				}
+			}
+			i = next - 1
+			continue
+		}
+
+		// Literal blocks follow a paragraph ending in "::"
+		if strings.HasSuffix(trimmed, "::") {
+			body, next := indentedBlock(lines, i+1)
+			if len(body) > 0 {
+				if text := strings.TrimSuffix(trimmed, "::"); strings.TrimSpace(text) != "" {
+					out = append(out, rstInline(strings.TrimRight(line, ": ")+":"), "")
+				}
+				out = append(out, fence("", body)...)
+				i = next - 1
+				continue
+			}
+		}
+
+		// Grid and simple tables are kept as preformatted text
+		if strings.HasPrefix(trimmed, "+-") || strings.HasPrefix(trimmed, "+=") ||
+			(isAdornment(strings.ReplaceAll(trimmed, " ", "")) && strings.Contains(trimmed, "= ")) {
+			var table []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				table = append(table, lines[i])
+			}
+			i--
+			out = append(out, fence("", table)...)
+			continue
+		}
+
+		if m := rstField.FindStringSubmatch(trimmed); m != nil && !strings.HasPrefix(line, " ") {
+			out = append(out, "**"+m[1]+":** "+rstInline(m[2])+"  ")
+			continue
+		}
+
+		line = rstEnumerated.ReplaceAllString(line, "${1}1. ")
+		out = append(out, rstInline(line))
+	}
+	return strings.Join(out, "\n")
+}
+
+// rstInline converts inline markup: literals, links, references and roles.
+// Text inside literals is left alone.
+func rstInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range rstLiteral.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(rstMarkup(text[last:m[0]]))
+		b.WriteString("`" + text[m[2]:m[3]] + "`")
+		last = m[1]
+	}
+	b.WriteString(rstMarkup(text[last:]))
+	return b.String()
+}
+
+// rstMarkup converts links, references and roles in text outside literals
+func rstMarkup(text string) string {
+	text = rstLink.ReplaceAllString(text, "[$1]($2)")
+	text = rstReference.ReplaceAllStringFunc(text, func(ref string) string {
+		if strings.HasPrefix(ref, "`") {
+			return strings.Trim(ref, "`_")
+		}
+		return strings.TrimRight(ref, "_")
+	})
+	return rstRole.ReplaceAllString(text, "`$1`")
+}
+
+// isAdornment reports whether line is a section underline or overline:
+// three or more of the same punctuation character
+func isAdornment(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 3 || !strings.ContainsRune(`=-~^"'`+"`"+`#*+:.`, rune(line[0])) {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// indentedBlock returns the indented lines starting at start (dedented,
+// with leading and trailing blank lines removed) and the index of the first
+// line after them
+func indentedBlock(lines []string, start int) ([]string, int) {
+	end := start
+	for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t")) {
+		end++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	return dedent(lines[start:end]), end
+}
+
+// dropOptions removes the ":option: value" lines at the start of a directive body
+func dropOptions(body []string) []string {
+	for len(body) > 0 && rstField.MatchString(strings.TrimSpace(body[0])) {
+		body = body[1:]
+	}
+	for len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+		body = body[1:]
+	}
+	return body
+}
+
+// dedent removes the common leading whitespace of lines
+func dedent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == -1 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		out[i] = line
+	}
+	return out
+}
+
+// fence wraps lines in a fenced code block
+func fence(language string, lines []string) []string {
+	out := []string{"```" + strings.TrimSpace(language)}
+	out = append(out, lines...)
+	return append(out, "```")
+}
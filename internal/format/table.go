@@ -0,0 +1,97 @@
+package format
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Table is a delimited data file: a header row and the records below it
+type Table struct {
+	// Header names the columns (the first record of the file)
+	Header []string
+
+	// Rows are the records after the header, padded to the header's width
+	Rows [][]string
+
+	// Lines is the 0-based source line each row starts on; quoted fields
+	// may span several lines
+	Lines []int
+}
+
+// ParseTable reads CSV or TSV source, with fields separated by delimiter.
+// Records may have differing numbers of fields.
+func ParseTable(source string, delimiter rune) (*Table, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(source, "\ufeff")))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if delimiter == '\t' {
+		// Quotes in TSV are usually literal
+		r.LazyQuotes = false
+	}
+
+	t := &Table{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if delimiter == '\t' && errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrBareQuote) {
+				return parseTSV(source).pad(), nil
+			}
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		if t.Header == nil {
+			t.Header = record
+			continue
+		}
+		t.Rows = append(t.Rows, record)
+		t.Lines = append(t.Lines, line-1)
+	}
+	return t.pad(), nil
+}
+
+// pad fills short records with empty fields so every row has a cell for
+// every column
+func (t *Table) pad() *Table {
+	columns := len(t.Header)
+	for _, row := range t.Rows {
+		columns = max(columns, len(row))
+	}
+	for len(t.Header) < columns {
+		t.Header = append(t.Header, "")
+	}
+	for i, row := range t.Rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		t.Rows[i] = row
+	}
+	return t
+}
+
+// parseTSV splits tab separated lines without treating quotes specially
+func parseTSV(source string) *Table {
+	t := &Table{}
+	source = strings.TrimPrefix(strings.ReplaceAll(source, "\r\n", "\n"), "\ufeff")
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		record := strings.Split(line, "\t")
+		if t.Header == nil {
+			t.Header = record
+			continue
+		}
+		t.Rows = append(t.Rows, record)
+		t.Lines = append(t.Lines, i)
+	}
+	return t
+}
@@ -0,0 +1,85 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		delimiter rune
+		want      *Table
+	}{
+		{
+			name:      "csv",
+			source:    "name,age\nann,30\nbo,41\n",
+			delimiter: ',',
+			want: &Table{
+				Header: []string{"name", "age"},
+				Rows:   [][]string{{"ann", "30"}, {"bo", "41"}},
+				Lines:  []int{1, 2},
+			},
+		},
+		{
+			name:      "quoted fields spanning lines",
+			source:    "a,b\n\"x, y\",\"multi\nline\"\nz,\"say \"\"hi\"\"\"",
+			delimiter: ',',
+			want: &Table{
+				Header: []string{"a", "b"},
+				Rows:   [][]string{{"x, y", "multi\nline"}, {"z", `say "hi"`}},
+				Lines:  []int{1, 3},
+			},
+		},
+		{
+			name:      "ragged rows are padded",
+			source:    "a,b\n1\n1,2,3",
+			delimiter: ',',
+			want: &Table{
+				Header: []string{"a", "b", ""},
+				Rows:   [][]string{{"1", "", ""}, {"1", "2", "3"}},
+				Lines:  []int{1, 2},
+			},
+		},
+		{
+			name:      "byte order mark",
+			source:    "\ufeffa\n1",
+			delimiter: ',',
+			want:      &Table{Header: []string{"a"}, Rows: [][]string{{"1"}}, Lines: []int{1}},
+		},
+		{
+			name:      "tsv",
+			source:    "a\tb\n1\t2\n",
+			delimiter: '\t',
+			want:      &Table{Header: []string{"a", "b"}, Rows: [][]string{{"1", "2"}}, Lines: []int{1}},
+		},
+		{
+			name:      "tsv with literal quotes",
+			source:    "a\tb\nsay \"hi\"\t2\n\n5\"\t6",
+			delimiter: '\t',
+			want: &Table{
+				Header: []string{"a", "b"},
+				Rows:   [][]string{{`say "hi"`, "2"}, {`5"`, "6"}},
+				Lines:  []int{1, 3},
+			},
+		},
+		{
+			name:      "header only",
+			source:    "a,b",
+			delimiter: ',',
+			want:      &Table{Header: []string{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTable(tt.source, tt.delimiter)
+			if err != nil {
+				t.Fatalf("ParseTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTable() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
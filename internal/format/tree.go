@@ -0,0 +1,267 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodeKind is the shape of a value in a data tree
+type NodeKind int
+
+const (
+	// Scalar is a string, number, boolean or null
+	Scalar NodeKind = iota
+
+	// Map is an object with keys in document order
+	Map
+
+	// List is an array
+	List
+)
+
+// ScalarType is the type of a scalar value, which decides its color
+type ScalarType int
+
+const (
+	// String values are shown quoted
+	String ScalarType = iota
+
+	// Number values are integers and floats
+	Number
+
+	// Bool values are true and false
+	Bool
+
+	// Null values are null, ~ or empty
+	Null
+)
+
+// Node is a value in a JSON or YAML document
+type Node struct {
+	// Key is the map key of the value, or "" for list items and the root
+	Key string
+
+	// Kind is the shape of the value
+	Kind NodeKind
+
+	// Value is the text of a scalar, unquoted
+	Value string
+
+	// Type is the type of a scalar
+	Type ScalarType
+
+	// Children are the entries of a map or list
+	Children []*Node
+
+	// Line is the 0-based source line the value starts on
+	Line int
+}
+
+// Walk calls fn for the node and everything below it in document order
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// scalarType guesses the type of an unquoted scalar
+func scalarType(value string) ScalarType {
+	switch value {
+	case "true", "false", "True", "False", "TRUE", "FALSE":
+		return Bool
+	case "null", "Null", "NULL", "~", "":
+		return Null
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err == nil {
+		return Number
+	}
+	if _, err := strconv.ParseInt(value, 0, 64); err == nil {
+		return Number
+	}
+	return String
+}
+
+// ParseJSON parses a JSON document into a tree, keeping object keys in the
+// order they are written
+func ParseJSON(source string) (*Node, error) {
+	p := &jsonParser{src: strings.TrimPrefix(source, "\ufeff")}
+	p.skipSpace()
+	node, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after the document", p.src[p.pos])
+	}
+	return node, nil
+}
+
+// jsonParser reads JSON while tracking the line of every value
+type jsonParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *jsonParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line+1, fmt.Sprintf(format, args...))
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// expect consumes c after any whitespace
+func (p *jsonParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return p.errorf("unexpected end of input, expected %q", c)
+	}
+	if p.src[p.pos] != c {
+		return p.errorf("unexpected %q, expected %q", p.src[p.pos], c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *jsonParser) value() (*Node, error) {
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	node := &Node{Line: p.line}
+
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		node.Kind = Map
+		p.pos++
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '}' {
+			p.pos++
+			return node, nil
+		}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.src) || p.src[p.pos] != '"' {
+				return nil, p.errorf("expected an object key")
+			}
+			key, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			child.Key = key
+			node.Children = append(node.Children, child)
+			if done, err := p.next('}'); err != nil || done {
+				return node, err
+			}
+		}
+
+	case c == '[':
+		node.Kind = List
+		p.pos++
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ']' {
+			p.pos++
+			return node, nil
+		}
+		for {
+			p.skipSpace()
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+			if done, err := p.next(']'); err != nil || done {
+				return node, err
+			}
+		}
+
+	case c == '"':
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		node.Value, node.Type = s, String
+		return node, nil
+
+	default:
+		start := p.pos
+		for p.pos < len(p.src) && !strings.ContainsRune(",]} \t\r\n", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		literal := p.src[start:p.pos]
+		switch {
+		case literal == "":
+			return nil, p.errorf("unexpected %q, expected a value", c)
+		case literal == "true" || literal == "false":
+			node.Type = Bool
+		case literal == "null":
+			node.Type = Null
+		case json.Valid([]byte(literal)):
+			node.Type = Number
+		default:
+			return nil, p.errorf("invalid value %q", literal)
+		}
+		node.Value = literal
+		return node, nil
+	}
+}
+
+// next consumes the comma between entries, or the closing bracket,
+// reporting whether the container ended
+func (p *jsonParser) next(close byte) (bool, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return false, p.errorf("unexpected end of input, expected %q", close)
+	}
+	switch p.src[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case close:
+		p.pos++
+		return true, nil
+	}
+	return false, p.errorf("unexpected %q, expected ',' or %q", p.src[p.pos], close)
+}
+
+// string reads a quoted string, decoding escapes
+func (p *jsonParser) string() (string, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '\n':
+			return "", p.errorf("newline in string")
+		case '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal([]byte(p.src[start:p.pos]), &s); err != nil {
+				return "", p.errorf("invalid string: %v", err)
+			}
+			return s, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
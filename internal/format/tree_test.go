@@ -0,0 +1,108 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+// dump writes a tree compactly: maps as {k: v}, lists as [a, b], strings
+// quoted and other scalars as written
+func dump(n *Node) string {
+	var b strings.Builder
+	var write func(n *Node)
+	write = func(n *Node) {
+		switch n.Kind {
+		case Map:
+			b.WriteString("{")
+			for i, c := range n.Children {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(c.Key + ": ")
+				write(c)
+			}
+			b.WriteString("}")
+		case List:
+			b.WriteString("[")
+			for i, c := range n.Children {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				write(c)
+			}
+			b.WriteString("]")
+		default:
+			if n.Type == String {
+				b.WriteString(`"` + n.Value + `"`)
+			} else {
+				b.WriteString(n.Value)
+			}
+		}
+	}
+	write(n)
+	return b.String()
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"keys in document order", `{"b": 1, "a": 2}`, `{b: 1, a: 2}`},
+		{"nested values", `{"list": [1.5, -2e3, true, null, "x"], "obj": {}}`, `{list: [1.5, -2e3, true, null, "x"], obj: {}}`},
+		{"escapes", `["a\"b", "\u00e9\n"]`, "[\"a\"b\", \"é\n\"]"},
+		{"byte order mark", "\ufeff[]", `[]`},
+		{"scalar document", ` "top" `, `"top"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseJSON(tt.source)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+			if got := dump(node); got != tt.want {
+				t.Errorf("ParseJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`{"a": 1,}`, "line 1: expected an object key"},
+		{"{\n\"a\": tru\n}", `line 2: invalid value "tru"`},
+		{`[1 2]`, `line 1: unexpected '2', expected ',' or ']'`},
+		{`{"a": 1} x`, `line 1: unexpected 'x' after the document`},
+		{`["open`, "line 1: unterminated string"},
+		{``, "line 1: unexpected end of input"},
+	}
+	for _, tt := range tests {
+		_, err := ParseJSON(tt.source)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParseJSON(%q) error = %v, want %q", tt.source, err, tt.want)
+		}
+	}
+}
+
+func TestParseJSONLines(t *testing.T) {
+	node, err := ParseJSON("{\n  \"a\": 1,\n  \"b\": [\n    2\n  ]\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	node.Walk(func(n *Node) { got = append(got, n.Line) })
+	want := []int{0, 1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("lines = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("lines = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a source line split into its indentation and text
+type yamlLine struct {
+	indent int
+	text   string // without indentation
+	raw    string
+	num    int // 0-based source line
+}
+
+// blank reports whether the line has no content besides a comment
+func (l yamlLine) blank() bool {
+	return l.text == "" || strings.HasPrefix(l.text, "#")
+}
+
+// ParseYAML parses the block and flow styles of YAML most configuration
+// files use into a tree, keeping keys in the order they are written. Anchors
+// and tags are dropped and aliases are shown as written. A stream of several
+// documents is returned as a list of them.
+func ParseYAML(source string) (*Node, error) {
+	source = strings.TrimPrefix(strings.ReplaceAll(source, "\r\n", "\n"), "\ufeff")
+
+	var docs [][]yamlLine
+	var current []yamlLine
+	for i, raw := range strings.Split(source, "\n") {
+		text := strings.TrimLeft(raw, " ")
+		switch {
+		case raw == "---" || strings.HasPrefix(raw, "--- "):
+			docs = append(docs, current)
+			current = nil
+			if rest := strings.TrimSpace(raw[3:]); rest != "" {
+				current = append(current, yamlLine{text: rest, raw: rest, num: i})
+			}
+			continue
+		case raw == "...", strings.HasPrefix(raw, "%"):
+			continue
+		}
+		current = append(current, yamlLine{indent: len(raw) - len(text), text: strings.TrimRight(text, " \t"), raw: raw, num: i})
+	}
+	docs = append(docs, current)
+
+	var nodes []*Node
+	for _, lines := range docs {
+		p := &yamlParser{lines: lines}
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			continue
+		}
+		node, err := p.block(-1)
+		if err != nil {
+			return nil, err
+		}
+		if p.skipBlank(); p.pos < len(p.lines) {
+			return nil, p.errorf("unexpected indentation")
+		}
+		nodes = append(nodes, node)
+	}
+
+	switch len(nodes) {
+	case 0:
+		return &Node{Type: Null, Value: "null"}, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return &Node{Kind: List, Children: nodes, Line: nodes[0].Line}, nil
+}
+
+// yamlParser reads the lines of one YAML document
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	line := 0
+	if p.pos < len(p.lines) {
+		line = p.lines[p.pos].num
+	} else if len(p.lines) > 0 {
+		line = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("line %d: %s", line+1, fmt.Sprintf(format, args...))
+}
+
+// skipBlank moves past blank and comment lines
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].blank() {
+		p.pos++
+	}
+}
+
+// block parses the value starting at the current line, which belongs to the
+// parent when it isn't indented further than parent
+func (p *yamlParser) block(parent int) (*Node, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parent {
+		return &Node{Type: Null, Value: "null"}, nil
+	}
+
+	l := p.lines[p.pos]
+	if isSequenceItem(l.text) {
+		return p.sequence(l.indent)
+	}
+	if _, _, ok := splitKey(l.text); ok {
+		return p.mapping(l.indent)
+	}
+	p.pos++
+	return p.inline(stripComment(l.text), l.num, parent)
+}
+
+// sequence parses "- item" lines at indent
+func (p *yamlParser) sequence(indent int) (*Node, error) {
+	node := &Node{Kind: List, Line: p.lines[p.pos].num}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+			return node, nil
+		}
+		l := &p.lines[p.pos]
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if !isSequenceItem(l.text) {
+			return node, nil
+		}
+
+		var child *Node
+		var err error
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if strings.HasPrefix(rest, "#") || rest == "" {
+			p.pos++
+			child, err = p.block(indent)
+		} else {
+			// Parse the rest of the line as if it were on a line of its own,
+			// indented to where it starts
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			child, err = p.block(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		child.Line = l.num
+		node.Children = append(node.Children, child)
+	}
+}
+
+// mapping parses "key: value" lines at indent
+func (p *yamlParser) mapping(indent int) (*Node, error) {
+	node := &Node{Kind: Map, Line: p.lines[p.pos].num}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+			return node, nil
+		}
+		l := p.lines[p.pos]
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if isSequenceItem(l.text) {
+			return node, nil
+		}
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			return nil, p.errorf("expected \"key: value\"")
+		}
+		p.pos++
+
+		var child *Node
+		var err error
+		rest = stripProperties(stripComment(rest))
+		switch {
+		case rest == "":
+			// A list may sit at the same indentation as its key
+			p.skipBlank()
+			if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+				child, err = p.sequence(indent)
+			} else {
+				child, err = p.block(indent)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			child = p.blockScalar(rest, indent)
+		default:
+			child, err = p.inline(rest, l.num, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		child.Key, child.Line = key, l.num
+		node.Children = append(node.Children, child)
+	}
+}
+
+// inline parses a value written on the line of its key: a flow collection,
+// which may continue on the following lines, or a scalar, which may continue
+// on more indented lines
+func (p *yamlParser) inline(text string, line, parent int) (*Node, error) {
+	text = stripProperties(text)
+	if text == "" {
+		return &Node{Type: Null, Value: "null", Line: line}, nil
+	}
+
+	if text[0] == '[' || text[0] == '{' {
+		for !flowClosed(text) && p.pos < len(p.lines) {
+			text += " " + stripComment(strings.TrimSpace(p.lines[p.pos].raw))
+			p.pos++
+		}
+		f := &flowParser{src: text}
+		node, err := f.value()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line+1, err)
+		}
+		f.skipSpace()
+		if f.pos < len(f.src) {
+			return nil, fmt.Errorf("line %d: unexpected %q after flow collection", line+1, f.src[f.pos])
+		}
+		node.Walk(func(n *Node) { n.Line = line })
+		return node, nil
+	}
+
+	// Plain and quoted scalars may be folded over several lines
+	for p.pos < len(p.lines) && !p.lines[p.pos].blank() && p.lines[p.pos].indent > parent {
+		text += " " + stripComment(p.lines[p.pos].text)
+		p.pos++
+	}
+
+	// A plain scalar can't hold a mapping, as in "a: b: c"
+	if text[0] != '"' && text[0] != '\'' && (strings.Contains(text, ": ") || strings.HasSuffix(text, ":")) {
+		return nil, fmt.Errorf("line %d: mapping values are not allowed here", line+1)
+	}
+	return yamlScalar(text, line), nil
+}
+
+// blockScalar reads a literal (|) or folded (>) block below its key
+func (p *yamlParser) blockScalar(header string, indent int) *Node {
+	node := &Node{Type: String, Line: p.lines[p.pos-1].num}
+
+	var lines []string
+	contentIndent := -1
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.text != "" && l.indent <= indent {
+			break
+		}
+		if l.text != "" && contentIndent == -1 {
+			contentIndent = l.indent
+		}
+		if l.text == "" {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, l.raw[min(contentIndent, l.indent):])
+		}
+		p.pos++
+	}
+
+	var value string
+	if header[0] == '|' {
+		value = strings.Join(lines, "\n")
+	} else {
+		var b strings.Builder
+		for i, line := range lines {
+			// Each blank line stands for a line break and the break
+			// before the next text is folded away
+			switch {
+			case i == 0:
+			case line == "":
+				b.WriteString("\n")
+			case lines[i-1] == "":
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		value = b.String()
+	}
+
+	switch {
+	case strings.Contains(header, "+"):
+		value += "\n"
+	case strings.Contains(header, "-"):
+		value = strings.TrimRight(value, "\n")
+	default:
+		value = strings.TrimRight(value, "\n") + "\n"
+	}
+	node.Value = value
+	return node
+}
+
+// isSequenceItem reports whether text starts a "- item"
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits "key: value" into the unquoted key and the value text
+func splitKey(text string) (key, rest string, ok bool) {
+	if text == "" || strings.ContainsRune("[{#&*!|>%@`", rune(text[0])) {
+		return "", "", false
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end == -1 || end+1 >= len(text) || text[end+1] != ':' {
+			return "", "", false
+		}
+		after := text[end+2:]
+		if after != "" && after[0] != ' ' {
+			return "", "", false
+		}
+		return yamlScalar(text[:end+1], 0).Value, strings.TrimSpace(after), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '#' && i > 0 && text[i-1] == ' ' {
+			return "", "", false
+		}
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// closingQuote returns the index of the quote ending the string text starts
+// with, or -1
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing "# comment" outside quotes
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" [{,:", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+	return text
+}
+
+// stripProperties removes leading anchors (&name) and tags (!tag)
+func stripProperties(text string) string {
+	for text != "" && (text[0] == '&' || text[0] == '!') {
+		_, rest, _ := strings.Cut(text, " ")
+		text = strings.TrimSpace(rest)
+	}
+	return text
+}
+
+// yamlScalar parses a plain, single-quoted or double-quoted scalar
+func yamlScalar(text string, line int) *Node {
+	text = strings.TrimSpace(text)
+	node := &Node{Line: line, Value: text}
+	switch {
+	case len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"':
+		node.Type = String
+		if s, err := strconv.Unquote(text); err == nil {
+			node.Value = s
+		} else {
+			node.Value = text[1 : len(text)-1]
+		}
+	case len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'':
+		node.Type = String
+		node.Value = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	default:
+		node.Type = scalarType(text)
+		if node.Type == Null {
+			node.Value = "null"
+		}
+	}
+	return node
+}
+
+// flowClosed reports whether every bracket opened in text is closed
+func flowClosed(text string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// flowParser reads a flow collection such as [a, b] or {k: v}
+type flowParser struct {
+	src string
+	pos int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.src) && (f.src[f.pos] == ' ' || f.src[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+func (f *flowParser) value() (*Node, error) {
+	f.skipSpace()
+	if f.pos >= len(f.src) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+
+	switch f.src[f.pos] {
+	case '[':
+		node := &Node{Kind: List}
+		f.pos++
+		for {
+			f.skipSpace()
+			if f.pos < len(f.src) && f.src[f.pos] == ']' {
+				f.pos++
+				return node, nil
+			}
+			child, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+
+	case '{':
+		node := &Node{Kind: Map}
+		f.pos++
+		for {
+			f.skipSpace()
+			if f.pos < len(f.src) && f.src[f.pos] == '}' {
+				f.pos++
+				return node, nil
+			}
+			key := f.scalar(":,}")
+			f.skipSpace()
+			child := &Node{Type: Null, Value: "null"}
+			if f.pos < len(f.src) && f.src[f.pos] == ':' {
+				f.pos++
+				var err error
+				if child, err = f.value(); err != nil {
+					return nil, err
+				}
+			}
+			child.Key = key.Value
+			node.Children = append(node.Children, child)
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f.scalar(",]}"), nil
+}
+
+// separator consumes the comma after an entry, leaving a closing bracket
+func (f *flowParser) separator(close byte) error {
+	f.skipSpace()
+	if f.pos >= len(f.src) {
+		return fmt.Errorf("expected %q", close)
+	}
+	switch f.src[f.pos] {
+	case ',':
+		f.pos++
+		return nil
+	case close:
+		return nil
+	}
+	return fmt.Errorf("unexpected %q in flow collection", f.src[f.pos])
+}
+
+// scalar reads a quoted scalar, or a plain one up to one of the stop bytes
+func (f *flowParser) scalar(stop string) *Node {
+	f.skipSpace()
+	start := f.pos
+	if f.pos < len(f.src) && (f.src[f.pos] == '"' || f.src[f.pos] == '\'') {
+		if end := closingQuote(f.src[f.pos:]); end != -1 {
+			f.pos += end + 1
+			return yamlScalar(f.src[start:f.pos], 0)
+		}
+	}
+	for f.pos < len(f.src) && !strings.ContainsRune(stop, rune(f.src[f.pos])) {
+		f.pos++
+	}
+	return yamlScalar(stripProperties(f.src[start:f.pos]), 0)
+}
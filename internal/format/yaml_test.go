@@ -0,0 +1,81 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"scalars", "s: text\nn: 42\nf: 1.5\nb: true\nz: ~\ne:", `{s: "text", n: 42, f: 1.5, b: true, z: null, e: null}`},
+		{"quoted scalars", "d: \"a: b\\n\"\ns: 'it''s'\nh: \"# not a comment\"", "{d: \"a: b\n\", s: \"it's\", h: \"# not a comment\"}"},
+		{"comments", "# top\na: 1 # one\nurl: http://x#y", `{a: 1, url: "http://x#y"}`},
+		{"nested mappings", "a:\n  b:\n    c: 1\n  d: 2", `{a: {b: {c: 1}, d: 2}}`},
+		{"sequences", "- 1\n- two\n-\n  - nested", `[1, "two", ["nested"]]`},
+		{"sequence at key indentation", "items:\n- a\n- b\nafter: 1", `{items: ["a", "b"], after: 1}`},
+		{"mappings in sequences", "- name: a\n  port: 80\n- name: b", `[{name: "a", port: 80}, {name: "b"}]`},
+		{"flow collections", "l: [1, 'two', {k: v}]\nm: {a: 1,\n  b: [x]}", `{l: [1, "two", {k: "v"}], m: {a: 1, b: ["x"]}}`},
+		{"literal block", "s: |\n  one\n  two\nn: 1", "{s: \"one\ntwo\n\", n: 1}"},
+		{"folded block", "s: >-\n  one\n  two\n\n  three\n\n\n  four\n", "{s: \"one two\nthree\n\nfour\"}"},
+		{"folded plain scalar", "s: one\n  two", `{s: "one two"}`},
+		{"anchors and tags", "a: &base !!str x\nb: *base", `{a: "x", b: "*base"}`},
+		{"quoted keys", "\"a b\": 1\n'c': 2", `{a b: 1, c: 2}`},
+		{"documents", "a: 1\n---\nb: 2\n...\n", `[{a: 1}, {b: 2}]`},
+		{"empty", "# nothing\n", `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseYAML(tt.source)
+			if err != nil {
+				t.Fatalf("ParseYAML() error = %v", err)
+			}
+			if got := dump(node); got != tt.want {
+				t.Errorf("ParseYAML() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"mapping in a plain scalar", "a: b: c", "line 1: mapping values are not allowed here"},
+		{"mapping in a folded scalar", "x: 1\na: b\n  c: d", "line 2: mapping values are not allowed here"},
+		{"plain scalar ending in a colon", "a: b:", "line 1: mapping values are not allowed here"},
+		{"deeper indentation", "a: 1\n  b: 2", "line 1: mapping values are not allowed here"},
+		{"unexpected indentation", "a:\n    b: 1\n  c: 2", "line 3: unexpected indentation"},
+		{"not a key", "a: 1\njust text", `line 2: expected "key: value"`},
+		{"unclosed flow collection", "a: [1, 2", `line 1: expected ']'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAML(tt.source)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ParseYAML(%q) error = %v, want %q", tt.source, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLAllowsColons(t *testing.T) {
+	for _, source := range []string{
+		"url: https://example.com:8080/path",
+		"time: 12:30",
+		"quoted: \"a: b\"",
+		"list: [a, b]",
+	} {
+		if _, err := ParseYAML(source); err != nil {
+			t.Errorf("ParseYAML(%q) error = %v", source, err)
+		}
+	}
+	if node, _ := ParseYAML("k: 'a: b'"); !strings.Contains(dump(node), `"a: b"`) {
+		t.Errorf("quoted value lost: %s", dump(node))
+	}
+}
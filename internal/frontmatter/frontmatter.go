@@ -0,0 +1,401 @@
+// Package frontmatter reads metadata blocks at the top of markdown documents.
+// YAML blocks are delimited by --- lines and TOML blocks by +++ lines; only
+// top-level scalars and lists of scalars are read.
+package frontmatter
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHeaderLines bounds how far ReadFile looks for the closing delimiter
+const maxHeaderLines = 200
+
+// Format is the syntax a front matter block is written in
+type Format int
+
+const (
+	// YAML front matter is delimited by --- lines
+	YAML Format = iota
+
+	// TOML front matter is delimited by +++ lines
+	TOML
+)
+
+// String returns the format name
+func (f Format) String() string {
+	if f == TOML {
+		return "toml"
+	}
+	return "yaml"
+}
+
+// Matter holds the fields parsed from a front matter block
+type Matter struct {
+	// Format is the syntax the block was written in
+	Format Format
+
+	// Fields maps lower-cased keys to their scalar values (lists joined with ", ")
+	Fields map[string]string
+
+	// Lists maps lower-cased keys of list-valued fields to their items
+	Lists map[string][]string
+
+	// Keys lists field keys in document order
+	Keys []string
+
+	// Lines is the number of lines the block occupies, including delimiters
+	Lines int
+}
+
+// Get returns the value for a key (case-insensitive), or ""
+func (m *Matter) Get(key string) string {
+	if m == nil {
+		return ""
+	}
+	return m.Fields[strings.ToLower(key)]
+}
+
+// List returns the items of a list field. Scalar fields are split on commas,
+// so "tags: a, b" and "tags: [a, b]" read the same.
+func (m *Matter) List(key string) []string {
+	if m == nil {
+		return nil
+	}
+	key = strings.ToLower(key)
+	if items, ok := m.Lists[key]; ok {
+		return items
+	}
+
+	var items []string
+	for _, item := range strings.Split(m.Fields[key], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Title returns the title field
+func (m *Matter) Title() string {
+	return m.Get("title")
+}
+
+// Date parses the date field, returning the zero time if absent or unparseable
+func (m *Matter) Date() time.Time {
+	return ParseDate(m.Get("date"))
+}
+
+// Weight parses the weight field, reporting whether it was present and numeric
+func (m *Matter) Weight() (float64, bool) {
+	v := m.Get("weight")
+	if v == "" {
+		return 0, false
+	}
+	w, err := strconv.ParseFloat(v, 64)
+	return w, err == nil
+}
+
+// dateLayouts are the date formats accepted in front matter
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+// ParseDate parses a date in one of the common front matter formats
+func ParseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Parse parses a front matter block at the start of content.
+// Returns nil if the content has no front matter.
+func Parse(content string) *Matter {
+	m, _ := Split(content)
+	return m
+}
+
+// Split separates the front matter block from the rest of the document.
+// Returns a nil Matter and the unchanged content if there is no block.
+func Split(content string) (*Matter, string) {
+	lines := strings.Split(content, "\n")
+
+	format, ok := opening(lines[0])
+	if !ok {
+		return nil, content
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if closes(format, lines[i]) {
+			var m *Matter
+			if format == TOML {
+				m = parseTOML(lines[1:i])
+			} else {
+				m = parseYAML(lines[1:i])
+			}
+			m.Lines = i + 1
+			return m, strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return nil, content
+}
+
+// opening reports whether a line opens a front matter block, and its format
+func opening(line string) (Format, bool) {
+	switch strings.TrimRight(line, " \r") {
+	case "---":
+		return YAML, true
+	case "+++":
+		return TOML, true
+	}
+	return YAML, false
+}
+
+// closes reports whether a line closes a block of the given format
+func closes(format Format, line string) bool {
+	delim := strings.TrimRight(line, " \r")
+	if format == TOML {
+		return delim == "+++"
+	}
+	return delim == "---" || delim == "..."
+}
+
+// ReadFile parses the front matter of a file, reading only its header lines
+func ReadFile(path string) (*Matter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	var format Format
+	scanner := bufio.NewScanner(f)
+	for n := 0; scanner.Scan() && n < maxHeaderLines; n++ {
+		line := scanner.Text()
+		b.WriteString(line)
+		b.WriteByte('\n')
+
+		// Stop at the closing delimiter (or straight away if there's no block)
+		if n == 0 {
+			var ok bool
+			if format, ok = opening(line); !ok {
+				return nil, nil
+			}
+			continue
+		}
+		if closes(format, line) {
+			break
+		}
+	}
+
+	return Parse(b.String()), scanner.Err()
+}
+
+// newMatter creates an empty Matter
+func newMatter(format Format) *Matter {
+	return &Matter{
+		Format: format,
+		Fields: make(map[string]string),
+		Lists:  make(map[string][]string),
+	}
+}
+
+// set records a scalar field
+func (m *Matter) set(key, value string) {
+	key = strings.ToLower(key)
+	if _, seen := m.Fields[key]; !seen {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Fields[key] = value
+	delete(m.Lists, key)
+}
+
+// setList records a list field
+func (m *Matter) setList(key string, items []string) {
+	m.set(key, strings.Join(items, ", "))
+	m.Lists[strings.ToLower(key)] = items
+}
+
+// parseYAML reads top-level "key: value" pairs, inline [a, b] lists, block
+// "- item" lists and |/> block scalars, ignoring nested mappings
+func parseYAML(lines []string) *Matter {
+	m := newMatter(YAML)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \r")
+		if line == "" || isIndented(line) || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = stripComment(strings.TrimSpace(value))
+
+		// Collect the indented lines belonging to this key
+		var nested []string
+		for i+1 < len(lines) {
+			next := strings.TrimRight(lines[i+1], " \r")
+			if next != "" && !isIndented(next) && !strings.HasPrefix(next, "- ") && next != "-" {
+				break
+			}
+			nested = append(nested, next)
+			i++
+		}
+
+		switch {
+		case strings.HasPrefix(value, "["):
+			m.setList(key, splitList(value))
+
+		case value == "|" || value == ">" || strings.HasPrefix(value, "|-") || strings.HasPrefix(value, ">-"):
+			sep := "\n"
+			if value[0] == '>' {
+				sep = " "
+			}
+			var parts []string
+			for _, n := range nested {
+				parts = append(parts, strings.TrimSpace(n))
+			}
+			m.set(key, strings.TrimSpace(strings.Join(parts, sep)))
+
+		case value == "":
+			var items []string
+			for _, n := range nested {
+				n = strings.TrimSpace(n)
+				if item, ok := strings.CutPrefix(n, "-"); ok {
+					items = append(items, unquote(stripComment(strings.TrimSpace(item))))
+				}
+			}
+			if len(items) > 0 {
+				m.setList(key, items)
+			} else if len(nested) == 0 {
+				m.set(key, "")
+			}
+			// Nested mappings are skipped
+
+		default:
+			m.set(key, unquote(value))
+		}
+	}
+
+	return m
+}
+
+// parseTOML reads top-level "key = value" pairs and single-line arrays,
+// stopping at the first [table] header
+func parseTOML(lines []string) *Matter {
+	m := newMatter(TOML)
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			break // Tables hold nested keys
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = unquote(strings.TrimSpace(key))
+		value = stripComment(strings.TrimSpace(value))
+
+		if strings.HasPrefix(value, "[") {
+			m.setList(key, splitList(value))
+		} else {
+			m.set(key, unquote(value))
+		}
+	}
+
+	return m
+}
+
+// splitList splits an inline [a, "b", 'c'] list into unquoted items
+func splitList(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[")
+	value = strings.TrimSuffix(value, "]")
+
+	var items []string
+	var current strings.Builder
+	var quote byte
+	flush := func() {
+		if item := unquote(strings.TrimSpace(current.String())); item != "" {
+			items = append(items, item)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			current.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+			current.WriteByte(c)
+		case c == ',':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return items
+}
+
+// stripComment removes a trailing " # comment" outside quotes
+func stripComment(value string) string {
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// isIndented reports whether a line starts with whitespace
+func isIndented(line string) bool {
+	return line[0] == ' ' || line[0] == '\t'
+}
+
+// unquote strips matching single or double quotes from a scalar
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  Format
+		fields  map[string]string
+		lists   map[string][]string
+		keys    []string
+		body    string
+	}{
+		{
+			name:    "yaml scalars",
+			content: "---\nTitle: Notes\ndate: 2024-03-01\nquoted: \"a: b\"\nsingle: 'x'\n---\nBody",
+			format:  YAML,
+			fields:  map[string]string{"title": "Notes", "date": "2024-03-01", "quoted": "a: b", "single": "x"},
+			lists:   map[string][]string{},
+			keys:    []string{"title", "date", "quoted", "single"},
+			body:    "Body",
+		},
+		{
+			name:    "yaml lists",
+			content: "---\ntags: [go, \"tui, cli\", 'md']\nauthors:\n  - Ann\n  - Bo # lead\n---\n",
+			format:  YAML,
+			fields:  map[string]string{"tags": "go, tui, cli, md", "authors": "Ann, Bo"},
+			lists:   map[string][]string{"tags": {"go", "tui, cli", "md"}, "authors": {"Ann", "Bo"}},
+			keys:    []string{"tags", "authors"},
+			body:    "",
+		},
+		{
+			name:    "yaml block scalars",
+			content: "---\nliteral: |\n  one\n  two\nfolded: >\n  one\n  two\n---\n",
+			format:  YAML,
+			fields:  map[string]string{"literal": "one\ntwo", "folded": "one two"},
+			lists:   map[string][]string{},
+			keys:    []string{"literal", "folded"},
+			body:    "",
+		},
+		{
+			name:    "yaml comments and nested mappings",
+			content: "---\n# heading\nstatus: draft # for now\nseo:\n  title: Other\nurl: http://x#y\n...\nBody",
+			format:  YAML,
+			fields:  map[string]string{"status": "draft", "url": "http://x#y"},
+			lists:   map[string][]string{},
+			keys:    []string{"status", "url"},
+			body:    "Body",
+		},
+		{
+			name:    "toml",
+			content: "+++\ntitle = \"Notes\"\ntags = [\"a\", \"b\"]\nweight = 3 # order\n[params]\nhidden = true\n+++\nBody",
+			format:  TOML,
+			fields:  map[string]string{"title": "Notes", "tags": "a, b", "weight": "3"},
+			lists:   map[string][]string{"tags": {"a", "b"}},
+			keys:    []string{"title", "tags", "weight"},
+			body:    "Body",
+		},
+		{
+			name:    "crlf line endings",
+			content: "---\r\ntitle: Notes\r\n---\r\nBody",
+			format:  YAML,
+			fields:  map[string]string{"title": "Notes"},
+			lists:   map[string][]string{},
+			keys:    []string{"title"},
+			body:    "Body",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, body := Split(tt.content)
+			if m == nil {
+				t.Fatal("Split found no front matter")
+			}
+			if m.Format != tt.format {
+				t.Errorf("Format = %v, want %v", m.Format, tt.format)
+			}
+			if !reflect.DeepEqual(m.Fields, tt.fields) {
+				t.Errorf("Fields = %q, want %q", m.Fields, tt.fields)
+			}
+			if !reflect.DeepEqual(m.Lists, tt.lists) {
+				t.Errorf("Lists = %q, want %q", m.Lists, tt.lists)
+			}
+			if !reflect.DeepEqual(m.Keys, tt.keys) {
+				t.Errorf("Keys = %q, want %q", m.Keys, tt.keys)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestSplitWithoutMatter(t *testing.T) {
+	tests := []string{
+		"# Title\n\n---\n",
+		"---\ntitle: never closed\n",
+		"+++\ntitle = \"x\"\n---\n",
+		"",
+	}
+	for _, content := range tests {
+		if m, body := Split(content); m != nil || body != content {
+			t.Errorf("Split(%q) = %v, %q; want no front matter", content, m, body)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	m := Parse("---\ntags: a, b ,c\nkeywords: [x]\n---\n")
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"tags", []string{"a", "b", "c"}},
+		{"Keywords", []string{"x"}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		if got := m.List(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("List(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024/03/01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-01 09:30", time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+		{"March 1, 2024", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"  1 March 2024 ", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"soon", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := ParseDate(tt.in); !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWeight(t *testing.T) {
+	tests := []struct {
+		content string
+		want    float64
+		ok      bool
+	}{
+		{"---\nweight: 2.5\n---\n", 2.5, true},
+		{"---\nweight: heavy\n---\n", 0, false},
+		{"---\ntitle: x\n---\n", 0, false},
+	}
+	for _, tt := range tests {
+		w, ok := Parse(tt.content).Weight()
+		if w != tt.want || ok != tt.ok {
+			t.Errorf("Weight() of %q = %v, %v; want %v, %v", tt.content, w, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package fsutil holds the file system helpers shared by the tree, the
+// preview and the config
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFile writes data to a temporary file beside path and renames it over
+// path, so a crash or a reader never sees a half-written file
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// IsWithin reports whether path equals base or is inside it. Both paths
+// should be clean and either both absolute or both relative.
+func IsWithin(path, base string) bool {
+	return path == base || strings.HasPrefix(path, base+string(filepath.Separator))
+}
@@ -0,0 +1,71 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		path, base string
+		want       bool
+	}{
+		{"/a/b", "/a/b", true},
+		{"/a/b/c.md", "/a/b", true},
+		{"/a/bc", "/a/b", false},
+		{"/a", "/a/b", false},
+		{"docs/x", "docs", true},
+	}
+	for _, tt := range tests {
+		if got := IsWithin(filepath.FromSlash(tt.path), filepath.FromSlash(tt.base)); got != tt.want {
+			t.Errorf("IsWithin(%q, %q) = %v, want %v", tt.path, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("content = %q, %v; want %q", got, err, "new")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want the temporary file gone", len(entries))
+	}
+}
+
+func TestRename(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Rename(a, b); !errors.Is(err, os.ErrExist) {
+		t.Errorf("Rename onto a file: error = %v, want os.ErrExist", err)
+	}
+	if got, _ := os.ReadFile(b); string(got) != "b" {
+		t.Errorf("b = %q, want it untouched", got)
+	}
+	if err := Rename(a, c); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(c); string(got) != "a" {
+		t.Errorf("c = %q, want %q", got, "a")
+	}
+}
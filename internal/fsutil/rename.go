@@ -0,0 +1,25 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+)
+
+// Rename renames oldPath to newPath like os.Rename, but fails with an error
+// matching os.ErrExist instead of replacing a path that already exists
+func Rename(oldPath, newPath string) error {
+	err := renameNoReplace(oldPath, newPath)
+	if !errors.Is(err, errUnsupported) {
+		return err
+	}
+
+	// The file system can't rename exclusively; check first
+	if _, err := os.Lstat(newPath); err == nil {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: os.ErrExist}
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// errUnsupported is returned by renameNoReplace when the platform or file
+// system has no exclusive rename
+var errUnsupported = errors.New("exclusive rename not supported")
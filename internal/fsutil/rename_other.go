@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package fsutil
+
+// renameNoReplace has no exclusive rename to call on this platform
+func renameNoReplace(oldPath, newPath string) error {
+	return errUnsupported
+}
@@ -0,0 +1,345 @@
+// Package ignore implements gitignore-style path matching for directory scanning.
+//
+// Rules are read from (lowest to highest precedence) the caller's default
+// patterns, the global git excludes file, .git/info/exclude, and the
+// .gitignore and .skimignore files in every directory from the repository
+// root down to the path being matched.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Ayushlm10/skim/internal/fsutil"
+)
+
+// IgnoreFileName is skim's own ignore file, read after .gitignore in each directory
+const IgnoreFileName = ".skimignore"
+
+// rule is a single compiled pattern
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ruleSet holds the rules read from one directory's ignore files.
+// Patterns are matched against paths relative to dir.
+type ruleSet struct {
+	dir   string
+	rules []rule
+}
+
+// Matcher decides whether paths under a root are ignored
+type Matcher struct {
+	// root is the directory being scanned
+	root string
+
+	// repoRoot is the enclosing git work tree (or root when not in a repo)
+	repoRoot string
+
+	// defaults are gitignore patterns applied before any ignore file
+	defaults []string
+
+	// base holds the defaults, global excludes and .git/info/exclude,
+	// relative to repoRoot
+	base *ruleSet
+
+	mu   sync.Mutex
+	dirs map[string]*ruleSet
+}
+
+// New creates a matcher for paths under root. The defaults are gitignore
+// patterns with the lowest precedence, so any ignore file can override them.
+func New(root string, defaults ...string) *Matcher {
+	m := &Matcher{root: root, defaults: defaults}
+	m.Reset()
+	return m
+}
+
+// Reset forgets all loaded ignore files so edits to them take effect
+func (m *Matcher) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.repoRoot = findRepoRoot(m.root)
+	m.dirs = make(map[string]*ruleSet)
+	m.base = &ruleSet{dir: m.repoRoot}
+
+	for _, pattern := range m.defaults {
+		if r, ok := parseRule(pattern); ok {
+			m.base.rules = append(m.base.rules, r)
+		}
+	}
+	if path := globalExcludesFile(); path != "" {
+		m.base.rules = append(m.base.rules, readRules(path)...)
+	}
+	m.base.rules = append(m.base.rules, readRules(filepath.Join(m.repoRoot, ".git", "info", "exclude"))...)
+}
+
+// Match reports whether path is ignored. Ancestor directories are not
+// checked; scanners skip ignored directories before descending into them.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sets := []*ruleSet{m.base}
+	for _, dir := range m.chain(filepath.Dir(path)) {
+		sets = append(sets, m.load(dir))
+	}
+
+	// The last matching rule wins, with deeper files taking precedence
+	ignored := false
+	for _, set := range sets {
+		rel, err := filepath.Rel(set.dir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, r := range set.rules {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.pattern.MatchString(rel) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// chain returns the directories from the repository root down to dir
+func (m *Matcher) chain(dir string) []string {
+	var dirs []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if !fsutil.IsWithin(d, m.repoRoot) {
+			break
+		}
+		dirs = append(dirs, d)
+		if d == m.repoRoot || d == filepath.Dir(d) {
+			break
+		}
+	}
+
+	// Reverse so shallower directories come first
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
+
+// load returns the cached rules for a directory, reading its ignore files on first use
+func (m *Matcher) load(dir string) *ruleSet {
+	if set, ok := m.dirs[dir]; ok {
+		return set
+	}
+
+	set := &ruleSet{dir: dir}
+	set.rules = append(set.rules, readRules(filepath.Join(dir, ".gitignore"))...)
+	set.rules = append(set.rules, readRules(filepath.Join(dir, IgnoreFileName))...)
+	m.dirs[dir] = set
+	return set
+}
+
+// readRules parses an ignore file, returning nil if it can't be read
+func readRules(path string) []rule {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseRule compiles one line of an ignore file
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// Trailing spaces are ignored unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to the file's directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.pattern = re
+	return r, true
+}
+
+// globToRegexp translates gitignore glob syntax (*, ?, [...], **) to a regexp
+func globToRegexp(glob string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				atEnd := i+2 == len(glob)
+				if atStart && atEnd {
+					// "**" on its own or trailing "/**": everything inside
+					b.WriteString(".*")
+					i++
+					continue
+				}
+				if atStart && glob[i+2] == '/' {
+					// "**/": zero or more leading directories
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+
+		case '?':
+			b.WriteString("[^/]")
+
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
+
+// findRepoRoot returns the nearest ancestor of dir containing .git, or dir itself
+func findRepoRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		if d == filepath.Dir(d) {
+			return dir
+		}
+	}
+}
+
+// globalExcludesFile locates git's global excludes file: core.excludesFile
+// from the user's git config, or the XDG default location
+func globalExcludesFile() string {
+	home, _ := os.UserHomeDir()
+
+	configs := []string{}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		configs = append(configs, filepath.Join(xdg, "git", "config"))
+	} else if home != "" {
+		configs = append(configs, filepath.Join(home, ".config", "git", "config"))
+	}
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+
+	// Later config files take precedence, as with git
+	excludes := ""
+	for _, config := range configs {
+		if path := readExcludesSetting(config); path != "" {
+			excludes = path
+		}
+	}
+	if excludes != "" {
+		if strings.HasPrefix(excludes, "~/") && home != "" {
+			excludes = filepath.Join(home, excludes[2:])
+		}
+		return excludes
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	if home != "" {
+		return filepath.Join(home, ".config", "git", "ignore")
+	}
+	return ""
+}
+
+// readExcludesSetting reads core.excludesFile from a git config file
+func readExcludesSetting(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inCore := false
+	value := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inCore = strings.EqualFold(strings.Trim(line, "[] \t"), "core")
+			continue
+		}
+		if !inCore {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			value = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return value
+}
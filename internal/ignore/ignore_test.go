@@ -0,0 +1,103 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files under root, making their directories
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	// Keep the user's global excludes out of the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{
+		".git/info/exclude": "secret.md\n",
+		".gitignore": "# comment\n" +
+			"*.log\n" +
+			"!keep.log\n" +
+			"build/\n" +
+			"/top.md\n" +
+			"docs/**/draft.md\n" +
+			"\\#hash.md\n" +
+			"!vendor/\n" +
+			"trailing.md   \n",
+		"sub/.gitignore":  "!debug.log\nlocal.md\n",
+		"sub/.skimignore": "!local.md\n",
+	})
+
+	m := New(root, "node_modules/", "vendor/")
+
+	tests := []struct {
+		name  string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"plain file", "readme.md", false, false},
+		{"glob", "app.log", false, true},
+		{"negated glob", "keep.log", false, false},
+		{"glob matches in subdirectories", "sub/deeper/app.log", false, true},
+		{"nested negation overrides parent", "sub/debug.log", false, false},
+		{"nested negation is local", "other/debug.log", false, true},
+		{"directory pattern on a directory", "build", true, true},
+		{"directory pattern on a file", "build", false, false},
+		{"anchored pattern at its directory", "top.md", false, true},
+		{"anchored pattern elsewhere", "sub/top.md", false, false},
+		{"double star with no directories", "docs/draft.md", false, true},
+		{"double star with directories", "docs/a/b/draft.md", false, true},
+		{"escaped hash", "#hash.md", false, true},
+		{"trailing spaces trimmed", "trailing.md", false, true},
+		{"info exclude", "secret.md", false, true},
+		{"skimignore overrides gitignore", "sub/local.md", false, false},
+		{"default pattern", "node_modules", true, true},
+		{"default pattern nested", "sub/node_modules", true, true},
+		{"default overridden by ignore file", "vendor", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			if got := m.Match(path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"*.md", `[^/]*\.md`},
+		{"a?c", `a[^/]c`},
+		{"[!ab].txt", `[^ab]\.txt`},
+		{"[abc", `\[abc`},
+		{"**/x", `(?:.*/)?x`},
+		{"x/**", `x/.*`},
+		{`\*`, `\*`},
+	}
+	for _, tt := range tests {
+		if got := globToRegexp(tt.glob); got != tt.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package images
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"strings"
+)
+
+// maxSamples caps the source pixels averaged along each axis for one
+// scaled pixel, so large photos scale quickly
+const maxSamples = 4
+
+// scale resizes an image to w by h pixels, averaging the source pixels each
+// one covers
+func scale(src image.Image, w, h int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		stepY := max(1, (y1-y0)/maxSamples)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			stepX := max(1, (x1-x0)/maxSamples)
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy += stepY {
+				for sx := x0; sx < x1; sx += stepX {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+cr, g+cg, bl+cb, a+ca, n+1
+				}
+			}
+			premultiplied := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)}
+			dst.SetNRGBA(x, y, color.NRGBAModel.Convert(premultiplied).(color.NRGBA))
+		}
+	}
+	return dst
+}
+
+// opaque reports whether a pixel is solid enough to draw
+func opaque(c color.NRGBA) bool {
+	return c.A >= 128
+}
+
+// halfBlocks draws an image two pixels per cell, the upper one as the
+// foreground of "▀" and the lower one as its background. Transparent
+// pixels are left to the terminal background.
+func halfBlocks(img *image.NRGBA) []string {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	lines := make([]string, 0, ceilDiv(h, 2))
+	for y := 0; y < h; y += 2 {
+		var line strings.Builder
+		for x := 0; x < w; x++ {
+			top, bottom := img.NRGBAAt(x, y), color.NRGBA{}
+			if y+1 < h {
+				bottom = img.NRGBAAt(x, y+1)
+			}
+			switch {
+			case !opaque(top) && !opaque(bottom):
+				line.WriteString("\x1b[0m ")
+			case !opaque(bottom):
+				fmt.Fprintf(&line, "\x1b[0;38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			case !opaque(top):
+				fmt.Fprintf(&line, "\x1b[0;38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			default:
+				fmt.Fprintf(&line, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		line.WriteString("\x1b[0m")
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// encodePNG returns an image as a PNG file
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img) // writing to memory can't fail
+	return buf.Bytes()
+}
+
+// kittyChunk is the most base64 data the kitty protocol takes per escape
+const kittyChunk = 4096
+
+// kitty encodes an image for the kitty graphics protocol, sending it to the
+// terminal as image id without showing it. The data is sent as PNG in
+// chunks; q=2 silences the terminal's replies.
+func kitty(img image.Image, id uint32) string {
+	data := base64.StdEncoding.EncodeToString(encodePNG(img))
+	var s strings.Builder
+	for start := 0; start < len(data); start += kittyChunk {
+		end := min(start+kittyChunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if start == 0 {
+			fmt.Fprintf(&s, "\x1b_Ga=t,f=100,i=%d,q=2,m=%d;", id, more)
+		} else {
+			fmt.Fprintf(&s, "\x1b_Gm=%d;", more)
+		}
+		s.WriteString(data[start:end])
+		s.WriteString("\x1b\\")
+	}
+	return s.String()
+}
+
+// iterm encodes an image as an iTerm2 inline image stretched over cols by
+// rows cells, leaving the cursor in place
+func iterm(img image.Image, cols, rows int) string {
+	data := encodePNG(img)
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0;doNotMoveCursor=1:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixel encodes an image as DEC sixel graphics, dithered to a 256 color
+// palette. Transparent pixels are left unpainted.
+func sixel(img *image.NRGBA) string {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	p := image.NewPaletted(img.Rect, palette.Plan9)
+	draw.FloydSteinberg.Draw(p, p.Rect, img, image.Point{})
+
+	var s strings.Builder
+	// P2=1 keeps the background of unpainted pixels; the raster attributes
+	// give a 1:1 pixel aspect and the size
+	fmt.Fprintf(&s, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+
+	used := make([]bool, len(p.Palette))
+	for _, c := range p.Pix {
+		used[c] = true
+	}
+	for i, c := range p.Palette {
+		if used[i] {
+			r, g, b, _ := c.RGBA()
+			fmt.Fprintf(&s, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+		}
+	}
+
+	// Each band is six pixel rows, drawn once per color it uses
+	for top := 0; top < h; top += 6 {
+		bits := make(map[uint8][]byte)
+		var order []uint8
+		for dy := 0; dy < 6 && top+dy < h; dy++ {
+			for x := 0; x < w; x++ {
+				if !opaque(img.NRGBAAt(x, top+dy)) {
+					continue
+				}
+				c := p.ColorIndexAt(x, top+dy)
+				if bits[c] == nil {
+					bits[c] = make([]byte, w)
+					order = append(order, c)
+				}
+				bits[c][x] |= 1 << dy
+			}
+		}
+
+		for i, c := range order {
+			if i > 0 {
+				s.WriteByte('$') // back to the start of the band
+			}
+			fmt.Fprintf(&s, "#%d", c)
+			writeSixels(&s, bits[c])
+		}
+		s.WriteByte('-')
+	}
+	s.WriteString("\x1b\\")
+	return s.String()
+}
+
+// writeSixels writes one color's row of a band, run-length encoded and
+// without the empty sixels at its end
+func writeSixels(s *strings.Builder, bits []byte) {
+	for len(bits) > 0 && bits[len(bits)-1] == 0 {
+		bits = bits[:len(bits)-1]
+	}
+	for x := 0; x < len(bits); {
+		run := 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+		ch := byte('?' + bits[x])
+		if run > 3 {
+			fmt.Fprintf(s, "!%d%c", run, ch)
+		} else {
+			for range run {
+				s.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
@@ -0,0 +1,441 @@
+// Package images draws pictures in the terminal, with the kitty graphics
+// protocol, iTerm2 inline images or sixel where the terminal supports one,
+// and as Unicode half blocks otherwise
+package images
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder; the first frame is shown
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Protocol is a way of drawing images in the terminal
+type Protocol int
+
+const (
+	// Off leaves images as their alt text
+	Off Protocol = iota
+
+	// Blocks approximates images with colored half-block characters
+	Blocks
+
+	// Kitty uses the kitty graphics protocol (kitty, Ghostty)
+	Kitty
+
+	// ITerm uses iTerm2 inline images (iTerm2, WezTerm)
+	ITerm
+
+	// Sixel uses DEC sixel graphics (foot, mlterm)
+	Sixel
+)
+
+// protocolNames are the names used in the config file, indexed by Protocol
+var protocolNames = []string{"off", "blocks", "kitty", "iterm", "sixel"}
+
+// String returns the protocol's config name
+func (p Protocol) String() string {
+	if p < 0 || int(p) >= len(protocolNames) {
+		return "off"
+	}
+	return protocolNames[p]
+}
+
+// ParseProtocol reads a protocol from its config name. "auto", an empty
+// name or an unknown one detects what the terminal supports.
+func ParseProtocol(name string) Protocol {
+	for i, n := range protocolNames {
+		if strings.EqualFold(name, n) {
+			return Protocol(i)
+		}
+	}
+	return Detect()
+}
+
+// Detect guesses the best protocol from the environment. Graphics don't get
+// through tmux or screen, so blocks are used inside them.
+func Detect() Protocol {
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux"):
+		return Blocks
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return Blocks
+}
+
+// Graphical reports whether the terminal draws the pixels itself, rather
+// than skim approximating them with text
+func (p Protocol) Graphical() bool {
+	return p >= Kitty
+}
+
+// Terminals don't reliably report their cell size, so images are sized for
+// cells of this many pixels
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// Picture is an image scaled to fit a block of terminal cells
+type Picture struct {
+	Cols, Rows int
+
+	// Blocks holds the half-block approximation, one line per row
+	Blocks []string
+
+	// Graphic draws the image at the cursor with the graphics protocol
+	// ("" for Blocks). For kitty it only sends the image to the terminal
+	// under id; Place places it.
+	Graphic string
+
+	id   uint32 // kitty image id
+	sent bool   // whether the terminal holds the kitty image
+}
+
+// Placement is a picture drawn with its top-left corner at screen cell
+// (X, Y), counted from 0
+type Placement struct {
+	Pic  *Picture
+	X, Y int
+}
+
+// Terminal is the program's output, shared by the renderer and the kitty
+// commands. Each write finishes before the next starts, so an image is
+// never sent in the middle of a frame.
+type Terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+// Write writes b to the terminal
+func (t *Terminal) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(b)
+}
+
+// WriteString writes s to the terminal
+func (t *Terminal) WriteString(s string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.WriteString(s)
+}
+
+// Output is where kitty commands are written. They go to the terminal
+// directly rather than into the frame, since a frame is dropped when a
+// newer one replaces it before it is drawn; the program renders to the same
+// Terminal so the two don't interleave.
+var Output io.Writer = &Terminal{File: os.Stdout}
+
+// placement identifies a kitty placement: an image, and which of its copies
+// on screen
+type placement struct {
+	image, copy uint32
+}
+
+var (
+	drawMu  sync.Mutex
+	nextID  uint32                    // last kitty image id handed out
+	placed  map[placement]image.Point // kitty placements on screen and their cells
+	freed   []uint32                  // kitty images sent and since dropped from the cache
+	pending strings.Builder           // kitty commands waiting for Flush
+
+	flushMu sync.Mutex // keeps flushed commands in the order they were made
+)
+
+// Draw returns the sequences that draw the placements for one frame, with
+// the cursor left where it was. The protocols that paint over the text are
+// redrawn with every frame. Kitty keeps images apart from the text and
+// Place moves them instead, so nothing is returned for it.
+func (p Protocol) Draw(placements []Placement) string {
+	if p == Kitty {
+		return ""
+	}
+	var s strings.Builder
+	for _, pl := range placements {
+		if pl.Pic.Graphic != "" {
+			s.WriteString(ansi.SaveCursor + ansi.CursorPosition(pl.X+1, pl.Y+1) + pl.Pic.Graphic + ansi.RestoreCursor)
+		}
+	}
+	return s.String()
+}
+
+// Place works out the kitty commands that show exactly the placements: each
+// image is sent once and then only placed where it moved to, and placements
+// no longer shown are taken down. The commands wait for Flush; Place
+// reports whether there are any.
+func Place(placements []Placement) bool {
+	drawMu.Lock()
+	defer drawMu.Unlock()
+
+	for _, id := range freed {
+		fmt.Fprintf(&pending, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+		for pl := range placed {
+			if pl.image == id {
+				delete(placed, pl)
+			}
+		}
+	}
+	freed = nil
+
+	shown := make(map[placement]image.Point, len(placements))
+	for _, pl := range placements {
+		pic, at := pl.Pic, image.Pt(pl.X, pl.Y)
+		key := placement{image: pic.id, copy: 1}
+		for _, ok := shown[key]; ok; _, ok = shown[key] {
+			key.copy++
+		}
+		shown[key] = at
+
+		if !pic.sent {
+			pending.WriteString(pic.Graphic)
+			pic.sent = true
+		} else if cell, ok := placed[key]; ok && cell == at {
+			continue
+		}
+		// Placing again under the same placement id moves the copy
+		fmt.Fprintf(&pending, "%s%s\x1b_Ga=p,i=%d,p=%d,q=2,C=1,c=%d,r=%d\x1b\\%s",
+			ansi.SaveCursor, ansi.CursorPosition(at.X+1, at.Y+1), key.image, key.copy, pic.Cols, pic.Rows, ansi.RestoreCursor)
+	}
+	for key := range placed {
+		if _, ok := shown[key]; !ok {
+			// Lowercase d=i takes the placement down and keeps the image
+			fmt.Fprintf(&pending, "\x1b_Ga=d,d=i,i=%d,p=%d,q=2\x1b\\", key.image, key.copy)
+		}
+	}
+	placed = shown
+	return pending.Len() > 0
+}
+
+// Flush writes the kitty commands Place left waiting to Output
+func Flush() {
+	flushMu.Lock()
+	defer flushMu.Unlock()
+
+	drawMu.Lock()
+	s := pending.String()
+	pending.Reset()
+	drawMu.Unlock()
+
+	if s != "" {
+		_, _ = io.WriteString(Output, s)
+	}
+}
+
+// ErrUnsupported is returned for files that aren't PNG, JPEG or GIF images
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrNotDecoded is returned by Load until Decode has read the file
+var ErrNotDecoded = errors.New("image not decoded yet")
+
+// ErrTooLarge is returned for images with more than maxPixels pixels
+var ErrTooLarge = errors.New("image too large")
+
+// maxPixels caps the size of an image that is decoded, since a decoded
+// image takes four bytes or more per pixel whatever the file's size
+const maxPixels = 40_000_000
+
+// Supported reports whether a file has the extension of an image that can
+// be shown
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+// maxCached bounds each cache; a full cache is emptied
+const maxCached = 64
+
+// decodedKey identifies a version of an image file
+type decodedKey struct {
+	path    string
+	modTime time.Time
+}
+
+// decodedImage is an image file read by Decode, or why it couldn't be
+type decodedImage struct {
+	img image.Image
+	err error
+}
+
+// pictureKey identifies a picture rendered from a file
+type pictureKey struct {
+	decodedKey
+	cols, rows int
+	protocol   Protocol
+}
+
+var (
+	cacheMu  sync.Mutex
+	decoded  = make(map[decodedKey]decodedImage)
+	pictures = make(map[pictureKey]*Picture)
+)
+
+// Load scales the image at path to fit maxCols by maxRows cells, never
+// enlarging it. The file must have been decoded with Decode first, or
+// ErrNotDecoded is returned. Decoded images and pictures are cached until
+// the file changes, so resizing the preview only scales them again.
+func Load(path string, maxCols, maxRows int, protocol Protocol) (*Picture, error) {
+	if !Supported(path) {
+		return nil, ErrUnsupported
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	file := decodedKey{path: path, modTime: info.ModTime()}
+	key := pictureKey{decodedKey: file, cols: maxCols, rows: maxRows, protocol: protocol}
+	if pic, ok := pictures[key]; ok {
+		return pic, nil
+	}
+
+	d, ok := decoded[file]
+	switch {
+	case !ok:
+		return nil, ErrNotDecoded
+	case d.err != nil:
+		return nil, d.err
+	}
+
+	pic := render(d.img, maxCols, maxRows, protocol)
+	if len(pictures) >= maxCached {
+		drop()
+	}
+	pictures[key] = pic
+	return pic, nil
+}
+
+// Decode reads the image at path for Load, unless the current version of
+// the file was read already. It can take a while, so it is meant to run
+// outside the UI; a failure is kept for Load to return.
+func Decode(path string) {
+	if !Supported(path) {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	file := decodedKey{path: path, modTime: info.ModTime()}
+
+	cacheMu.Lock()
+	_, ok := decoded[file]
+	cacheMu.Unlock()
+	if ok {
+		return
+	}
+
+	img, err := decode(path)
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if len(decoded) >= maxCached {
+		clear(decoded)
+	}
+	decoded[file] = decodedImage{img: img, err: err}
+}
+
+// drop empties the picture cache, freeing the kitty images the terminal
+// holds for it on the next Place. A picture still in view is sent again.
+func drop() {
+	drawMu.Lock()
+	defer drawMu.Unlock()
+	for _, pic := range pictures {
+		if pic.sent {
+			freed = append(freed, pic.id)
+			pic.sent = false
+		}
+	}
+	clear(pictures)
+}
+
+// decode reads an image file, checking its size before decoding it
+func decode(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+	switch {
+	case err != nil:
+		return nil, err
+	case config.Width <= 0 || config.Height <= 0:
+		return nil, errors.New("empty image")
+	case int64(config.Width)*int64(config.Height) > maxPixels:
+		return nil, ErrTooLarge
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		return nil, errors.New("empty image")
+	}
+	return img, nil
+}
+
+// render scales an image to its cells and encodes it for the protocol
+func render(img image.Image, maxCols, maxRows int, protocol Protocol) *Picture {
+	b := img.Bounds()
+	cols, rows := fit(b.Dx(), b.Dy(), maxCols, maxRows)
+	pic := &Picture{Cols: cols, Rows: rows, Blocks: halfBlocks(scale(img, cols, rows*2))}
+
+	switch protocol {
+	case Kitty:
+		drawMu.Lock()
+		nextID++
+		pic.id = nextID
+		drawMu.Unlock()
+		pic.Graphic = kitty(scale(img, cols*cellWidth, rows*cellHeight), pic.id)
+	case ITerm:
+		pic.Graphic = iterm(scale(img, cols*cellWidth, rows*cellHeight), cols, rows)
+	case Sixel:
+		pic.Graphic = sixel(scale(img, cols*cellWidth, rows*cellHeight))
+	}
+	return pic
+}
+
+// fit returns the cells an image of w by h pixels takes when scaled down to
+// fit maxCols by maxRows cells, keeping its aspect ratio
+func fit(w, h, maxCols, maxRows int) (cols, rows int) {
+	cols = min(max(maxCols, 1), ceilDiv(w, cellWidth))
+	rows = ceilDiv(h*cols*cellWidth/w, cellHeight)
+	if rows > maxRows {
+		rows = max(maxRows, 1)
+		cols = w * rows * cellHeight / (h * cellWidth)
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// ceilDiv divides rounding up
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePNG writes a w by h PNG to dir and returns its path
+func writePNG(t *testing.T, dir, name string, w, h int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// pngHeader returns the start of a PNG claiming to be w by h pixels, with
+// no image data after it
+func pngHeader(w, h uint32) []byte {
+	data := make([]byte, 13)
+	binary.BigEndian.PutUint32(data[0:], w)
+	binary.BigEndian.PutUint32(data[4:], h)
+	data[8], data[9] = 8, 6 // 8-bit RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	chunk := append([]byte("IHDR"), data...)
+	buf.Write(chunk)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	dir := t.TempDir()
+	small := writePNG(t, dir, "small.png", 40, 20)
+	huge := filepath.Join(dir, "huge.png")
+	if err := os.WriteFile(huge, pngHeader(100_000, 100_000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "broken.png")
+	if err := os.WriteFile(broken, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "small", path: small},
+		{name: "over the pixel limit", path: huge, wantErr: ErrTooLarge},
+		{name: "not an image", path: broken, wantErr: image.ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.path, 10, 10, Blocks); !errors.Is(err, ErrNotDecoded) {
+				t.Fatalf("Load before Decode: err = %v, want ErrNotDecoded", err)
+			}
+			Decode(tt.path)
+			pic, err := Load(tt.path, 10, 10, Blocks)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load after Decode: err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (pic.Cols != 4 || pic.Rows != 1 || len(pic.Blocks) != 1) {
+				t.Errorf("picture = %d by %d cells with %d rows of blocks, want 4 by 1", pic.Cols, pic.Rows, len(pic.Blocks))
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	var out bytes.Buffer
+	Output = &out
+	t.Cleanup(func() { Output = &Terminal{File: os.Stdout} })
+
+	pic := &Picture{Cols: 2, Rows: 1, Graphic: "<image>"}
+	pic.id = 7
+	at := func(x, y int) []Placement {
+		return []Placement{{Pic: pic, X: x, Y: y}}
+	}
+
+	steps := []struct {
+		name       string
+		placements []Placement
+		want       []string // commands, in order; nil when nothing is sent
+	}{
+		{"first frame sends and places", at(3, 4), []string{"<image>", "\x1b[5;4H\x1b_Ga=p,i=7,p=1,q=2,C=1,c=2,r=1\x1b\\"}},
+		{"unmoved", at(3, 4), nil},
+		{"moved", at(3, 2), []string{"\x1b[3;4H\x1b_Ga=p,i=7,p=1,q=2,C=1,c=2,r=1\x1b\\"}},
+		{"out of view", nil, []string{"\x1b_Ga=d,d=i,i=7,p=1,q=2\x1b\\"}},
+		{"back in view", at(0, 0), []string{"\x1b[1;1H\x1b_Ga=p,i=7,p=1,q=2,C=1,c=2,r=1\x1b\\"}},
+	}
+	for _, step := range steps {
+		out.Reset()
+		if got := Place(step.placements); got != (step.want != nil) {
+			t.Fatalf("%s: Place = %v, want %v", step.name, got, step.want != nil)
+		}
+		if Kitty.Draw(step.placements) != "" {
+			t.Errorf("%s: kitty drew into the frame", step.name)
+		}
+		Flush()
+		written := out.String()
+		for _, command := range step.want {
+			i := strings.Index(written, command)
+			if i < 0 {
+				t.Fatalf("%s: wrote %q, want %q in it", step.name, out.String(), command)
+			}
+			written = written[i+len(command):]
+		}
+		if step.want == nil && out.Len() > 0 {
+			t.Errorf("%s: wrote %q, want nothing", step.name, out.String())
+		}
+	}
+	Place(nil)
+	Flush()
+}
+
+func TestDrawPaintsOverTheFrame(t *testing.T) {
+	pic := &Picture{Cols: 1, Rows: 1, Graphic: "<sixel>"}
+	want := "\x1b7\x1b[2;3H<sixel>\x1b8"
+	if got := Sixel.Draw([]Placement{{Pic: pic, X: 2, Y: 1}}); got != want {
+		t.Errorf("Draw = %q, want %q", got, want)
+	}
+	if got := Sixel.Draw([]Placement{{Pic: &Picture{Cols: 1, Rows: 1, Blocks: []string{"▀"}}}}); got != "" {
+		t.Errorf("Draw of a picture without graphics = %q, want nothing", got)
+	}
+}
@@ -0,0 +1,109 @@
+package index
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+)
+
+// snippetWidth is how much of the linking line a backlink keeps
+const snippetWidth = 80
+
+// Backlink is a link to a document from another one
+type Backlink struct {
+	// Path is the linking document
+	Path string
+
+	// Line is the 1-based line of the link
+	Line int
+
+	// Snippet is the text around the link
+	Snippet string
+}
+
+// Backlinks reads every file in paths and returns the links (regular and
+// wiki) that point at target, in path order
+func Backlinks(target string, paths []string) []Backlink {
+	vault := mdlinks.NewVault(paths)
+
+	var backlinks []Backlink
+	for _, path := range paths {
+		if path == target {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		lines := strings.Split(string(content), "\n")
+		lastLine := -1
+		for _, link := range mdlinks.Extract(string(content)) {
+			var resolved string
+			if link.Kind == mdlinks.Wiki {
+				resolved = vault.Resolve(path, link.Target)
+			} else {
+				resolved = mdlinks.Resolve(path, link.Target)
+			}
+			// One entry per line is enough to show the context
+			if resolved != target || link.Line == lastLine {
+				continue
+			}
+			lastLine = link.Line
+
+			lineStart := link.Start
+			for _, line := range lines[:link.Line] {
+				lineStart -= len(line) + 1
+			}
+			backlinks = append(backlinks, Backlink{
+				Path:    path,
+				Line:    link.Line + 1,
+				Snippet: snippet(lines[link.Line], lineStart),
+			})
+		}
+	}
+	return backlinks
+}
+
+// snippet returns up to snippetWidth bytes of line around byte offset at,
+// with list markers and runs of whitespace removed
+func snippet(line string, at int) string {
+	start := 0
+	if at > snippetWidth/2 {
+		start = at - snippetWidth/2
+	}
+	end := start + snippetWidth
+	if end > len(line) {
+		end = len(line)
+	}
+
+	// Keep whole words (and whole UTF-8 sequences) at the edges
+	if start > 0 {
+		if idx := strings.IndexByte(line[start:end], ' '); idx != -1 {
+			start += idx + 1
+		}
+	}
+	if end < len(line) {
+		if idx := strings.LastIndexByte(line[start:end], ' '); idx != -1 {
+			end = start + idx
+		}
+	}
+	for start < end && !utf8.RuneStart(line[start]) {
+		start++
+	}
+	for end < len(line) && end > start && !utf8.RuneStart(line[end]) {
+		end--
+	}
+
+	text := strings.Join(strings.Fields(line[start:end]), " ")
+	text = strings.TrimLeft(text, "-*+> ")
+	if start > 0 {
+		text = "…" + text
+	}
+	if end < len(line) {
+		text += "…"
+	}
+	return text
+}
@@ -0,0 +1,81 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBacklinks(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Target Note.md": "# Target\n\n[[Other]]\n",
+		"wiki.md":        "Intro\n\n- see [[Target Note]] and [[target note#Usage|usage]] too\n",
+		"relative.md":    "Details in [the target](Target%20Note.md#top).\n",
+		"sub/deep.md":    "Up: [t](../Target%20Note.md)\n\n```\n[[Target Note]]\n```\n",
+		"unrelated.md":   "[[Other]] and [x](wiki.md)\n",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var got []string
+	for _, b := range Backlinks(filepath.Join(root, "Target Note.md"), paths) {
+		rel, _ := filepath.Rel(root, b.Path)
+		got = append(got, fmt.Sprintf("%s:%d: %s", filepath.ToSlash(rel), b.Line, b.Snippet))
+	}
+	want := []string{
+		"relative.md:1: Details in [the target](Target%20Note.md#top).",
+		"sub/deep.md:1: Up: [t](../Target%20Note.md)",
+		"wiki.md:3: see [[Target Note]] and [[target note#Usage|usage]] too",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backlinks =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("word ", 30) + "[[Target]] " + strings.Repeat("more ", 30)
+	at := strings.Index(long, "[[")
+
+	tests := []struct {
+		name string
+		line string
+		at   int
+		want string
+	}{
+		{"short line", "See [[Target]] here", 4, "See [[Target]] here"},
+		{"list marker and spacing", "  -   see   [[Target]]", 12, "see [[Target]]"},
+		{"quote", "> as [[Target]] says", 5, "as [[Target]] says"},
+		{
+			"long line keeps whole words around the link",
+			long, at,
+			"…word word word word word word word [[Target]] more more more more more…",
+		},
+		{
+			"multibyte text at the edge",
+			strings.Repeat("é", 60) + " [[Target]]", 121,
+			"…[[Target]]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.line, tt.at); got != tt.want {
+				t.Errorf("snippet = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
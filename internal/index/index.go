@@ -0,0 +1,230 @@
+// Package index collects tags, front matter values and links across a docs tree
+package index
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+)
+
+// TagField is the pseudo-field tags (front matter and #hashtags) are indexed under
+const TagField = "tags"
+
+// maxValueLength skips long free-text values, which make poor filters
+const maxValueLength = 40
+
+// skipFields are front matter keys whose values are unique per document
+var skipFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"summary":     true,
+	"date":        true,
+	"lastmod":     true,
+	"updated":     true,
+	"created":     true,
+	"weight":      true,
+	"slug":        true,
+	"aliases":     true,
+	"url":         true,
+}
+
+// hashtagPattern matches #tag, #nested/tag and #kebab-tag preceded by whitespace
+var hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+
+// Entry is one field value and the documents that have it
+type Entry struct {
+	// Field is the front matter key (TagField for tags)
+	Field string
+
+	// Value is the tag or field value
+	Value string
+
+	// Paths lists the matching documents, sorted
+	Paths []string
+}
+
+// Label returns "#tag" for tags and "field: value" for other fields
+func (e Entry) Label() string {
+	if e.Field == TagField {
+		return "#" + e.Value
+	}
+	return e.Field + ": " + e.Value
+}
+
+// Index maps field values to the documents that carry them
+type Index struct {
+	// values maps field -> value -> set of paths
+	values map[string]map[string]map[string]bool
+
+	// Files is the number of documents indexed
+	Files int
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{values: make(map[string]map[string]map[string]bool)}
+}
+
+// Build reads and indexes every file in paths, skipping unreadable ones
+func Build(paths []string) *Index {
+	idx := New()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		idx.Add(path, string(content))
+	}
+	return idx
+}
+
+// Add indexes one document's front matter and hashtags
+func (idx *Index) Add(path, content string) {
+	idx.Files++
+
+	matter, body := frontmatter.Split(content)
+	if matter != nil {
+		for _, key := range matter.Keys {
+			switch {
+			case key == TagField || key == "tag" || key == "keywords":
+				for _, tag := range matter.List(key) {
+					idx.add(TagField, normalizeTag(tag), path)
+				}
+			case skipFields[key]:
+			case matter.Lists[key] != nil:
+				for _, value := range matter.Lists[key] {
+					idx.add(key, value, path)
+				}
+			default:
+				idx.add(key, matter.Fields[key], path)
+			}
+		}
+	}
+
+	for _, tag := range Hashtags(body) {
+		idx.add(TagField, tag, path)
+	}
+}
+
+// add records that path has field = value
+func (idx *Index) add(field, value, path string) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxValueLength {
+		return
+	}
+
+	byValue, ok := idx.values[field]
+	if !ok {
+		byValue = make(map[string]map[string]bool)
+		idx.values[field] = byValue
+	}
+	paths, ok := byValue[value]
+	if !ok {
+		paths = make(map[string]bool)
+		byValue[value] = paths
+	}
+	paths[path] = true
+}
+
+// Entries returns every indexed value: tags first, then other fields by
+// name, each ordered by document count (most used first) and then by value
+func (idx *Index) Entries() []Entry {
+	fields := make([]string, 0, len(idx.values))
+	for field := range idx.values {
+		if field != TagField {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	if _, ok := idx.values[TagField]; ok {
+		fields = append([]string{TagField}, fields...)
+	}
+
+	var entries []Entry
+	for _, field := range fields {
+		var group []Entry
+		for value := range idx.values[field] {
+			group = append(group, Entry{Field: field, Value: value, Paths: idx.Lookup(field, value)})
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if len(group[i].Paths) != len(group[j].Paths) {
+				return len(group[i].Paths) > len(group[j].Paths)
+			}
+			return group[i].Value < group[j].Value
+		})
+		entries = append(entries, group...)
+	}
+	return entries
+}
+
+// Lookup returns the documents with field = value. Nested tags count
+// towards their parents, so #infra also finds #infra/net.
+func (idx *Index) Lookup(field, value string) []string {
+	if field != TagField {
+		return sortedKeys(idx.values[field][value])
+	}
+
+	paths := make(map[string]bool)
+	for tag, tagged := range idx.values[TagField] {
+		if tag == value || strings.HasPrefix(tag, value+"/") {
+			for path := range tagged {
+				paths[path] = true
+			}
+		}
+	}
+	return sortedKeys(paths)
+}
+
+// Hashtags returns the distinct #tags in a markdown body, lower-cased.
+// Code blocks, code spans, headings and purely numeric tags (#1) are skipped.
+func Hashtags(body string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	var fence mdlinks.Fence
+
+	for _, line := range strings.Split(body, "\n") {
+		if fence.Scan(line) || fence.Open() {
+			continue
+		}
+
+		for _, m := range hashtagPattern.FindAllStringSubmatch(mdlinks.MaskCodeSpans(line), -1) {
+			tag := normalizeTag(m[1])
+			if tag == "" || isNumber(tag) || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// normalizeTag lower-cases a tag and drops a leading # and trailing separators
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.TrimRight(tag, "/-"))
+}
+
+// isNumber reports whether s is all ASCII digits, like an issue reference
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns a set's members in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"plain", "Notes on #infra and #OnCall.", []string{"infra", "oncall"}},
+		{"nested and kebab", "#infra/net #run-book", []string{"infra/net", "run-book"}},
+		{"repeats once", "#a #A #a", []string{"a"}},
+		{"trailing separators", "see #todo- and #infra/", []string{"todo", "infra"}},
+		{"numbers are issue refs", "fixes #12 and #12b", []string{"12b"}},
+		{"needs whitespace before", "a#b c#d (#e) #f", []string{"f"}},
+		{"headings are not tags", "# Title\n## Sub\n#tag", []string{"tag"}},
+		{"code spans", "`#not` #yes", []string{"yes"}},
+		{"code blocks", "```\n#not\n```\n#yes", []string{"yes"}},
+		{"unicode", "#über #日本", []string{"über", "日本"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hashtags(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hashtags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	idx := New()
+	idx.Add("/a.md", "---\ntags: [infra, oncall]\nstatus: draft\ntitle: A\n---\nBody #infra/net\n")
+	idx.Add("/b.md", "---\ntags: infra\nstatus: published\nowners: [ops, web]\n---\n")
+	idx.Add("/c.md", "---\nkeywords: [Ops]\nstatus: draft\ndescription: "+
+		"a long free text value that is much too long to filter by\n---\n")
+
+	var got []string
+	for _, e := range idx.Entries() {
+		got = append(got, e.Label())
+	}
+	want := []string{
+		// tags first, most used first
+		"#infra", "#infra/net", "#oncall", "#ops",
+		// then fields by name
+		"owners: ops", "owners: web",
+		"status: draft", "status: published",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+	if idx.Files != 3 {
+		t.Errorf("Files = %d, want 3", idx.Files)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	idx := New()
+	idx.Add("/a.md", "#infra")
+	idx.Add("/b.md", "#infra/net")
+	idx.Add("/c.md", "#infrastructure")
+	idx.Add("/d.md", "---\nstatus: draft\n---\n")
+
+	tests := []struct {
+		field, value string
+		want         []string
+	}{
+		{TagField, "infra", []string{"/a.md", "/b.md"}},
+		{TagField, "infra/net", []string{"/b.md"}},
+		{TagField, "missing", []string{}},
+		{"status", "draft", []string{"/d.md"}},
+		{"status", "published", []string{}},
+	}
+	for _, tt := range tests {
+		if got := idx.Lookup(tt.field, tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q, %q) = %q, want %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
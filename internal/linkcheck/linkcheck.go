@@ -0,0 +1,221 @@
+// Package linkcheck finds broken links in markdown files: missing files and
+// images, unknown #anchors, undefined references and malformed URLs
+package linkcheck
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+)
+
+// Problem is a broken link
+type Problem struct {
+	// Path is the file containing the link
+	Path string `json:"path"`
+
+	// Line is the 1-based line of the link
+	Line int `json:"line"`
+
+	// Target is the link destination as written
+	Target string `json:"target"`
+
+	// Reason describes what is wrong
+	Reason string `json:"reason"`
+}
+
+// Report is the result of checking a set of files
+type Report struct {
+	// Files is the number of files checked
+	Files int `json:"files"`
+
+	// Links is the number of links checked
+	Links int `json:"links"`
+
+	// Problems lists the broken links, by path and line
+	Problems []Problem `json:"problems"`
+}
+
+// checker validates links, caching the anchors of each target document
+type checker struct {
+	vault   *mdlinks.Vault
+	anchors map[string]map[string]bool
+}
+
+// Check reads every file in paths and validates its links. External URLs
+// are only checked for syntax.
+func Check(paths []string) Report {
+	c := &checker{
+		vault:   mdlinks.NewVault(paths),
+		anchors: make(map[string]map[string]bool),
+	}
+
+	report := Report{Problems: []Problem{}}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			report.Problems = append(report.Problems, Problem{Path: path, Reason: err.Error()})
+			continue
+		}
+		report.Files++
+
+		links, problems := c.checkFile(path, string(content))
+		report.Links += links
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+// checkFile checks one document and returns how many links it has and the broken ones
+func (c *checker) checkFile(path, content string) (int, []Problem) {
+	// Front matter lines count towards line numbers but hold no links
+	matter, body := frontmatter.Split(content)
+	offset := 0
+	if matter != nil {
+		offset = matter.Lines
+	}
+
+	var problems []Problem
+	report := func(link mdlinks.Link, reason string) {
+		problems = append(problems, Problem{
+			Path:   path,
+			Line:   offset + link.Line + 1,
+			Target: link.Target,
+			Reason: reason,
+		})
+	}
+
+	links := mdlinks.Extract(body)
+	labels := make(map[string]bool)
+	for _, link := range links {
+		if link.Kind == mdlinks.Definition {
+			labels[mdlinks.NormalizeLabel(link.Text)] = true
+		}
+		if reason := c.checkLink(path, body, link); reason != "" {
+			report(link, reason)
+		}
+	}
+
+	references := mdlinks.ExtractReferences(body)
+	for _, link := range references {
+		if !labels[mdlinks.NormalizeLabel(link.Target)] {
+			report(link, "undefined reference ["+link.Target+"]")
+		}
+	}
+
+	return len(links) + len(references), problems
+}
+
+// checkLink returns why a link is broken, or "" if it is fine
+func (c *checker) checkLink(path, content string, link mdlinks.Link) string {
+	if link.Kind == mdlinks.Wiki {
+		return c.checkWiki(path, content, link)
+	}
+
+	switch {
+	case link.Target == "":
+		if link.Kind == mdlinks.Image {
+			return "empty image path"
+		}
+		return "empty link"
+	case mdlinks.IsExternal(link.Target), strings.HasPrefix(link.Target, "mailto:"):
+		return checkURL(link.Target)
+	}
+
+	target, anchor := mdlinks.SplitAnchor(link.Target)
+	if target == "" {
+		// A bare # is commonly used as a placeholder
+		if anchor != "" && !mdlinks.Anchors(content)[strings.ToLower(anchor)] {
+			return "no heading #" + anchor
+		}
+		return ""
+	}
+
+	resolved := mdlinks.Resolve(path, link.Target)
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if link.Kind == mdlinks.Image {
+			return "image not found"
+		}
+		return "file not found"
+	}
+	if anchor != "" && !info.IsDir() && isMarkdown(resolved) && !c.hasAnchor(resolved, anchor) {
+		return "no heading #" + anchor + " in " + filepath.Base(resolved)
+	}
+	return ""
+}
+
+// checkWiki validates a [[wiki link]] and its #heading, which is written as
+// heading text rather than a slug
+func (c *checker) checkWiki(path, content string, link mdlinks.Link) string {
+	name, heading := mdlinks.SplitAnchor(link.Target)
+	anchor := mdlinks.Slug(heading)
+
+	if name == "" {
+		if anchor != "" && !mdlinks.Anchors(content)[anchor] {
+			return "no heading #" + heading
+		}
+		return ""
+	}
+
+	resolved := c.vault.Resolve(path, link.Target)
+	if resolved == "" {
+		return "no note named " + name
+	}
+	if anchor != "" && !c.hasAnchor(resolved, anchor) {
+		return "no heading #" + heading + " in " + filepath.Base(resolved)
+	}
+	return ""
+}
+
+// hasAnchor reports whether a markdown file defines an anchor
+func (c *checker) hasAnchor(path, anchor string) bool {
+	anchors, ok := c.anchors[path]
+	if !ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		_, body := frontmatter.Split(string(content))
+		anchors = mdlinks.Anchors(body)
+		c.anchors[path] = anchors
+	}
+	return anchors[strings.ToLower(anchor)]
+}
+
+// checkURL returns why an external URL is malformed, or "" if it looks valid
+func checkURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "malformed URL"
+	}
+	switch u.Scheme {
+	case "http", "https", "ftp":
+		if u.Host == "" {
+			return "URL has no host"
+		}
+	case "mailto":
+		if u.Opaque == "" || !strings.Contains(u.Opaque, "@") {
+			return "malformed email address"
+		}
+	}
+	return ""
+}
+
+// isMarkdown reports whether a path has a markdown extension
+func isMarkdown(path string) bool {
+	return format.IsMarkdown(path)
+}
@@ -0,0 +1,122 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/walk"
+)
+
+// Run executes the check command, printing problems as text or JSON.
+// The caller should exit non-zero when the report has problems.
+func Run(args []string) (Report, error) {
+	// Parse flags
+	rootPath := "."
+	var asJSON bool
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		case "-h", "--help":
+			printUsage()
+			return Report{}, nil
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return Report{}, fmt.Errorf("unknown flag: %s", arg)
+			}
+			rootPath = arg
+		}
+	}
+
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return Report{}, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Report{}, err
+	}
+	if !info.IsDir() {
+		return Report{}, fmt.Errorf("not a directory: %s", absPath)
+	}
+
+	// Check the same files the tree shows
+	var paths []string
+	err = walk.Markdown(absPath, walk.OptionsFor(absPath), func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Check(paths)
+	if asJSON {
+		// Paths relative to the checked directory read better in CI logs
+		for i := range report.Problems {
+			report.Problems[i].Path = relative(absPath, report.Problems[i].Path)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return report, encoder.Encode(report)
+	}
+
+	for _, p := range report.Problems {
+		location := relative(absPath, p.Path)
+		if p.Line > 0 {
+			location += fmt.Sprintf(":%d", p.Line)
+		}
+		if p.Target != "" {
+			fmt.Printf("%s: %s: %s\n", location, p.Target, p.Reason)
+		} else {
+			fmt.Printf("%s: %s\n", location, p.Reason)
+		}
+	}
+	if len(report.Problems) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("%s (%s, %s checked)\n",
+		plural(len(report.Problems), "broken link"), plural(report.Files, "file"), plural(report.Links, "link"))
+	return report, nil
+}
+
+// relative returns path relative to root when it is inside it
+func relative(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// plural formats a count with a noun, adding "s" when needed
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// printUsage prints help for the check command
+func printUsage() {
+	fmt.Print(`Usage: skim check [path] [flags]
+
+Check links in every markdown file under path (default: current directory).
+Relative file links, images, #anchors, reference definitions and [[wiki links]]
+are validated; external URLs are only checked for syntax.
+
+Exits with status 1 when broken links are found.
+
+Flags:
+  --json         Print the report as JSON
+  -h, --help     Show this help message
+
+Examples:
+  skim check              Check the current directory
+  skim check ./docs       Check a docs directory
+  skim check --json       Machine-readable report for CI
+`)
+}
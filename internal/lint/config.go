@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ConfigFileName is the per-repository lint config file
+const ConfigFileName = ".skimlint.json"
+
+// DefaultLineLength is the line-length limit when the config sets none
+const DefaultLineLength = 120
+
+// Config selects which rules run
+type Config struct {
+	// Disable lists rules that are turned off ("all" turns off linting)
+	Disable []string `json:"disable,omitempty"`
+
+	// LineLength is the longest allowed line (0 uses DefaultLineLength)
+	LineLength int `json:"lineLength,omitempty"`
+}
+
+// Enabled reports whether a rule runs
+func (c Config) Enabled(rule string) bool {
+	for _, disabled := range c.Disable {
+		if disabled == rule || disabled == "all" {
+			return false
+		}
+	}
+	return true
+}
+
+// lineLength returns the line-length limit
+func (c Config) lineLength() int {
+	if c.LineLength > 0 {
+		return c.LineLength
+	}
+	return DefaultLineLength
+}
+
+// LoadConfig reads the nearest .skimlint.json in dir or its parents, up to
+// the enclosing git repository. No file gives the default rule set.
+func LoadConfig(dir string) (Config, error) {
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var c Config
+			if err := json.Unmarshal(data, &c); err != nil {
+				return Config{}, fmt.Errorf("reading %s: %w", path, err)
+			}
+			for _, rule := range c.Disable {
+				if rule != "all" && !slices.Contains(Rules, rule) {
+					return c, fmt.Errorf("%s: unknown lint rule %q", path, rule)
+				}
+			}
+			return c, nil
+		}
+
+		// Stop at the repository root or the top of the filesystem
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return Config{}, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Config{}, nil
+		}
+		dir = parent
+	}
+}
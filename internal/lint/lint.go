@@ -0,0 +1,168 @@
+// Package lint checks markdown source for common style problems
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ayushlm10/skim/internal/frontmatter"
+	"github.com/Ayushlm10/skim/internal/mdlinks"
+)
+
+// Rule names, used in diagnostics and to disable rules in the config file
+const (
+	HeadingIncrement   = "heading-increment"
+	DuplicateHeading   = "duplicate-heading"
+	TrailingWhitespace = "trailing-whitespace"
+	ImageAltText       = "image-alt-text"
+	BareURL            = "bare-url"
+	LineLength         = "line-length"
+	UnclosedFence      = "unclosed-fence"
+)
+
+// Rules lists every rule
+var Rules = []string{
+	HeadingIncrement,
+	DuplicateHeading,
+	TrailingWhitespace,
+	ImageAltText,
+	BareURL,
+	LineLength,
+	UnclosedFence,
+}
+
+// Diagnostic is a single lint finding
+type Diagnostic struct {
+	// Line is the 1-based line in the file
+	Line int
+
+	// Rule is the name of the rule that fired
+	Rule string
+
+	// Message describes the problem
+	Message string
+}
+
+var (
+	// headingPattern matches ATX headings, capturing the hashes and the text
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?\s*#*\s*$`)
+
+	// imageNoAlt matches images with empty alt text
+	imageNoAlt = regexp.MustCompile(`!\[\s*\]\(`)
+
+	// linkSyntax matches inline links, autolinks and HTML tags, where URLs are not bare
+	linkSyntax = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)|<[^>]+>`)
+
+	// urlPattern matches a URL written as plain text
+	urlPattern = regexp.MustCompile(`(?:^|[\s(])(https?://[^\s<>()]+)`)
+
+	// definitionPattern matches [label]: target reference definitions
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s`)
+)
+
+// Check returns the diagnostics for a markdown document, in line order.
+// Front matter is skipped but counted in line numbers.
+func Check(content string, cfg Config) []Diagnostic {
+	matter, body := frontmatter.Split(content)
+	offset := 0
+	if matter != nil {
+		offset = matter.Lines
+	}
+
+	var diagnostics []Diagnostic
+	report := func(line int, rule, format string, args ...any) {
+		if cfg.Enabled(rule) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Line:    offset + line + 1,
+				Rule:    rule,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+	}
+
+	lines := strings.Split(body, "\n")
+	seen := make(map[string]int) // heading slug -> first line
+	previousLevel := 0
+	var fence mdlinks.Fence
+	fenceLine := 0
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		// Code blocks are left alone apart from finding where they end
+		if fence.Scan(line) {
+			if fence.Open() {
+				fenceLine = i
+			}
+			continue
+		}
+		if fence.Open() {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			if previousLevel > 0 && level > previousLevel+1 {
+				report(i, HeadingIncrement, "heading level jumps from h%d to h%d", previousLevel, level)
+			}
+			previousLevel = level
+
+			slug := mdlinks.Slug(m[2])
+			if first, ok := seen[slug]; ok && slug != "" {
+				report(i, DuplicateHeading, "duplicate heading %q (first on line %d)", m[2], offset+first+1)
+			} else {
+				seen[slug] = i
+			}
+		}
+
+		if hasTrailingWhitespace(line) {
+			report(i, TrailingWhitespace, "trailing whitespace")
+		}
+
+		masked := mdlinks.MaskCodeSpans(line)
+		if imageNoAlt.MatchString(masked) {
+			report(i, ImageAltText, "image has no alt text")
+		}
+
+		if !definitionPattern.MatchString(masked) {
+			text := linkSyntax.ReplaceAllStringFunc(masked, func(s string) string {
+				return strings.Repeat(" ", len(s))
+			})
+			if m := urlPattern.FindStringSubmatch(text); m != nil {
+				report(i, BareURL, "bare URL %s (use <...> or a link)", strings.TrimRight(m[1], ".,;:!?"))
+			}
+		}
+
+		if width := utf8.RuneCountInString(line); width > cfg.lineLength() && !exemptFromLength(line, cfg.lineLength()) {
+			report(i, LineLength, "line is %d characters (limit %d)", width, cfg.lineLength())
+		}
+	}
+
+	if fence.Open() {
+		report(fenceLine, UnclosedFence, "code fence is never closed")
+	}
+
+	return diagnostics
+}
+
+// hasTrailingWhitespace reports whether a line ends in spaces or tabs,
+// allowing exactly two spaces after text (a hard line break)
+func hasTrailingWhitespace(line string) bool {
+	trimmed := strings.TrimRight(line, " \t")
+	if trimmed == line {
+		return false
+	}
+	return strings.TrimSpace(trimmed) == "" || line[len(trimmed):] != "  "
+}
+
+// exemptFromLength reports whether a long line is allowed anyway: tables,
+// and lines that only overflow because of a single long word such as a URL
+func exemptFromLength(line string, limit int) bool {
+	if strings.HasPrefix(strings.TrimSpace(line), "|") {
+		return true
+	}
+	runes := []rune(line)
+	return !strings.ContainsAny(string(runes[limit:]), " \t")
+}
@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// finding is the line and rule of a diagnostic
+type finding struct {
+	line int
+	rule string
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		cfg     Config
+		want    []finding
+	}{
+		{
+			name:    "clean document",
+			content: "# Title\n\n## Part\n\nText with a [link](https://example.com).\n",
+		},
+		{
+			name:    "heading jumps a level",
+			content: "# Title\n### Deep\n## Back\n#### Deep again",
+			want:    []finding{{2, HeadingIncrement}, {4, HeadingIncrement}},
+		},
+		{
+			name:    "duplicate headings by slug",
+			content: "# Setup\n## Usage\n## setup\n",
+			want:    []finding{{3, DuplicateHeading}},
+		},
+		{
+			name:    "trailing whitespace but not a hard break",
+			content: "tab\t\nspaces   \nbreak  \n  \n",
+			want:    []finding{{1, TrailingWhitespace}, {2, TrailingWhitespace}, {4, TrailingWhitespace}},
+		},
+		{
+			name:    "image without alt text",
+			content: "![](a.png) ![ok](b.png) `![](code.png)`",
+			want:    []finding{{1, ImageAltText}},
+		},
+		{
+			name:    "bare URLs",
+			content: "see https://a.example.\n<https://b.example>\n[b](https://c.example)\n[ref]: https://d.example\n`https://e.example`",
+			want:    []finding{{1, BareURL}},
+		},
+		{
+			name:    "line length",
+			content: strings.Repeat("word ", 6) + "end\n" + strings.Repeat("x", 40) + "\n| " + strings.Repeat("cell ", 8) + "|",
+			cfg:     Config{LineLength: 20},
+			want:    []finding{{1, LineLength}},
+		},
+		{
+			name:    "code blocks are skipped",
+			content: "# A\n```\n### not a heading   \n```\n~~~~\n```\n~~~~\n# A2",
+		},
+		{
+			name:    "unclosed fence",
+			content: "text\n````md\n```\n# inside\n",
+			want:    []finding{{2, UnclosedFence}},
+		},
+		{
+			name:    "front matter counts toward line numbers",
+			content: "---\ntitle: x   \n---\n# A\n### C",
+			want:    []finding{{5, HeadingIncrement}},
+		},
+		{
+			name:    "disabled rules",
+			content: "# A\n### C   \n",
+			cfg:     Config{Disable: []string{TrailingWhitespace}},
+			want:    []finding{{2, HeadingIncrement}},
+		},
+		{
+			name:    "everything disabled",
+			content: "# A\n### C   \n",
+			cfg:     Config{Disable: []string{"all"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []finding
+			for _, d := range Check(tt.content, tt.cfg) {
+				got = append(got, finding{d.Line, d.Rule})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMessages(t *testing.T) {
+	got := Check("# Setup\n## Setup\nhttps://x.example/path.", Config{})
+	want := []string{
+		`duplicate heading "Setup" (first on line 1)`,
+		"bare URL https://x.example/path (use <...> or a link)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Check() = %v, want %d diagnostics", got, len(want))
+	}
+	for i, d := range got {
+		if d.Message != want[i] {
+			t.Errorf("message %d = %q, want %q", i, d.Message, want[i])
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "docs", "deep")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(dir, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		content string // written to docs/; "" writes nothing
+		want    Config
+		wantErr bool
+	}{
+		{"no file", "", Config{}, false},
+		{"found in a parent", `{"disable": ["line-length"], "lineLength": 80}`, Config{Disable: []string{LineLength}, LineLength: 80}, false},
+		{"unknown rule", `{"disable": ["spelling"]}`, Config{Disable: []string{"spelling"}}, true},
+		{"invalid json", `{"disable": `, Config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(filepath.Join(root, "docs", ConfigFileName))
+			if tt.content != "" {
+				write(filepath.Join(root, "docs"), tt.content)
+			}
+			got, err := LoadConfig(sub)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
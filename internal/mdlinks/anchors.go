@@ -0,0 +1,96 @@
+package mdlinks
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// headingPattern matches ATX headings
+	headingPattern = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+
+	// setextPattern matches the underline of a setext heading
+	setextPattern = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+
+	// htmlAnchorPattern matches <a name="..."> and id="..." anchors in raw HTML
+	htmlAnchorPattern = regexp.MustCompile(`<[a-zA-Z][^>]*\s(?:name|id)=["']([^"']+)["']`)
+
+	// inlineMarkup matches the inline markup left out of heading anchors
+	inlineMarkup = regexp.MustCompile("[*_`~]|\\]\\([^)]*\\)|\\[")
+)
+
+// Slug converts heading text to a GitHub-style anchor
+func Slug(heading string) string {
+	heading = strings.TrimSpace(inlineMarkup.ReplaceAllString(heading, ""))
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// Anchor is a #anchor defined by a heading or an HTML tag
+type Anchor struct {
+	// Name is the anchor without the #, lowercased
+	Name string
+
+	// Line is the 0-based line of the heading text or the tag
+	Line int
+
+	// Heading is the heading text as written, "" for HTML anchors
+	Heading string
+}
+
+// FindAnchors returns the anchors a document's headings and HTML anchors
+// define, in document order. Repeated headings get -1, -2, ... suffixes the
+// way GitHub numbers them.
+func FindAnchors(content string) []Anchor {
+	var anchors []Anchor
+	seen := make(map[string]bool)
+	add := func(heading string, line int) {
+		slug := Slug(heading)
+		name := slug
+		for n := 1; seen[name]; n++ {
+			name = slug + "-" + strconv.Itoa(n)
+		}
+		seen[name] = true
+		anchors = append(anchors, Anchor{Name: name, Line: line, Heading: heading})
+	}
+
+	previous := ""
+	eachLine(content, func(i, _ int, line string) {
+		for _, m := range htmlAnchorPattern.FindAllStringSubmatch(line, -1) {
+			name := strings.ToLower(m[1])
+			seen[name] = true
+			anchors = append(anchors, Anchor{Name: name, Line: i})
+		}
+
+		switch {
+		case headingPattern.MatchString(line):
+			add(headingPattern.FindStringSubmatch(line)[1], i)
+			line = ""
+		case setextPattern.MatchString(line) && strings.TrimSpace(previous) != "":
+			add(strings.TrimSpace(previous), i-1)
+			line = ""
+		}
+		previous = line
+	})
+	return anchors
+}
+
+// Anchors returns the set of #anchors a document defines
+func Anchors(content string) map[string]bool {
+	anchors := make(map[string]bool)
+	for _, a := range FindAnchors(content) {
+		anchors[a.Name] = true
+	}
+	return anchors
+}
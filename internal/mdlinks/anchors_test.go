@@ -0,0 +1,48 @@
+package mdlinks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct{ heading, want string }{
+		{"Getting Started", "getting-started"},
+		{"What's new in v2.0?", "whats-new-in-v20"},
+		{"  `code` and *emphasis*  ", "code-and-emphasis"},
+		{"[Link](http://x.y) text", "link-text"},
+		{"__Bold__ - dashes", "bold---dashes"},
+		{"Ünïcödé Héading", "ünïcödé-héading"},
+		{"日本語", "日本語"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.heading); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	content := "# Intro\n## Intro\n### Intro ###\nSetext\n======\n\n---\n" +
+		"<a name=\"Custom\"></a> <div id='box'>\n```\n# Not a heading\n```\n"
+	want := map[string]bool{
+		"intro": true, "intro-1": true, "intro-2": true,
+		"setext": true, "custom": true, "box": true,
+	}
+	if got := Anchors(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Anchors() = %v, want %v", got, want)
+	}
+}
+
+func TestFindAnchors(t *testing.T) {
+	content := "# Intro\ntext\nIntro\n-----\n<a id=\"Top\"></a>\n## Intro"
+	want := []Anchor{
+		{Name: "intro", Line: 0, Heading: "Intro"},
+		{Name: "intro-1", Line: 2, Heading: "Intro"},
+		{Name: "top", Line: 4},
+		{Name: "intro-2", Line: 5, Heading: "Intro"},
+	}
+	if got := FindAnchors(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAnchors() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package mdlinks
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fencePattern matches a line opening a fenced code block: its indent, the
+// run of backticks or tildes and the info string. Fences in list items are
+// indented past the list marker, so any indent is taken.
+var fencePattern = regexp.MustCompile("^( *)(`{3,}|~{3,})(.*)$")
+
+// OpenFence parses a line opening a fenced code block, returning its indent,
+// its fence and the language named in the info string. As in CommonMark, a
+// backtick fence's info string can't contain a backtick.
+func OpenFence(line string) (indent, fence, lang string, ok bool) {
+	m := fencePattern.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+	if m == nil || (m[2][0] == '`' && strings.Contains(m[3], "`")) {
+		return "", "", "", false
+	}
+	if fields := strings.Fields(m[3]); len(fields) > 0 {
+		lang = fields[0]
+	}
+	return m[1], m[2], lang, true
+}
+
+// ClosesFence reports whether line closes the block opened with fence: the
+// same character, at least as many, and nothing else on the line
+func ClosesFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) >= len(fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// Fence follows fenced code blocks through a document line by line
+type Fence struct {
+	open string // fence of the block the last line left open
+}
+
+// Scan moves past a line and reports whether it opens or closes a block
+func (f *Fence) Scan(line string) bool {
+	if f.open != "" {
+		if ClosesFence(line, f.open) {
+			f.open = ""
+			return true
+		}
+		return false
+	}
+	if _, fence, _, ok := OpenFence(line); ok {
+		f.open = fence
+		return true
+	}
+	return false
+}
+
+// Open reports whether the lines scanned so far leave a block open
+func (f Fence) Open() bool {
+	return f.open != ""
+}
@@ -0,0 +1,339 @@
+// Package mdlinks finds and rewrites links in raw markdown source
+package mdlinks
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind identifies the syntax a link was written with
+type Kind int
+
+const (
+	// Inline is a [text](target) link
+	Inline Kind = iota
+
+	// Image is a ![alt](target) image
+	Image
+
+	// Definition is a [ref]: target reference definition
+	Definition
+
+	// Wiki is a [[Note Name#heading|alias]] link, resolved by file name
+	Wiki
+
+	// Reference is a [text][label] use of a reference definition; its
+	// Target is the label. Only ExtractReferences returns these.
+	Reference
+)
+
+// Link is a single link occurrence in markdown source
+type Link struct {
+	// Kind is the link syntax
+	Kind Kind
+
+	// Line is the 0-based line number
+	Line int
+
+	// Text is the link text, image alt text or reference label. For wiki
+	// links it is the alias, or the note name as Obsidian displays it.
+	Text string
+
+	// Target is the destination as written (without angle brackets or title)
+	Target string
+
+	// Start and End are the byte offsets of Target within the whole content
+	Start int
+	End   int
+}
+
+var (
+	// inlinePattern matches [text](target "title") and ![alt](target)
+	inlinePattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\]]*\])*)\]\(\s*(<[^>]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+
+	// definitionPattern matches [label]: target "title"
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*(<[^>]*>|\S+)`)
+
+	// referencePattern matches [text][label] and [label][] (the label group is empty)
+	referencePattern = regexp.MustCompile(`!?\[((?:[^\[\]]|\[[^\]]*\])+)\]\[([^\[\]]*)\]`)
+
+	// wikiPattern matches [[target]], [[target|alias]] and ![[embeds]]
+	wikiPattern = regexp.MustCompile(`!?\[\[([^\[\]|\n]+?)(?:\|([^\[\]\n]*))?\]\]`)
+)
+
+// Extract returns all inline links, images, reference definitions and wiki
+// links in content. Fenced code blocks and inline code spans are skipped.
+func Extract(content string) []Link {
+	var links []Link
+
+	eachLine(content, func(i, lineStart int, line string) {
+		if m := definitionPattern.FindStringSubmatchIndex(line); m != nil {
+			start, end := trimAngles(line, m[4], m[5])
+			links = append(links, Link{
+				Kind:   Definition,
+				Line:   i,
+				Text:   line[m[2]:m[3]],
+				Target: line[start:end],
+				Start:  lineStart + start,
+				End:    lineStart + end,
+			})
+			return
+		}
+
+		masked := MaskCodeSpans(line)
+		for _, m := range inlinePattern.FindAllStringSubmatchIndex(masked, -1) {
+			kind := Inline
+			if m[3] > m[2] {
+				kind = Image
+			}
+			start, end := trimAngles(line, m[6], m[7])
+			links = append(links, Link{
+				Kind:   kind,
+				Line:   i,
+				Text:   line[m[4]:m[5]],
+				Target: line[start:end],
+				Start:  lineStart + start,
+				End:    lineStart + end,
+			})
+		}
+
+		for _, m := range wikiPattern.FindAllStringSubmatchIndex(masked, -1) {
+			links = append(links, wikiLink(line, m, i, lineStart))
+		}
+	})
+
+	return links
+}
+
+// ExtractReferences returns the [text][label] and [label][] links in content,
+// outside code. Shortcut [label] references are not included since they
+// can't be told apart from plain bracketed text.
+func ExtractReferences(content string) []Link {
+	var links []Link
+
+	eachLine(content, func(i, lineStart int, line string) {
+		if definitionPattern.MatchString(line) {
+			return
+		}
+		for _, m := range referencePattern.FindAllStringSubmatchIndex(MaskCodeSpans(line), -1) {
+			start, end := m[4], m[5]
+			if start == end {
+				start, end = m[2], m[3]
+			}
+			links = append(links, Link{
+				Kind:   Reference,
+				Line:   i,
+				Text:   line[m[2]:m[3]],
+				Target: line[start:end],
+				Start:  lineStart + start,
+				End:    lineStart + end,
+			})
+		}
+	})
+
+	return links
+}
+
+// NormalizeLabel folds a reference label the way markdown matches them:
+// case-insensitively, with runs of whitespace collapsed
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// wikiLink builds the Link for a wikiPattern match in line
+func wikiLink(line string, m []int, i, lineStart int) Link {
+	target := strings.TrimSpace(line[m[2]:m[3]])
+	start := m[2] + strings.Index(line[m[2]:m[3]], target)
+
+	text := ""
+	if m[4] != -1 {
+		text = strings.TrimSpace(line[m[4]:m[5]])
+	}
+	if text == "" {
+		switch name, anchor := SplitAnchor(target); {
+		case name == "":
+			text = anchor
+		case anchor != "":
+			text = name + " > " + anchor
+		default:
+			text = name
+		}
+	}
+
+	return Link{
+		Kind:   Wiki,
+		Line:   i,
+		Text:   text,
+		Target: target,
+		Start:  lineStart + start,
+		End:    lineStart + start + len(target),
+	}
+}
+
+// eachLine calls fn with every line outside fenced code blocks, its 0-based
+// number and its byte offset within content
+func eachLine(content string, fn func(i, lineStart int, line string)) {
+	var fence Fence
+
+	offset := 0
+	for i, line := range strings.Split(content, "\n") {
+		lineStart := offset
+		offset += len(line) + 1
+
+		if fence.Scan(line) || fence.Open() {
+			continue
+		}
+
+		fn(i, lineStart, line)
+	}
+}
+
+// RewriteWiki replaces every [[wiki link]] outside code with the result of
+// fn, which receives the parsed link
+func RewriteWiki(content string, fn func(link Link) string) string {
+	if !strings.Contains(content, "[[") {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	eachLine(content, func(i, lineStart int, line string) {
+		for _, m := range wikiPattern.FindAllStringSubmatchIndex(MaskCodeSpans(line), -1) {
+			b.WriteString(content[last : lineStart+m[0]])
+			b.WriteString(fn(wikiLink(line, m, i, lineStart)))
+			last = lineStart + m[1]
+		}
+	})
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// trimAngles strips <...> around a link destination
+func trimAngles(line string, start, end int) (int, int) {
+	if end-start >= 2 && line[start] == '<' && line[end-1] == '>' {
+		return start + 1, end - 1
+	}
+	return start, end
+}
+
+// MaskCodeSpans replaces the contents of `code spans` with spaces so links and tags
+// inside them are not matched, keeping byte offsets intact
+func MaskCodeSpans(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+
+	b := []byte(line)
+	for i := 0; i < len(b); i++ {
+		if b[i] != '`' {
+			continue
+		}
+		// Count the opening backtick run
+		run := 1
+		for i+run < len(b) && b[i+run] == '`' {
+			run++
+		}
+		closer := strings.Index(line[i+run:], strings.Repeat("`", run))
+		if closer == -1 {
+			i += run - 1
+			continue
+		}
+		end := i + run + closer + run
+		for j := i; j < end; j++ {
+			b[j] = ' '
+		}
+		i = end - 1
+	}
+	return string(b)
+}
+
+// IsExternal reports whether a target points outside the local filesystem
+// (a URL with a scheme, a mailto: link, or a protocol-relative URL)
+func IsExternal(target string) bool {
+	if strings.HasPrefix(target, "//") {
+		return true
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	return u.Scheme != "" && len(u.Scheme) > 1 // single letter schemes are Windows drives
+}
+
+// SplitAnchor splits a target into its path and #fragment parts
+func SplitAnchor(target string) (path, anchor string) {
+	if idx := strings.Index(target, "#"); idx != -1 {
+		return target[:idx], target[idx+1:]
+	}
+	return target, ""
+}
+
+// Resolve returns the absolute filesystem path a relative link target points at,
+// relative to the directory of the file containing it. Returns "" for external
+// links and pure #anchor links.
+func Resolve(fromFile, target string) string {
+	if IsExternal(target) {
+		return ""
+	}
+	p, _ := SplitAnchor(target)
+	if idx := strings.Index(p, "?"); idx != -1 {
+		p = p[:idx]
+	}
+	if p == "" {
+		return ""
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(filepath.Dir(fromFile), filepath.FromSlash(p))
+}
+
+// Edit replaces the target of a link with a new destination
+type Edit struct {
+	Link   Link
+	Target string
+}
+
+// Apply rewrites link targets in content, leaving every other byte untouched.
+// Edits whose target no longer matches the content at its offsets are skipped.
+func Apply(content string, edits []Edit) string {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Link.Start > sorted[j].Link.Start
+	})
+
+	for _, e := range sorted {
+		if e.Link.End > len(content) || content[e.Link.Start:e.Link.End] != e.Link.Target {
+			continue
+		}
+		content = content[:e.Link.Start] + e.Target + content[e.Link.End:]
+	}
+	return content
+}
+
+// RelativeTarget builds a link target from one file to another, keeping the
+// #anchor of the original target and using forward slashes
+func RelativeTarget(fromFile, toPath, original string) string {
+	rel, err := filepath.Rel(filepath.Dir(fromFile), toPath)
+	if err != nil {
+		return original
+	}
+	rel = filepath.ToSlash(rel)
+
+	// Preserve percent-encoding style of the original link
+	if strings.Contains(original, "%20") {
+		rel = strings.ReplaceAll(rel, " ", "%20")
+	}
+
+	if _, anchor := SplitAnchor(original); anchor != "" {
+		rel += "#" + anchor
+	}
+	return rel
+}
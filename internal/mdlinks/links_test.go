@@ -0,0 +1,173 @@
+package mdlinks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Link
+	}{
+		{
+			name:    "inline link and image",
+			content: "See [docs](guide.md#setup \"Guide\") and ![logo](<img/a b.png>).",
+			want: []Link{
+				{Kind: Inline, Text: "docs", Target: "guide.md#setup", Start: 11, End: 25},
+				{Kind: Image, Text: "logo", Target: "img/a b.png", Start: 48, End: 59},
+			},
+		},
+		{
+			name:    "reference definition",
+			content: "intro\n[spec]: <spec.md> \"Spec\"",
+			want:    []Link{{Kind: Definition, Line: 1, Text: "spec", Target: "spec.md", Start: 15, End: 22}},
+		},
+		{
+			name:    "wiki link with anchor and alias",
+			content: "[[ Note#Part | see ]] and [[Other]]",
+			want: []Link{
+				{Kind: Wiki, Text: "see", Target: "Note#Part", Start: 3, End: 12},
+				{Kind: Wiki, Text: "Other", Target: "Other", Start: 28, End: 33},
+			},
+		},
+		{
+			name:    "code spans and fences are skipped",
+			content: "`[a](x.md)`\n````\n```\n[b](y.md)\n```\n````\n~~~\n[c](z.md)\n~~~\n[d](w.md)",
+			want:    []Link{{Kind: Inline, Line: 9, Text: "d", Target: "w.md", Start: 62, End: 66}},
+		},
+		{
+			name:    "parentheses in the target",
+			content: "[wiki](https://en.wikipedia.org/wiki/Go_(language))",
+			want: []Link{
+				{Kind: Inline, Text: "wiki", Target: "https://en.wikipedia.org/wiki/Go_(language)", Start: 7, End: 50},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			for _, l := range got {
+				if tt.content[l.Start:l.End] != l.Target {
+					t.Errorf("offsets %d:%d hold %q, not %q", l.Start, l.End, tt.content[l.Start:l.End], l.Target)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractReferences(t *testing.T) {
+	content := "[Full][Spec] and [spec][] but not [plain]\n[spec]: spec.md"
+	want := []Link{
+		{Kind: Reference, Text: "Full", Target: "Spec", Start: 7, End: 11},
+		{Kind: Reference, Text: "spec", Target: "spec", Start: 18, End: 22},
+	}
+	if got := ExtractReferences(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractReferences() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	from := filepath.FromSlash("/docs/guide/intro.md")
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"setup.md", "/docs/guide/setup.md"},
+		{"../api/index.md#auth", "/docs/api/index.md"},
+		{"my%20notes.md", "/docs/guide/my notes.md"},
+		{"page.md?raw=1", "/docs/guide/page.md"},
+		{"/abs/file.md", "/abs/file.md"},
+		{"#local", ""},
+		{"https://example.com/a.md", ""},
+		{"mailto:me@example.com", ""},
+		{"//cdn.example.com/x.png", ""},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.FromSlash(want)
+		}
+		if got := Resolve(from, tt.target); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.target, got, want)
+		}
+	}
+}
+
+func TestRelativeTarget(t *testing.T) {
+	tests := []struct {
+		from, to, original string
+		want               string
+	}{
+		{"/docs/a.md", "/docs/sub/b.md", "b.md", "sub/b.md"},
+		{"/docs/sub/a.md", "/docs/b.md", "b.md#top", "../b.md#top"},
+		{"/docs/a.md", "/docs/my notes.md", "old%20name.md", "my%20notes.md"},
+	}
+	for _, tt := range tests {
+		got := RelativeTarget(filepath.FromSlash(tt.from), filepath.FromSlash(tt.to), tt.original)
+		if got != tt.want {
+			t.Errorf("RelativeTarget(%q, %q, %q) = %q, want %q", tt.from, tt.to, tt.original, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	content := "[a](one.md) and [b](two.md)"
+	links := Extract(content)
+	edits := []Edit{
+		{Link: links[0], Target: "first.md"},
+		{Link: links[1], Target: "second.md"},
+		{Link: Link{Target: "gone.md", Start: 4, End: 11}, Target: "x.md"}, // stale
+	}
+	want := "[a](first.md) and [b](second.md)"
+	if got := Apply(content, edits); got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+}
+
+func TestFence(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		code  []bool // whether each line is fence or content
+	}{
+		{"backticks", []string{"```go", "x", "```", "y"}, []bool{true, true, true, false}},
+		{"longer closing fence", []string{"```", "x", "`````", "y"}, []bool{true, true, true, false}},
+		{"shorter fence stays inside", []string{"````", "```", "````", "y"}, []bool{true, true, true, false}},
+		{"other character stays inside", []string{"~~~", "```", "~~~", "y"}, []bool{true, true, true, false}},
+		{"closing fence with text stays inside", []string{"```", "``` x", "```", "y"}, []bool{true, true, true, false}},
+		{"backticks in the info string", []string{"``` a`b", "y"}, []bool{false, false}},
+		{"two backticks", []string{"``", "y"}, []bool{false, false}},
+		{"indented in a list item", []string{"- a", "    ```", "    x", "    ```", "y"}, []bool{false, true, true, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Fence
+			for i, line := range tt.lines {
+				got := f.Scan(line) || f.Open()
+				if got != tt.code[i] {
+					t.Errorf("line %d %q: code = %v, want %v", i, line, got, tt.code[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMaskCodeSpans(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"a `b` c", "a     c"},
+		{"``a ` b`` c", "          c"},
+		{"unclosed `x", "unclosed `x"},
+		{"none", "none"},
+	}
+	for _, tt := range tests {
+		if got := MaskCodeSpans(tt.in); got != tt.want {
+			t.Errorf("MaskCodeSpans(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
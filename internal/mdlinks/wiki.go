@@ -0,0 +1,69 @@
+package mdlinks
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Vault resolves [[wiki link]] targets by file name across a docs tree,
+// the way Obsidian does: names are matched case-insensitively, with or
+// without the .md extension, and may include part of the path
+type Vault struct {
+	// byName maps lower-cased base names without extension to paths
+	byName map[string][]string
+}
+
+// NewVault indexes the given markdown files
+func NewVault(paths []string) *Vault {
+	v := &Vault{byName: make(map[string][]string)}
+	for _, path := range paths {
+		name := strings.ToLower(trimMarkdownExt(filepath.Base(path)))
+		v.byName[name] = append(v.byName[name], path)
+	}
+	return v
+}
+
+// Resolve returns the file a wiki link target points at, or "" if no file
+// matches. When several files share a name, the one in the linking file's
+// directory wins, then the one with the shortest path.
+func (v *Vault) Resolve(fromFile, target string) string {
+	name, _ := SplitAnchor(target)
+	name = strings.ToLower(trimMarkdownExt(strings.TrimSpace(filepath.ToSlash(name))))
+	if name == "" {
+		return ""
+	}
+
+	// A target like folder/Note must match the end of the path
+	base := name
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		base = name[idx+1:]
+	}
+
+	best := ""
+	for _, path := range v.byName[base] {
+		if base != name {
+			slashed := strings.ToLower(trimMarkdownExt(filepath.ToSlash(path)))
+			if !strings.HasSuffix(slashed, "/"+strings.TrimPrefix(name, "/")) {
+				continue
+			}
+		}
+		switch {
+		case best == "":
+			best = path
+		case filepath.Dir(path) == filepath.Dir(fromFile):
+			return path
+		case len(path) < len(best) && filepath.Dir(best) != filepath.Dir(fromFile):
+			best = path
+		}
+	}
+	return best
+}
+
+// trimMarkdownExt removes a .md or .markdown extension
+func trimMarkdownExt(name string) string {
+	ext := filepath.Ext(name)
+	if strings.EqualFold(ext, ".md") || strings.EqualFold(ext, ".markdown") {
+		return name[:len(name)-len(ext)]
+	}
+	return name
+}
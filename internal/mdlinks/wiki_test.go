@@ -0,0 +1,65 @@
+package mdlinks
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultResolve(t *testing.T) {
+	paths := []string{
+		"/vault/Home.md",
+		"/vault/notes/Ideas.md",
+		"/vault/archive/Ideas.md",
+		"/vault/archive/old/Plan.markdown",
+		"/vault/projects/skim/Plan.md",
+	}
+	for i, p := range paths {
+		paths[i] = filepath.FromSlash(p)
+	}
+	v := NewVault(paths)
+
+	tests := []struct {
+		from, target string
+		want         string
+	}{
+		{"/vault/Home.md", "home", "/vault/Home.md"},
+		{"/vault/Home.md", "Home.md#Top", "/vault/Home.md"},
+		{"/vault/notes/Today.md", "Ideas", "/vault/notes/Ideas.md"},
+		{"/vault/archive/Log.md", "Ideas", "/vault/archive/Ideas.md"},
+		{"/vault/Home.md", "Plan", "/vault/projects/skim/Plan.md"},
+		{"/vault/Home.md", "old/Plan", "/vault/archive/old/Plan.markdown"},
+		{"/vault/Home.md", "skim/plan.md", "/vault/projects/skim/Plan.md"},
+		{"/vault/Home.md", "Missing", ""},
+		{"/vault/Home.md", "#heading", ""},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.FromSlash(want)
+		}
+		if got := v.Resolve(filepath.FromSlash(tt.from), tt.target); got != want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", tt.from, tt.target, got, want)
+		}
+	}
+}
+
+func TestRewriteWiki(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"see [[Note]] now", "see [Note](Note) now"},
+		{"[[note#Usage|how to]] and [[b]]", "[how to](note#Usage) and [b](b)"},
+		{"`[[code]]` [[real]]", "`[[code]]` [real](real)"},
+		{"```\n[[fenced]]\n```\n[[after]]", "```\n[[fenced]]\n```\n[after](after)"},
+		{"no links [here](x.md)", "no links [here](x.md)"},
+	}
+	for _, tt := range tests {
+		got := RewriteWiki(tt.content, func(link Link) string {
+			return "[" + link.Text + "](" + link.Target + ")"
+		})
+		if got != tt.want {
+			t.Errorf("RewriteWiki(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -69,6 +69,13 @@ var (
 
 	TreeIndicatorStyle = lipgloss.NewStyle().
 				Foreground(Subtle)
+
+	TreeColumnStyle = lipgloss.NewStyle().
+			Foreground(Subtle)
+
+	TreeSecondaryStyle = lipgloss.NewStyle().
+				Foreground(Subtle).
+				Italic(true)
 )
 
 // Preview styles
@@ -110,6 +117,9 @@ var (
 	StatusIgnoredStyle = lipgloss.NewStyle().
 				Foreground(Warning).
 				Bold(true)
+
+	StatusMessageStyle = lipgloss.NewStyle().
+				Foreground(Success)
 )
 
 // Filter input styles
@@ -143,6 +153,132 @@ var (
 				Italic(true)
 )
 
+// Task list styles
+var (
+	TaskMarkerStyle = lipgloss.NewStyle().
+			Foreground(Accent).
+			Bold(true)
+
+	TaskProgressStyle = lipgloss.NewStyle().
+				Foreground(AccentDim)
+
+	TaskDoneStyle = lipgloss.NewStyle().
+			Foreground(Success).
+			Bold(true)
+)
+
+// Lint styles
+var (
+	LintMarkerStyle = lipgloss.NewStyle().
+			Foreground(Warning)
+
+	LintCountStyle = lipgloss.NewStyle().
+			Foreground(Warning)
+)
+
+// Raw source and split view styles
+var (
+	SourceLineNumberStyle = lipgloss.NewStyle().
+				Foreground(Subtle)
+
+	SourceGutterStyle = lipgloss.NewStyle().
+				Foreground(Border)
+
+	SplitDividerStyle = lipgloss.NewStyle().
+				Foreground(Border)
+)
+
+// Front matter styles
+var (
+	MatterKeyStyle = lipgloss.NewStyle().
+			Foreground(Muted)
+
+	MatterValueStyle = lipgloss.NewStyle().
+				Foreground(Highlight)
+
+	MatterTitleStyle = lipgloss.NewStyle().
+				Foreground(Highlight).
+				Bold(true)
+
+	MatterBorderStyle = lipgloss.NewStyle().
+				Border(lipgloss.NormalBorder(), false, false, false, true).
+				BorderForeground(Border).
+				PaddingLeft(1).
+				MarginLeft(2)
+
+	MatterHintStyle = lipgloss.NewStyle().
+			Foreground(Subtle).
+			Italic(true)
+)
+
+// Data file styles (CSV tables, JSON and YAML trees)
+var (
+	TableHeaderStyle = lipgloss.NewStyle().
+				Foreground(Highlight).
+				Bold(true)
+
+	TableRuleStyle = lipgloss.NewStyle().
+			Foreground(Border)
+
+	DataKeyStyle = lipgloss.NewStyle().
+			Foreground(Accent)
+
+	DataStringStyle = lipgloss.NewStyle().
+			Foreground(Highlight)
+
+	DataNumberStyle = lipgloss.NewStyle().
+			Foreground(Warning)
+
+	DataBoolStyle = lipgloss.NewStyle().
+			Foreground(Error)
+
+	DataNullStyle = lipgloss.NewStyle().
+			Foreground(Subtle).
+			Italic(true)
+
+	DataPunctStyle = lipgloss.NewStyle().
+			Foreground(Subtle)
+
+	DataErrorStyle = lipgloss.NewStyle().
+			Foreground(Error)
+)
+
+// Image styles
+var (
+	ImagePlaceholderStyle = lipgloss.NewStyle().
+		Foreground(Muted).
+		Italic(true)
+)
+
+// Diagram styles
+var (
+	DiagramStyle = lipgloss.NewStyle().
+			Foreground(AccentDim)
+
+	DiagramNoticeStyle = lipgloss.NewStyle().
+				Foreground(Warning).
+				Italic(true)
+
+	DiagramSourceStyle = lipgloss.NewStyle().
+				Foreground(Muted)
+)
+
+// Math styles
+var (
+	MathStyle = lipgloss.NewStyle().
+			Foreground(Highlight)
+
+	MathSourceStyle = lipgloss.NewStyle().
+			Foreground(Muted)
+)
+
+// Overflow styles
+var (
+	OverflowMarkStyle = lipgloss.NewStyle().
+		Foreground(Accent).
+		Bold(true)
+)
+
 // Help styles
 var (
 	HelpKeyStyle = lipgloss.NewStyle().
@@ -183,4 +319,7 @@ const (
 	TreeVertical  = "│  "
 	TreeEmpty     = "   "
 	SelectedMark  = "◀"
+	TaskMarker    = "▶"
+	LintMarker    = "▎"
+	TreeCycleMark = "↺"
 )
@@ -0,0 +1,439 @@
+package texmath
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// block is laid out math: lines of text and the line the rest of the
+// formula lines up with
+type block struct {
+	lines []string
+	base  int
+}
+
+// textBlock is a block of a single line
+func textBlock(s string) block {
+	return block{lines: []string{s}}
+}
+
+func (b block) width() int {
+	w := 0
+	for _, line := range b.lines {
+		w = max(w, ansi.StringWidth(line))
+	}
+	return w
+}
+
+// hcat puts blocks side by side with their baselines lined up
+func hcat(blocks ...block) block {
+	if len(blocks) == 0 {
+		return textBlock("")
+	}
+	above, below := 0, 0
+	for _, b := range blocks {
+		above = max(above, b.base)
+		below = max(below, len(b.lines)-1-b.base)
+	}
+
+	lines := make([]string, above+below+1)
+	for _, b := range blocks {
+		w := b.width()
+		for i := range lines {
+			s := ""
+			if j := i - (above - b.base); j >= 0 && j < len(b.lines) {
+				s = b.lines[j]
+			}
+			lines[i] += s + strings.Repeat(" ", w-ansi.StringWidth(s))
+		}
+	}
+	return block{lines: lines, base: above}
+}
+
+// pad widens each line of a block to w columns, aligned left, right or
+// in the center
+func pad(b block, w int, align cellAlign, right bool) block {
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		gap := max(w-ansi.StringWidth(line), 0)
+		left := gap / 2
+		switch {
+		case align == leftCells, align == pairedCells && !right:
+			left = 0
+		case align == pairedCells && right:
+			left = gap
+		}
+		lines[i] = strings.Repeat(" ", left) + line + strings.Repeat(" ", gap-left)
+	}
+	return block{lines: lines, base: b.base}
+}
+
+// layout turns nodes into blocks
+type layout struct {
+	display bool // stack fractions, limits and matrices over several lines
+	tight   bool // leave out the spaces around operators, as in scripts
+}
+
+func (l layout) node(n node) block {
+	switch n := n.(type) {
+	case symbol:
+		return textBlock(n.text)
+	case row:
+		return l.row(n)
+	case script:
+		return l.script(n)
+	case frac:
+		return l.frac(n)
+	case radical:
+		return l.radical(n)
+	case font:
+		b := l.node(n.body)
+		for i, line := range b.lines {
+			b.lines[i] = strings.Map(func(r rune) rune { return fontLetter(n.name, r) }, line)
+		}
+		return b
+	case accent:
+		b := l.node(n.body)
+		var s strings.Builder
+		for _, r := range b.lines[b.base] {
+			s.WriteRune(r)
+			if r != ' ' {
+				s.WriteRune(n.mark)
+			}
+		}
+		b.lines[b.base] = s.String()
+		return b
+	case fenced:
+		return fence(l.node(n.body), n.left, n.right)
+	case matrix:
+		return l.matrix(n)
+	}
+	return textBlock("")
+}
+
+// inline lays a node out on one line, without operator spacing
+func (l layout) inline(n node) string {
+	return layout{tight: true}.node(n).lines[0]
+}
+
+// kindOf returns how a node is spaced against its neighbours
+func kindOf(n node) kind {
+	switch n := n.(type) {
+	case symbol:
+		return n.kind
+	case script:
+		return kindOf(n.base)
+	case accent:
+		return kindOf(n.body)
+	}
+	return ordKind
+}
+
+// spaced reports whether a space goes between nodes of these kinds
+func spaced(prev, k kind) bool {
+	switch {
+	case prev == noKind || prev == spaceKind || k == spaceKind:
+		return false
+	case k == binKind || k == relKind || k == opKind:
+		return prev != openKind
+	case prev == binKind || prev == relKind || prev == punctKind:
+		return true
+	case prev == opKind:
+		return k != openKind && k != closeKind && k != punctKind
+	}
+	return false
+}
+
+// row lays nodes out side by side with spaces around operators. An
+// operator with nothing before it, as in -x, is a sign.
+func (l layout) row(r row) block {
+	var parts []block
+	prev := noKind
+	for _, n := range r {
+		k := kindOf(n)
+		if k == binKind {
+			switch prev {
+			case noKind, binKind, relKind, openKind, punctKind, opKind:
+				k = ordKind
+			}
+		}
+		if !l.tight && spaced(prev, k) {
+			parts = append(parts, textBlock(" "))
+		}
+		parts = append(parts, l.node(n))
+		prev = k
+	}
+	return hcat(parts...)
+}
+
+// toScript converts text to sub- or superscript characters, if all have one
+func toScript(s string, table map[rune]rune) (string, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(c)
+	}
+	return b.String(), s != ""
+}
+
+// script lays out a node with its scripts: as Unicode script characters
+// when they have them, above and below big operators in display math,
+// raised and lowered beside the node otherwise
+func (l layout) script(s script) block {
+	base := l.node(s.base)
+	if op, ok := s.base.(symbol); ok && op.limits && l.display {
+		return l.limits(base, s)
+	}
+
+	sub, subOK := "", true
+	if s.sub != nil {
+		sub, subOK = toScript(l.inline(s.sub), subscripts)
+	}
+	sup, supOK := "", true
+	if s.sup != nil {
+		sup, supOK = toScript(l.inline(s.sup), superscripts)
+	}
+	if len(base.lines) == 1 && subOK && supOK {
+		return textBlock(base.lines[0] + sub + sup)
+	}
+
+	if !l.display {
+		text := base.lines[0]
+		if s.sub != nil {
+			text += scriptFallback("_", sub, subOK, l.inline(s.sub))
+		}
+		if s.sup != nil {
+			text += scriptFallback("^", sup, supOK, l.inline(s.sup))
+		}
+		return textBlock(text)
+	}
+
+	scripts := layout{display: true, tight: true}
+	var col []string
+	if s.sup != nil {
+		col = append(col, scripts.node(s.sup).lines...)
+	}
+	top := len(col)
+	col = append(col, make([]string, len(base.lines))...)
+	if s.sub != nil {
+		col = append(col, scripts.node(s.sub).lines...)
+	}
+	return hcat(base, block{lines: col, base: top + base.base})
+}
+
+// scriptFallback writes a script that has no Unicode form as _x or ^(xy)
+func scriptFallback(mark, converted string, ok bool, text string) string {
+	switch {
+	case ok:
+		return converted
+	case len([]rune(text)) == 1:
+		return mark + text
+	}
+	return mark + "(" + text + ")"
+}
+
+// limits stacks the scripts of a big operator above and below it
+func (l layout) limits(op block, s script) block {
+	scripts := layout{display: true, tight: true}
+	var parts []block
+	if s.sup != nil {
+		parts = append(parts, scripts.node(s.sup))
+	}
+	base := 0
+	for _, p := range parts {
+		base += len(p.lines)
+	}
+	base += op.base
+	parts = append(parts, op)
+	if s.sub != nil {
+		parts = append(parts, scripts.node(s.sub))
+	}
+
+	w := 0
+	for _, p := range parts {
+		w = max(w, p.width())
+	}
+	var lines []string
+	for _, p := range parts {
+		lines = append(lines, pad(p, w, centerCells, false).lines...)
+	}
+	return block{lines: lines, base: base}
+}
+
+// frac lays out a fraction: stacked over a rule in display math, with a
+// slash inline
+func (l layout) frac(f frac) block {
+	if !l.display {
+		num, den := l.node(f.num).lines[0], l.node(f.den).lines[0]
+		if f.binom {
+			return textBlock("(" + num + " choose " + den + ")")
+		}
+		if v, ok := vulgarFractions[num+"/"+den]; ok {
+			return textBlock(v)
+		}
+		return textBlock(group(num) + "/" + group(den))
+	}
+
+	num, den := l.node(f.num), l.node(f.den)
+	w := max(num.width(), den.width())
+	if f.binom {
+		lines := append(pad(num, w, centerCells, false).lines, pad(den, w, centerCells, false).lines...)
+		return fence(block{lines: lines, base: len(num.lines) - 1}, "(", ")")
+	}
+	lines := pad(num, w+2, centerCells, false).lines
+	lines = append(lines, strings.Repeat("─", w+2))
+	lines = append(lines, pad(den, w+2, centerCells, false).lines...)
+	return block{lines: lines, base: len(num.lines)}
+}
+
+// group puts parentheses around text that isn't a single word or number
+func group(s string) string {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '.' {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+// radical lays out a root with √, ∛ or ∜
+func (l layout) radical(r radical) block {
+	sign := "√"
+	if r.index != nil {
+		index := l.inline(r.index)
+		switch index {
+		case "2":
+		case "3":
+			sign = "∛"
+		case "4":
+			sign = "∜"
+		default:
+			if sup, ok := toScript(index, superscripts); ok {
+				sign = sup + "√"
+			} else {
+				sign = "(" + index + ")√"
+			}
+		}
+	}
+
+	body := l.node(r.body)
+	if len(body.lines) == 1 {
+		return textBlock(sign + group(body.lines[0]))
+	}
+	return hcat(textBlock(sign), fence(body, "(", ")"))
+}
+
+// delimiterPieces are the top, middle, bottom and center characters that
+// build tall delimiters
+var delimiterPieces = map[string][4]string{
+	"(": {"⎛", "⎜", "⎝", "⎜"}, ")": {"⎞", "⎟", "⎠", "⎟"},
+	"[": {"⎡", "⎢", "⎣", "⎢"}, "]": {"⎤", "⎥", "⎦", "⎥"},
+	"{": {"⎧", "⎪", "⎩", "⎨"}, "}": {"⎫", "⎪", "⎭", "⎬"},
+	"⌊": {"⎢", "⎢", "⎣", "⎢"}, "⌋": {"⎥", "⎥", "⎦", "⎥"},
+	"⌈": {"⎡", "⎢", "⎢", "⎢"}, "⌉": {"⎤", "⎥", "⎥", "⎥"},
+	"|": {"│", "│", "│", "│"}, "‖": {"‖", "‖", "‖", "‖"},
+}
+
+// fence puts delimiters around a block, built up to its height
+func fence(b block, left, right string) block {
+	if len(b.lines) == 1 {
+		return textBlock(left + b.lines[0] + right)
+	}
+	return hcat(delimiter(left, b), b, delimiter(right, b))
+}
+
+// delimiter builds a delimiter as tall as a block
+func delimiter(d string, b block) block {
+	h := len(b.lines)
+	lines := make([]string, h)
+	pieces, ok := delimiterPieces[d]
+	for i := range lines {
+		switch {
+		case !ok:
+			if i == b.base {
+				lines[i] = d
+			}
+		case i == 0:
+			lines[i] = pieces[0]
+		case i == h-1:
+			lines[i] = pieces[2]
+		case i == (h-1)/2 && h > 2:
+			lines[i] = pieces[3]
+		default:
+			lines[i] = pieces[1]
+		}
+	}
+	return block{lines: lines, base: b.base}
+}
+
+// matrix lays out a grid of cells; inline the rows are separated with
+// semicolons
+func (l layout) matrix(m matrix) block {
+	if !l.display {
+		var rows []string
+		for _, r := range m.rows {
+			var cells []string
+			for _, c := range r {
+				cells = append(cells, strings.TrimSpace(l.node(c).lines[0]))
+			}
+			rows = append(rows, strings.Join(cells, " "))
+		}
+		return textBlock(m.left + strings.Join(rows, "; ") + m.right)
+	}
+
+	cells := make([][]block, len(m.rows))
+	var widths []int
+	tall := false
+	for i, r := range m.rows {
+		for j, c := range r {
+			b := l.node(c)
+			cells[i] = append(cells[i], b)
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], b.width())
+			tall = tall || len(b.lines) > 1
+		}
+	}
+
+	align := m.align
+	if align == pairedCells && len(widths) == 1 {
+		align = centerCells
+	}
+
+	var lines []string
+	for i, r := range cells {
+		var parts []block
+		for j := range widths {
+			if j > 0 {
+				parts = append(parts, textBlock(cellGap(align, j)))
+			}
+			b := textBlock("")
+			if j < len(r) {
+				b = r[j]
+			}
+			parts = append(parts, pad(b, widths[j], align, j%2 == 0))
+		}
+		if i > 0 && tall {
+			lines = append(lines, "")
+		}
+		lines = append(lines, hcat(parts...).lines...)
+	}
+	return fence(block{lines: lines, base: (len(lines) - 1) / 2}, m.left, m.right)
+}
+
+// cellGap is the space before column j of a matrix
+func cellGap(align cellAlign, j int) string {
+	switch {
+	case align == pairedCells && j%2 == 1:
+		return " "
+	case align == pairedCells:
+		return "    "
+	}
+	return "  "
+}
@@ -0,0 +1,601 @@
+package texmath
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies a token of TeX math
+type tokenKind int
+
+const (
+	eofToken     tokenKind = iota
+	charToken              // a character typed directly
+	commandToken           // \name or \ and one other character
+	openToken              // {
+	closeToken             // }
+	supToken               // ^
+	subToken               // _
+	alignToken             // & between matrix cells
+	newlineToken           // \\ between matrix rows
+	spaceToken             // white space, which only matters in \text
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits TeX math into tokens, dropping % comments
+func tokenize(tex string) []token {
+	var toks []token
+	r := []rune(tex)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			for i < len(r) && unicode.IsSpace(r[i]) {
+				i++
+			}
+			toks = append(toks, token{spaceToken, " "})
+		case c == '%':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '\\' && i+1 < len(r) && r[i+1] == '\\':
+			toks = append(toks, token{newlineToken, `\\`})
+			i += 2
+		case c == '\\' && i+1 < len(r) && isLetter(r[i+1]):
+			j := i + 1
+			for j < len(r) && isLetter(r[j]) {
+				j++
+			}
+			toks = append(toks, token{commandToken, string(r[i+1 : j])})
+			// Spaces after a command name only end the name
+			for j < len(r) && unicode.IsSpace(r[j]) {
+				j++
+			}
+			i = j
+		case c == '\\' && i+1 < len(r):
+			toks = append(toks, token{commandToken, string(r[i+1])})
+			i += 2
+		case c == '{':
+			toks = append(toks, token{openToken, "{"})
+			i++
+		case c == '}':
+			toks = append(toks, token{closeToken, "}"})
+			i++
+		case c == '^':
+			toks = append(toks, token{supToken, "^"})
+			i++
+		case c == '_':
+			toks = append(toks, token{subToken, "_"})
+			i++
+		case c == '&':
+			toks = append(toks, token{alignToken, "&"})
+			i++
+		default:
+			toks = append(toks, token{charToken, string(c)})
+			i++
+		}
+	}
+	return toks
+}
+
+// isLetter reports whether r can be part of a command name
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// node is a piece of parsed math: one of the types below
+type node interface{}
+
+// symbol is a character, operator or word
+type symbol struct {
+	text   string
+	kind   kind
+	limits bool // whether displayed scripts go above and below
+}
+
+// row is a sequence of nodes side by side
+type row []node
+
+// script is a node with a subscript, superscript or both (nil if absent)
+type script struct {
+	base, sub, sup node
+}
+
+// frac is a fraction, or a binomial coefficient
+type frac struct {
+	num, den node
+	binom    bool
+}
+
+// radical is a square root, or a root of another index (nil for square)
+type radical struct {
+	index, body node
+}
+
+// font is math set in an alphabet such as \mathbb
+type font struct {
+	name string
+	body node
+}
+
+// accent is math with a mark above or below each character
+type accent struct {
+	mark rune
+	body node
+}
+
+// fenced is math between \left and \right delimiters
+type fenced struct {
+	left, right string
+	body        node
+}
+
+// cellAlign is how the cells of a matrix line up
+type cellAlign int
+
+const (
+	centerCells cellAlign = iota
+	leftCells
+	pairedCells // right and left in turn, as in align
+)
+
+// matrix is a grid of cells from an environment or \\ and & at the top
+type matrix struct {
+	rows        [][]node
+	left, right string
+	align       cellAlign
+}
+
+// environments gives the delimiters and alignment of each supported
+// environment
+var environments = map[string]matrix{
+	"matrix": {}, "smallmatrix": {}, "array": {align: leftCells},
+	"pmatrix": {left: "(", right: ")"}, "bmatrix": {left: "[", right: "]"},
+	"Bmatrix": {left: "{", right: "}"}, "vmatrix": {left: "|", right: "|"},
+	"Vmatrix": {left: "‖", right: "‖"},
+	"cases":   {left: "{", align: leftCells}, "dcases": {left: "{", align: leftCells},
+	"aligned": {align: pairedCells}, "align": {align: pairedCells}, "align*": {align: pairedCells},
+	"alignat": {align: pairedCells}, "alignat*": {align: pairedCells},
+	"split": {align: pairedCells}, "eqnarray": {align: pairedCells}, "eqnarray*": {align: pairedCells},
+	"gathered": {}, "gather": {}, "gather*": {}, "multline": {}, "multline*": {},
+	"equation": {}, "equation*": {},
+}
+
+// parse reads TeX math into nodes
+func parse(tex string) (node, error) {
+	p := &parser{toks: tokenize(tex)}
+	rows, err := p.parseRows()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != eofToken {
+		return nil, fmt.Errorf("unexpected %s", t.text)
+	}
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		return rows[0][0], nil
+	}
+	return matrix{rows: rows, align: pairedCells}, nil
+}
+
+// parser reads tokens into nodes
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.toks) {
+		return token{kind: eofToken}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) skipSpace() {
+	for p.peek().kind == spaceToken {
+		p.pos++
+	}
+}
+
+// parseRows reads cells separated by & and rows separated by \\
+func (p *parser) parseRows() ([][]node, error) {
+	var rows [][]node
+	var cells []node
+	for {
+		r, err := p.parseRow()
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, r)
+		switch p.peek().kind {
+		case alignToken:
+			p.next()
+		case newlineToken:
+			p.next()
+			rows = append(rows, cells)
+			cells = nil
+		default:
+			// A trailing \\ leaves an empty last row
+			if len(cells) > 1 || len(cells[0].(row)) > 0 || len(rows) == 0 {
+				rows = append(rows, cells)
+			}
+			return rows, nil
+		}
+	}
+}
+
+// parseRow reads nodes up to a closing brace, &, \\, \end, \right or the end
+func (p *parser) parseRow() (row, error) {
+	var r row
+	for {
+		p.skipSpace()
+		t := p.peek()
+		switch {
+		case t.kind == eofToken, t.kind == closeToken, t.kind == alignToken, t.kind == newlineToken,
+			t.kind == commandToken && (t.text == "end" || t.text == "right"):
+			return r, nil
+		}
+
+		n, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			continue // a command that changes nothing in text
+		}
+		if n, err = p.parseScripts(n); err != nil {
+			return nil, err
+		}
+		r = append(r, n)
+	}
+}
+
+// parseAtom reads one symbol, group or command with its arguments. It
+// returns nil for commands that are left out.
+func (p *parser) parseAtom() (node, error) {
+	p.skipSpace()
+	t := p.next()
+	switch t.kind {
+	case charToken:
+		if s, ok := chars[t.text]; ok {
+			return s, nil
+		}
+		return symbol{text: t.text, kind: ordKind}, nil
+	case openToken:
+		r, err := p.parseRow()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != closeToken {
+			return nil, fmt.Errorf("missing }")
+		}
+		return r, nil
+	case supToken, subToken:
+		// A script with nothing before it
+		p.pos--
+		return symbol{kind: ordKind}, nil
+	case commandToken:
+		return p.parseCommand(t.text)
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// parseScripts reads the subscript, superscript and primes after a node
+func (p *parser) parseScripts(base node) (node, error) {
+	s := script{base: base}
+	primes := ""
+	for {
+		p.skipSpace()
+		t := p.peek()
+		switch {
+		case t.kind == supToken || t.kind == subToken:
+			p.next()
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			if t.kind == supToken {
+				if s.sup != nil {
+					return nil, fmt.Errorf("double superscript")
+				}
+				s.sup = arg
+			} else {
+				if s.sub != nil {
+					return nil, fmt.Errorf("double subscript")
+				}
+				s.sub = arg
+			}
+		case t.kind == charToken && t.text == "'":
+			p.next()
+			primes += "′"
+		case t.kind == commandToken && (t.text == "limits" || t.text == "nolimits"):
+			p.next()
+			if sym, ok := base.(symbol); ok && sym.kind == opKind {
+				sym.limits = t.text == "limits"
+				s.base = sym
+			}
+		default:
+			if primes != "" {
+				if s.sup == nil {
+					s.sup = symbol{text: primes, kind: ordKind}
+				} else {
+					s.sup = row{symbol{text: primes, kind: ordKind}, s.sup}
+				}
+			}
+			if s.sub == nil && s.sup == nil {
+				return s.base, nil
+			}
+			return s, nil
+		}
+	}
+}
+
+// parseArg reads a command argument or script: a group or a single atom
+func (p *parser) parseArg() (node, error) {
+	p.skipSpace()
+	switch p.peek().kind {
+	case eofToken, closeToken, alignToken, newlineToken, supToken, subToken:
+		return nil, fmt.Errorf("missing argument")
+	}
+	n, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, fmt.Errorf("missing argument")
+	}
+	return n, nil
+}
+
+// parseText reads a braced argument as text, keeping its spaces
+func (p *parser) parseText() (string, error) {
+	p.skipSpace()
+	if p.next().kind != openToken {
+		return "", fmt.Errorf("missing {")
+	}
+	var b strings.Builder
+	depth := 0
+	for {
+		t := p.next()
+		switch t.kind {
+		case eofToken:
+			return "", fmt.Errorf("missing }")
+		case openToken:
+			depth++
+		case closeToken:
+			if depth == 0 {
+				return b.String(), nil
+			}
+			depth--
+		case commandToken:
+			s, ok := commands[t.text]
+			if !ok {
+				return "", fmt.Errorf("unknown command \\%s in text", t.text)
+			}
+			b.WriteString(s.text)
+		default:
+			b.WriteString(t.text)
+		}
+	}
+}
+
+// parseOptional reads a [...] argument, or returns nil when there isn't one
+func (p *parser) parseOptional() (node, error) {
+	p.skipSpace()
+	if t := p.peek(); t.kind != charToken || t.text != "[" {
+		return nil, nil
+	}
+	p.next()
+	start, depth := p.pos, 0
+	for ; p.pos < len(p.toks); p.pos++ {
+		t := p.toks[p.pos]
+		switch {
+		case t.kind == openToken:
+			depth++
+		case t.kind == closeToken:
+			depth--
+		case t.kind == charToken && t.text == "]" && depth == 0:
+			inner := &parser{toks: p.toks[start:p.pos]}
+			p.pos++
+			r, err := inner.parseRow()
+			if err == nil && inner.peek().kind != eofToken {
+				err = fmt.Errorf("unexpected %s", inner.peek().text)
+			}
+			return r, err
+		}
+	}
+	return nil, fmt.Errorf("missing ]")
+}
+
+// parseDelim reads the delimiter after \left, \right or \big
+func (p *parser) parseDelim() (string, error) {
+	p.skipSpace()
+	t := p.next()
+	switch {
+	case t.kind == charToken && t.text == ".":
+		return "", nil
+	case t.kind == charToken && t.text == "<":
+		return "⟨", nil
+	case t.kind == charToken && t.text == ">":
+		return "⟩", nil
+	case t.kind == charToken && strings.Contains("()[]|/", t.text):
+		return t.text, nil
+	case t.kind == commandToken:
+		if s, ok := commands[t.text]; ok && (s.kind == openKind || s.kind == closeKind || s.kind == ordKind || s.kind == relKind) {
+			return s.text, nil
+		}
+	}
+	return "", fmt.Errorf("bad delimiter %q", t.text)
+}
+
+// parseCommand reads a command and its arguments
+func (p *parser) parseCommand(name string) (node, error) {
+	if s, ok := commands[name]; ok {
+		return s, nil
+	}
+	if mark, ok := accents[name]; ok {
+		body, err := p.parseArg()
+		return accent{mark: mark, body: body}, err
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom", "dbinom", "tbinom":
+		num, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.parseArg()
+		return frac{num: num, den: den, binom: strings.HasSuffix(name, "binom")}, err
+
+	case "sqrt":
+		index, err := p.parseOptional()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseArg()
+		return radical{index: index, body: body}, err
+
+	case "text", "textrm", "textnormal", "textit", "textbf", "textsf", "texttt", "textup", "mbox", "hbox":
+		s, err := p.parseText()
+		return symbol{text: s, kind: ordKind}, err
+
+	case "operatorname":
+		limits := p.skipStar()
+		s, err := p.parseText()
+		return symbol{text: s, kind: opKind, limits: limits}, err
+
+	case "mathbb", "mathcal", "mathscr", "mathfrak", "mathbf", "mathit", "mathsf", "mathtt",
+		"mathrm", "mathnormal", "boldsymbol", "bm", "pmb":
+		body, err := p.parseArg()
+		return font{name: name, body: body}, err
+
+	case "left":
+		left, err := p.parseDelim()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseRow()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != commandToken || t.text != "right" {
+			return nil, fmt.Errorf("missing \\right")
+		}
+		right, err := p.parseDelim()
+		return fenced{left: left, right: right, body: body}, err
+
+	case "big", "Big", "bigg", "Bigg", "bigl", "Bigl", "biggl", "Biggl",
+		"bigr", "Bigr", "biggr", "Biggr", "bigm", "Bigm", "biggm", "Biggm":
+		d, err := p.parseDelim()
+		k := ordKind
+		switch {
+		case strings.HasSuffix(name, "l"):
+			k = openKind
+		case strings.HasSuffix(name, "r"):
+			k = closeKind
+		case strings.HasSuffix(name, "m"):
+			k = relKind
+		}
+		return symbol{text: d, kind: k}, err
+
+	case "not":
+		n, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := n.(symbol)
+		if !ok {
+			return nil, fmt.Errorf("\\not needs a symbol")
+		}
+		if neg, ok := negated[s.text]; ok {
+			s.text = neg
+		} else {
+			s.text += "̸"
+		}
+		return s, nil
+
+	case "pmod":
+		arg, err := p.parseArg()
+		return row{symbol{text: " ", kind: spaceKind}, fenced{left: "(", right: ")", body: row{symbol{text: "mod", kind: opKind}, arg}}}, err
+
+	case "tag":
+		p.skipStar()
+		s, err := p.parseText()
+		return symbol{text: "    (" + s + ")", kind: spaceKind}, err
+
+	case "begin":
+		return p.parseEnv()
+
+	case "boxed", "displaylines", "mathop", "mathord", "mathrel", "mathbin", "mathclose", "mathopen", "mathpunct":
+		return p.parseArg()
+
+	case "textcolor":
+		if _, err := p.parseText(); err != nil {
+			return nil, err
+		}
+		return p.parseArg()
+
+	case "color", "label":
+		_, err := p.parseText()
+		return nil, err
+
+	case "displaystyle", "textstyle", "scriptstyle", "scriptscriptstyle",
+		"limits", "nolimits", "nonumber", "notag", "middle":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown command \\%s", name)
+}
+
+// skipStar skips a * after a command name, reporting whether there was one
+func (p *parser) skipStar() bool {
+	if t := p.peek(); t.kind == charToken && t.text == "*" {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// parseEnv reads an environment after \begin up to its \end
+func (p *parser) parseEnv() (node, error) {
+	name, err := p.parseText()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := environments[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown environment %s", name)
+	}
+	if name == "array" || strings.HasPrefix(name, "alignat") {
+		// The column spec or column count
+		if _, err := p.parseText(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := p.parseRows()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != commandToken || t.text != "end" {
+		return nil, fmt.Errorf("missing \\end{%s}", name)
+	}
+	if end, err := p.parseText(); err != nil || end != name {
+		return nil, fmt.Errorf("missing \\end{%s}", name)
+	}
+
+	if m.left == "" && len(rows) == 1 && len(rows[0]) == 1 {
+		return rows[0][0], nil
+	}
+	m.rows = rows
+	return m, nil
+}
@@ -0,0 +1,239 @@
+package texmath
+
+// kind is how a symbol is spaced against its neighbours
+type kind int
+
+const (
+	noKind    kind = iota
+	ordKind        // letters, digits and other ordinary symbols
+	binKind        // binary operators such as + and ×
+	relKind        // relations such as = and ≤
+	punctKind      // commas and semicolons
+	openKind       // opening brackets
+	closeKind      // closing brackets
+	opKind         // big operators and function names such as ∑ and sin
+	spaceKind      // explicit spacing such as \quad
+)
+
+// sym is a shorthand for the symbol tables
+func sym(text string, k kind) symbol {
+	return symbol{text: text, kind: k}
+}
+
+// chars gives the characters typed directly in math their spacing
+var chars = map[string]symbol{
+	"+":  sym("+", binKind),
+	"-":  sym("−", binKind),
+	"*":  sym("∗", binKind),
+	"=":  sym("=", relKind),
+	"<":  sym("<", relKind),
+	">":  sym(">", relKind),
+	":":  sym(":", relKind),
+	",":  sym(",", punctKind),
+	";":  sym(";", punctKind),
+	"(":  sym("(", openKind),
+	"[":  sym("[", openKind),
+	")":  sym(")", closeKind),
+	"]":  sym("]", closeKind),
+	"'":  sym("′", ordKind),
+	"~":  sym(" ", spaceKind),
+	"\"": sym("″", ordKind),
+}
+
+// commands maps the commands that stand for a single symbol
+var commands = map[string]symbol{
+	// Lowercase Greek
+	"alpha": sym("α", ordKind), "beta": sym("β", ordKind), "gamma": sym("γ", ordKind),
+	"delta": sym("δ", ordKind), "epsilon": sym("ϵ", ordKind), "varepsilon": sym("ε", ordKind),
+	"zeta": sym("ζ", ordKind), "eta": sym("η", ordKind), "theta": sym("θ", ordKind),
+	"vartheta": sym("ϑ", ordKind), "iota": sym("ι", ordKind), "kappa": sym("κ", ordKind),
+	"lambda": sym("λ", ordKind), "mu": sym("μ", ordKind), "nu": sym("ν", ordKind),
+	"xi": sym("ξ", ordKind), "omicron": sym("ο", ordKind), "pi": sym("π", ordKind),
+	"varpi": sym("ϖ", ordKind), "rho": sym("ρ", ordKind), "varrho": sym("ϱ", ordKind),
+	"sigma": sym("σ", ordKind), "varsigma": sym("ς", ordKind), "tau": sym("τ", ordKind),
+	"upsilon": sym("υ", ordKind), "phi": sym("ϕ", ordKind), "varphi": sym("φ", ordKind),
+	"chi": sym("χ", ordKind), "psi": sym("ψ", ordKind), "omega": sym("ω", ordKind),
+
+	// Uppercase Greek
+	"Gamma": sym("Γ", ordKind), "Delta": sym("Δ", ordKind), "Theta": sym("Θ", ordKind),
+	"Lambda": sym("Λ", ordKind), "Xi": sym("Ξ", ordKind), "Pi": sym("Π", ordKind),
+	"Sigma": sym("Σ", ordKind), "Upsilon": sym("Υ", ordKind), "Phi": sym("Φ", ordKind),
+	"Psi": sym("Ψ", ordKind), "Omega": sym("Ω", ordKind),
+
+	// Letter-like symbols
+	"infty": sym("∞", ordKind), "partial": sym("∂", ordKind), "nabla": sym("∇", ordKind),
+	"forall": sym("∀", ordKind), "exists": sym("∃", ordKind), "nexists": sym("∄", ordKind),
+	"emptyset": sym("∅", ordKind), "varnothing": sym("∅", ordKind), "hbar": sym("ℏ", ordKind),
+	"ell": sym("ℓ", ordKind), "Re": sym("ℜ", ordKind), "Im": sym("ℑ", ordKind),
+	"aleph": sym("ℵ", ordKind), "wp": sym("℘", ordKind), "angle": sym("∠", ordKind),
+	"triangle": sym("△", ordKind), "top": sym("⊤", ordKind), "bot": sym("⊥", ordKind),
+	"prime": sym("′", ordKind), "degree": sym("°", ordKind), "neg": sym("¬", ordKind),
+	"lnot": sym("¬", ordKind), "checkmark": sym("✓", ordKind), "dagger": sym("†", ordKind),
+	"ldots": sym("…", ordKind), "dots": sym("…", ordKind), "dotsc": sym("…", ordKind),
+	"cdots": sym("⋯", ordKind), "dotsb": sym("⋯", ordKind), "vdots": sym("⋮", ordKind),
+	"ddots": sym("⋱", ordKind), "imath": sym("ı", ordKind), "jmath": sym("ȷ", ordKind),
+
+	// Binary operators
+	"pm": sym("±", binKind), "mp": sym("∓", binKind), "times": sym("×", binKind),
+	"div": sym("÷", binKind), "cdot": sym("⋅", binKind), "ast": sym("∗", binKind),
+	"star": sym("⋆", binKind), "circ": sym("∘", binKind), "bullet": sym("∙", binKind),
+	"cap": sym("∩", binKind), "cup": sym("∪", binKind), "setminus": sym("∖", binKind),
+	"wedge": sym("∧", binKind), "land": sym("∧", binKind), "vee": sym("∨", binKind),
+	"lor": sym("∨", binKind), "oplus": sym("⊕", binKind), "ominus": sym("⊖", binKind),
+	"otimes": sym("⊗", binKind), "odot": sym("⊙", binKind), "oslash": sym("⊘", binKind),
+	"sqcup": sym("⊔", binKind), "sqcap": sym("⊓", binKind), "uplus": sym("⊎", binKind),
+	"bmod": sym("mod", binKind), "mod": sym("mod", binKind),
+
+	// Relations
+	"leq": sym("≤", relKind), "le": sym("≤", relKind), "geq": sym("≥", relKind),
+	"ge": sym("≥", relKind), "leqslant": sym("⩽", relKind), "geqslant": sym("⩾", relKind),
+	"neq": sym("≠", relKind), "ne": sym("≠", relKind), "approx": sym("≈", relKind),
+	"equiv": sym("≡", relKind), "sim": sym("∼", relKind), "simeq": sym("≃", relKind),
+	"cong": sym("≅", relKind), "propto": sym("∝", relKind), "ll": sym("≪", relKind),
+	"gg": sym("≫", relKind), "in": sym("∈", relKind), "notin": sym("∉", relKind),
+	"ni": sym("∋", relKind), "subset": sym("⊂", relKind), "subseteq": sym("⊆", relKind),
+	"supset": sym("⊃", relKind), "supseteq": sym("⊇", relKind), "subsetneq": sym("⊊", relKind),
+	"perp": sym("⊥", relKind), "parallel": sym("∥", relKind), "mid": sym("∣", relKind),
+	"nmid": sym("∤", relKind), "vdash": sym("⊢", relKind), "models": sym("⊨", relKind),
+	"prec": sym("≺", relKind), "succ": sym("≻", relKind), "preceq": sym("⪯", relKind),
+	"succeq": sym("⪰", relKind), "doteq": sym("≐", relKind), "coloneqq": sym("≔", relKind),
+	"triangleq": sym("≜", relKind), "asymp": sym("≍", relKind),
+
+	// Arrows
+	"to": sym("→", relKind), "rightarrow": sym("→", relKind), "leftarrow": sym("←", relKind),
+	"gets": sym("←", relKind), "leftrightarrow": sym("↔", relKind), "Rightarrow": sym("⇒", relKind),
+	"Leftarrow": sym("⇐", relKind), "Leftrightarrow": sym("⇔", relKind), "implies": sym("⟹", relKind),
+	"impliedby": sym("⟸", relKind), "iff": sym("⟺", relKind), "mapsto": sym("↦", relKind),
+	"longrightarrow": sym("⟶", relKind), "longleftarrow": sym("⟵", relKind), "longmapsto": sym("⟼", relKind),
+	"Longrightarrow": sym("⟹", relKind), "Longleftarrow": sym("⟸", relKind), "Longleftrightarrow": sym("⟺", relKind),
+	"uparrow": sym("↑", relKind), "downarrow": sym("↓", relKind), "updownarrow": sym("↕", relKind),
+	"Uparrow": sym("⇑", relKind), "Downarrow": sym("⇓", relKind), "hookrightarrow": sym("↪", relKind),
+	"rightleftharpoons": sym("⇌", relKind), "nearrow": sym("↗", relKind), "searrow": sym("↘", relKind),
+
+	// Delimiters
+	"{": sym("{", openKind), "}": sym("}", closeKind), "lbrace": sym("{", openKind),
+	"rbrace": sym("}", closeKind), "langle": sym("⟨", openKind), "rangle": sym("⟩", closeKind),
+	"lfloor": sym("⌊", openKind), "rfloor": sym("⌋", closeKind), "lceil": sym("⌈", openKind),
+	"rceil": sym("⌉", closeKind), "lvert": sym("|", openKind), "rvert": sym("|", closeKind),
+	"lVert": sym("‖", openKind), "rVert": sym("‖", closeKind), "vert": sym("|", ordKind),
+	"Vert": sym("‖", ordKind), "|": sym("‖", ordKind), "lbrack": sym("[", openKind),
+	"rbrack": sym("]", closeKind),
+
+	// Escaped characters
+	"%": sym("%", ordKind), "$": sym("$", ordKind), "&": sym("&", ordKind),
+	"#": sym("#", ordKind), "_": sym("_", ordKind), "backslash": sym("\\", ordKind),
+
+	// Spacing
+	",": sym(" ", spaceKind), ":": sym(" ", spaceKind), ";": sym(" ", spaceKind),
+	">": sym(" ", spaceKind), " ": sym(" ", spaceKind), "!": sym("", spaceKind),
+	"thinspace": sym(" ", spaceKind), "enspace": sym(" ", spaceKind), "quad": sym("  ", spaceKind),
+	"qquad": sym("    ", spaceKind),
+
+	// Big operators; the limits of those marked go above and below
+	"sum": {text: "∑", kind: opKind, limits: true}, "prod": {text: "∏", kind: opKind, limits: true},
+	"coprod": {text: "∐", kind: opKind, limits: true}, "bigcup": {text: "⋃", kind: opKind, limits: true},
+	"bigcap": {text: "⋂", kind: opKind, limits: true}, "bigvee": {text: "⋁", kind: opKind, limits: true},
+	"bigwedge": {text: "⋀", kind: opKind, limits: true}, "bigoplus": {text: "⨁", kind: opKind, limits: true},
+	"bigotimes": {text: "⨂", kind: opKind, limits: true}, "bigsqcup": {text: "⨆", kind: opKind, limits: true},
+	"int": sym("∫", opKind), "iint": sym("∬", opKind), "iiint": sym("∭", opKind),
+	"oint": sym("∮", opKind),
+
+	// Function names
+	"sin": sym("sin", opKind), "cos": sym("cos", opKind), "tan": sym("tan", opKind),
+	"cot": sym("cot", opKind), "sec": sym("sec", opKind), "csc": sym("csc", opKind),
+	"arcsin": sym("arcsin", opKind), "arccos": sym("arccos", opKind), "arctan": sym("arctan", opKind),
+	"sinh": sym("sinh", opKind), "cosh": sym("cosh", opKind), "tanh": sym("tanh", opKind),
+	"coth": sym("coth", opKind), "log": sym("log", opKind), "ln": sym("ln", opKind),
+	"lg": sym("lg", opKind), "exp": sym("exp", opKind), "dim": sym("dim", opKind),
+	"ker": sym("ker", opKind), "deg": sym("deg", opKind), "arg": sym("arg", opKind),
+	"hom": sym("hom", opKind),
+	"lim": {text: "lim", kind: opKind, limits: true}, "limsup": {text: "lim sup", kind: opKind, limits: true},
+	"liminf": {text: "lim inf", kind: opKind, limits: true}, "max": {text: "max", kind: opKind, limits: true},
+	"min": {text: "min", kind: opKind, limits: true}, "sup": {text: "sup", kind: opKind, limits: true},
+	"inf": {text: "inf", kind: opKind, limits: true}, "det": {text: "det", kind: opKind, limits: true},
+	"gcd": {text: "gcd", kind: opKind, limits: true}, "Pr": {text: "Pr", kind: opKind, limits: true},
+	"argmax": {text: "argmax", kind: opKind, limits: true}, "argmin": {text: "argmin", kind: opKind, limits: true},
+}
+
+// negated maps symbols to their crossed-out forms after \not
+var negated = map[string]string{
+	"=": "≠", "<": "≮", ">": "≯", "∈": "∉", "≡": "≢", "∼": "≁", "≈": "≉",
+	"⊂": "⊄", "⊃": "⊅", "⊆": "⊈", "⊇": "⊉", "≤": "≰", "≥": "≱", "∣": "∤",
+}
+
+// accents maps accent commands to the combining character they add
+var accents = map[string]rune{
+	"hat": '̂', "widehat": '̂', "check": '̌', "tilde": '̃',
+	"widetilde": '̃', "bar": '̄', "overline": '̅', "breve": '̆',
+	"dot": '̇', "ddot": '̈', "acute": '́', "grave": '̀',
+	"vec": '⃗', "overrightarrow": '⃗', "underline": '̲',
+}
+
+// superscripts and subscripts map characters to their Unicode script forms
+var (
+	superscripts = scriptTable(
+		"0123456789+-−=()abcdefghijklmnoprstuvwxyzABDEGHIJKLMNOPRTUVWαβγδεθιφχ′″*⊤",
+		"⁰¹²³⁴⁵⁶⁷⁸⁹⁺⁻⁻⁼⁽⁾ᵃᵇᶜᵈᵉᶠᵍʰⁱʲᵏˡᵐⁿᵒᵖʳˢᵗᵘᵛʷˣʸᶻᴬᴮᴰᴱᴳᴴᴵᴶᴷᴸᴹᴺᴼᴾᴿᵀᵁⱽᵂᵅᵝᵞᵟᵋᶿᶥᵠᵡ′″*ᵀ",
+	)
+	subscripts = scriptTable(
+		"0123456789+-−=()aehijklmnoprstuvxβγρφχ,",
+		"₀₁₂₃₄₅₆₇₈₉₊₋₋₌₍₎ₐₑₕᵢⱼₖₗₘₙₒₚᵣₛₜᵤᵥₓᵦᵧᵨᵩᵪ,",
+	)
+)
+
+// scriptTable pairs the runes of from with the runes of to
+func scriptTable(from, to string) map[rune]rune {
+	a, b := []rune(from), []rune(to)
+	table := make(map[rune]rune, len(a))
+	for i := range a {
+		table[a[i]] = b[i]
+	}
+	return table
+}
+
+// vulgarFractions are the fractions with a character of their own
+var vulgarFractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾", "1/5": "⅕",
+	"2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙", "5/6": "⅚", "1/8": "⅛",
+	"3/8": "⅜", "5/8": "⅝", "7/8": "⅞",
+}
+
+// fontLetter maps a letter or digit to a math alphabet, or returns it
+// unchanged when the alphabet doesn't have it
+func fontLetter(font string, r rune) rune {
+	type alphabet struct {
+		upper, lower, digit rune
+		special             map[rune]rune
+	}
+	var a alphabet
+	switch font {
+	case "mathbb":
+		a = alphabet{0x1D538, 0x1D552, 0x1D7D8, map[rune]rune{
+			'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+		}}
+	case "mathcal", "mathscr":
+		a = alphabet{0x1D49C, 0x1D4B6, 0, map[rune]rune{
+			'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+			'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+		}}
+	case "mathfrak":
+		a = alphabet{0x1D504, 0x1D51E, 0, map[rune]rune{
+			'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ',
+		}}
+	default:
+		return r
+	}
+
+	if s, ok := a.special[r]; ok {
+		return s
+	}
+	switch {
+	case r >= 'A' && r <= 'Z':
+		return a.upper + r - 'A'
+	case r >= 'a' && r <= 'z':
+		return a.lower + r - 'a'
+	case r >= '0' && r <= '9' && a.digit != 0:
+		return a.digit + r - '0'
+	}
+	return r
+}
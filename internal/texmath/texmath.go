@@ -0,0 +1,46 @@
+// Package texmath converts LaTeX math to Unicode text: Greek letters,
+// operators, sub- and superscripts, fractions, roots, big operators and
+// matrices. Anything it can't convert is reported so the TeX can be shown
+// as written instead.
+package texmath
+
+import "strings"
+
+// Inline converts math to a single line of text. Scripts use Unicode sub-
+// and superscript characters where they exist, and fractions are written
+// with a slash. ok is false when the math uses something unsupported.
+func Inline(tex string) (string, bool) {
+	n, err := parse(tex)
+	if err != nil {
+		return "", false
+	}
+	b := layout{}.node(n)
+	if len(b.lines) != 1 {
+		return "", false
+	}
+	s := strings.TrimSpace(b.lines[0])
+	return s, s != ""
+}
+
+// Display converts math to lines of text, with fractions, limits and
+// matrices stacked over several lines. ok is false when the math uses
+// something unsupported.
+func Display(tex string) ([]string, bool) {
+	n, err := parse(tex)
+	if err != nil {
+		return nil, false
+	}
+	b := layout{display: true}.node(n)
+
+	lines := make([]string, 0, len(b.lines))
+	empty := true
+	for _, line := range b.lines {
+		line = strings.TrimRight(line, " ")
+		empty = empty && line == ""
+		lines = append(lines, line)
+	}
+	if empty {
+		return nil, false
+	}
+	return lines, true
+}
@@ -0,0 +1,82 @@
+package texmath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		tex  string
+		want string
+	}{
+		{`x^2 + y_1`, "x² + y₁"},
+		{`x^{n+1}`, "xⁿ⁺¹"},
+		{`x^q`, "x^q"},
+		{`e^{i\pi}`, "e^(iπ)"},
+		{`\alpha + \beta = \gamma`, "α + β = γ"},
+		{`a \leq b \neq c`, "a ≤ b ≠ c"},
+		{`a \cdot b \times c`, "a ⋅ b × c"},
+		{`\frac{a}{b}`, "a/b"},
+		{`\frac{a+b}{2}`, "(a + b)/2"},
+		{`\sqrt{x}`, "√x"},
+		{`\sqrt[3]{x}`, "∛x"},
+		{`\sum_{i=1}^n i`, "∑ᵢ₌₁ⁿ i"},
+		{`\lim_{x \to 0}`, "lim_(x→0)"},
+		{`\int_0^1 x\,dx`, "∫₀¹ x dx"},
+		{`\left( x \right)`, "(x)"},
+		{`\{a\}`, "{a}"},
+		{`f'(x)`, "f′(x)"},
+		{`\mathbb{R}`, "ℝ"},
+		{`\text{if } x`, "if x"},
+		{`\hat{x}`, "x̂"},
+		{`x % comment`, "x"},
+	}
+	for _, tt := range tests {
+		got, ok := Inline(tt.tex)
+		if !ok || got != tt.want {
+			t.Errorf("Inline(%q) = %q, %v; want %q, true", tt.tex, got, ok, tt.want)
+		}
+	}
+}
+
+func TestInlineUnsupported(t *testing.T) {
+	for _, tex := range []string{``, `\foo`, `{x`, `x}`, `\frac{a}`} {
+		if got, ok := Inline(tex); ok {
+			t.Errorf("Inline(%q) = %q, true; want false", tex, got)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		name string
+		tex  string
+		want []string
+	}{
+		{"single line", `x^2`, []string{"x²"}},
+		{"fraction", `\frac{a}{b}`, []string{" a", "───", " b"}},
+		{"limits", `\sum_{i=1}^{n} i^2`, []string{" n", " ∑  i²", "i=1"}},
+		{"nested", `\sqrt{\frac{1}{2}}`, []string{" ⎛ 1 ⎞", "√⎜───⎟", " ⎝ 2 ⎠"}},
+		{"delimiters", `\left( \frac{a}{b} \right)`, []string{"⎛ a ⎞", "⎜───⎟", "⎝ b ⎠"}},
+		{"matrix", `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, []string{"⎛a  b⎞", "⎝c  d⎠"}},
+		{"cases", `\begin{cases} 1 & x > 0 \\ 0 & \text{otherwise} \end{cases}`, []string{"⎧1  x > 0", "⎩0  otherwise"}},
+		{"aligned", `\begin{align} a &= b \\ c &= d \end{align}`, []string{"a = b", "c = d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Display(tt.tex)
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Display(%q) = %q, %v; want %q, true", tt.tex, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayUnsupported(t *testing.T) {
+	for _, tex := range []string{``, `\foo`, `\begin{tabular}x\end{tabular}`} {
+		if got, ok := Display(tex); ok {
+			t.Errorf("Display(%q) = %q, true; want false", tex, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package walk finds the documents under a directory, skipping hidden and
+// ignored entries the same way the file tree does
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ayushlm10/skim/internal/format"
+	"github.com/Ayushlm10/skim/internal/ignore"
+)
+
+// Options selects the entries a walk (or the file tree) visits
+type Options struct {
+	// ShowHidden includes hidden files/directories (starting with .)
+	ShowHidden bool
+
+	// Extensions lists the document file extensions shown in the tree
+	// (nil for every format skim can render)
+	Extensions []string
+
+	// IgnoreDirs is a list of directory names to skip (e.g., node_modules, vendor).
+	// Ignore files (.gitignore, .skimignore, ...) can re-include them.
+	IgnoreDirs []string
+
+	// Ignore matches paths against IgnoreDirs, .gitignore, .git/info/exclude,
+	// the global git excludes file and .skimignore (nil to use IgnoreDirs only)
+	Ignore *ignore.Matcher
+
+	// ShowIgnored when true shows entries that would normally be ignored
+	ShowIgnored bool
+}
+
+// DefaultIgnoreDirs is the list of directories to ignore by default
+// These are common development noise directories that often contain
+// many files but rarely have meaningful markdown documentation
+var DefaultIgnoreDirs = []string{
+	"node_modules",     // JavaScript/Node.js dependencies
+	"vendor",           // Go modules, PHP Composer
+	"__pycache__",      // Python bytecode cache
+	".venv",            // Python virtual environments
+	"venv",             // Python virtual environments (alternative)
+	"dist",             // Build output directories
+	"build",            // Build output directories
+	"target",           // Rust/Java build output
+	".cache",           // Generic cache directories
+	".next",            // Next.js build cache
+	".nuxt",            // Nuxt.js build cache
+	"coverage",         // Test coverage reports
+	".terraform",       // Terraform state/cache
+	".serverless",      // Serverless framework
+	"bower_components", // Bower dependencies (legacy)
+}
+
+// OptionsFor returns the default options with ignore-file matching for rootPath
+func OptionsFor(rootPath string) Options {
+	return Options{
+		IgnoreDirs: DefaultIgnoreDirs,
+		Ignore:     ignore.New(rootPath, dirPatterns(DefaultIgnoreDirs)...),
+	}
+}
+
+// dirPatterns turns directory names into gitignore patterns matching them
+// at any depth
+func dirPatterns(names []string) []string {
+	patterns := make([]string, len(names))
+	for i, name := range names {
+		patterns[i] = name + "/"
+	}
+	return patterns
+}
+
+// IsIgnored reports whether an entry should be skipped. The matcher holds
+// IgnoreDirs as its lowest-precedence rules, under the ignore files.
+func (opts Options) IsIgnored(path string, isDir bool) bool {
+	if opts.ShowIgnored {
+		return false
+	}
+	if opts.Ignore != nil {
+		return opts.Ignore.Match(path, isDir)
+	}
+	if !isDir {
+		return false
+	}
+	name := filepath.Base(path)
+	for _, ignored := range opts.IgnoreDirs {
+		if name == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDocument reports whether a file name has one of the document extensions
+// the tree shows
+func (opts Options) IsDocument(name string) bool {
+	if opts.Extensions == nil {
+		return format.IsDocument(name)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range opts.Extensions {
+		if format.NormalizeExt(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Skip reports whether a walk leaves out an entry below the root: hidden
+// entries unless shown, and ignored ones
+func (opts Options) Skip(path string, isDir bool) bool {
+	if !opts.ShowHidden && strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	return opts.IsIgnored(path, isDir)
+}
+
+// Markdown calls fn for every markdown file under rootPath
+func Markdown(rootPath string, opts Options, fn func(path string) error) error {
+	return filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors, continue walking
+		}
+
+		// Never skip the root itself
+		if path != rootPath && opts.Skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.IsDir() && format.IsMarkdown(d.Name()) {
+			return fn(path)
+		}
+		return nil
+	})
+}
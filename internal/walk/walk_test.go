@@ -0,0 +1,86 @@
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	// Keep the user's global excludes out of the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		".gitignore":             "drafts/\n",
+		"a.md":                   "",
+		"b.txt":                  "",
+		"docs/c.markdown":        "",
+		"docs/.hidden.md":        "",
+		".notes/d.md":            "",
+		"drafts/e.md":            "",
+		"node_modules/pkg/f.md":  "",
+		"docs/node_modules/g.md": "",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		adjust func(*Options)
+		want   []string
+	}{
+		{"defaults", func(*Options) {}, []string{"a.md", "docs/c.markdown"}},
+		{"hidden shown", func(o *Options) { o.ShowHidden = true }, []string{".notes/d.md", "a.md", "docs/.hidden.md", "docs/c.markdown"}},
+		{"ignored shown", func(o *Options) { o.ShowIgnored = true }, []string{"a.md", "docs/c.markdown", "docs/node_modules/g.md", "drafts/e.md", "node_modules/pkg/f.md"}},
+		{"directory names only", func(o *Options) { o.Ignore = nil }, []string{"a.md", "docs/c.markdown", "drafts/e.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := OptionsFor(root)
+			tt.adjust(&opts)
+
+			var got []string
+			err := Markdown(root, opts, func(path string) error {
+				rel, _ := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Markdown() visited %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDocument(t *testing.T) {
+	tests := []struct {
+		extensions []string
+		name       string
+		want       bool
+	}{
+		{nil, "a.md", true},
+		{nil, "a.rst", true},
+		{nil, "a.go", false},
+		{[]string{"md"}, "A.MD", true},
+		{[]string{"md"}, "a.rst", false},
+		{[]string{".RST", "txt"}, "a.rst", true},
+	}
+	for _, tt := range tests {
+		opts := Options{Extensions: tt.extensions}
+		if got := opts.IsDocument(tt.name); got != tt.want {
+			t.Errorf("IsDocument(%q) with %q = %v, want %v", tt.name, tt.extensions, got, tt.want)
+		}
+	}
+}
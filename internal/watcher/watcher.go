@@ -1,6 +1,7 @@
 package watcher
 
 import (
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -62,11 +63,12 @@ func (w *Watcher) Watch(path string) error {
 
 	// Remove old watch if exists
 	if w.watchedPath != "" {
-		_ = w.watcher.Remove(w.watchedPath)
+		_ = w.watcher.Remove(filepath.Dir(w.watchedPath))
 	}
 
-	// Add new watch
-	err := w.watcher.Add(path)
+	// Watch the directory so files saved by renaming a new copy over them
+	// (as editors and task toggling do) keep being followed
+	err := w.watcher.Add(filepath.Dir(path))
 	if err != nil {
 		return err
 	}
@@ -81,7 +83,7 @@ func (w *Watcher) Unwatch() {
 	defer w.mu.Unlock()
 
 	if w.watchedPath != "" {
-		_ = w.watcher.Remove(w.watchedPath)
+		_ = w.watcher.Remove(filepath.Dir(w.watchedPath))
 		w.watchedPath = ""
 	}
 }
@@ -136,12 +138,12 @@ func (w *Watcher) processEvents() {
 				w.mu.Unlock()
 
 				// Only process events for the file we're watching
-				if event.Name == currentPath {
+				if currentPath != "" && filepath.Clean(event.Name) == filepath.Clean(currentPath) {
 					// Debounce: reset timer on each event
 					if timer != nil {
 						timer.Stop()
 					}
-					pendingPath = event.Name
+					pendingPath = currentPath
 					timer = time.AfterFunc(w.debounceDelay, func() {
 						// Non-blocking send
 						select {
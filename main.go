@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/Ayushlm10/skim/internal/app"
+	"github.com/Ayushlm10/skim/internal/images"
+	"github.com/Ayushlm10/skim/internal/linkcheck"
 	"github.com/Ayushlm10/skim/internal/upgrade"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -27,6 +29,16 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "check":
+			report, err := linkcheck.Run(os.Args[2:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+			if len(report.Problems) > 0 {
+				os.Exit(1)
+			}
+			return
 		case "help", "--help", "-h":
 			printHelp()
 			return
@@ -70,6 +82,9 @@ func main() {
 		model,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
+		// Kitty images are written alongside the frames; sharing the output
+		// keeps them from landing mid-frame
+		tea.WithOutput(images.Output),
 	)
 
 	if _, err := p.Run(); err != nil {
@@ -83,24 +98,70 @@ func printHelp() {
 
 Usage:
   skim [path]          Open skim in the specified directory (default: current directory)
+                       Shows markdown, .mdx, .txt, .rst, .adoc, .org and .ipynb documents,
+                       and .csv, .tsv, .json and .yaml data files
   skim version         Print version information
   skim upgrade         Upgrade skim to the latest version
+  skim check [path]    Check for broken links (--json for CI; exits 1 on problems)
   skim help            Show this help message
 
 Flags:
   -h, --help           Show this help message
   -v, --version        Print version information
 
-Navigation:
-  ↑/k, ↓/j             Move selection up/down
-  Enter                Open file or toggle directory
-  Tab                  Switch focus between panels
-  /                    Filter files (file tree) or search (preview)
-  n/N                  Next/previous search match
-  i                    Toggle ignored directories
+Keys:
+  Tab                  Switch focus between the file tree and the preview
+  .                    Reveal the previewed file in the tree
+  #                    Browse tags and front matter values; pick one to filter the tree
+  b                    List backlinks to the previewed file (including [[wiki links]])
+  L                    Check links under the root and list broken ones
+  D                    List lint findings for the previewed file (configured by .skimlint.json)
+  <, >                 Shrink/grow the file tree
+  \                    Hide/show the file tree (Tab pops it up while hidden)
+  |                    Cycle layout: auto, side by side, stacked (saved between sessions)
+  f, Z                 Fullscreen preview / zen fullscreen without the status bar
+  z                    Reading mode: keep the preview's text to a centered column
   ?                    Show help overlay
   q, Ctrl+C            Quit
 
+File tree:
+  ↑/k, ↓/j             Move selection up/down
+  Enter                Open file or toggle directory
+  h/l, ←/→             Collapse or go to parent / expand or open
+  E, W, 1-9            Expand recursively, collapse all, expand to depth
+  /, Esc               Filter files / clear the filter
+  i                    Toggle ignored entries (.gitignore, .skimignore, git excludes)
+  F                    Toggle listing all files, e.g. source code
+  a/A                  New file/directory
+  r, C, d              Rename/move, duplicate, delete to trash
+  u                    Undo last file action
+  s/S                  Cycle sort order (name, natural, modified, size, title, date, weight) / reverse
+  m                    Cycle metadata columns (modified, size, words)
+  T                    Show document titles (front matter title or first heading)
+
+Preview:
+  ↑/k, ↓/j, g/G        Scroll, jump to top/bottom (PgUp/PgDn or Ctrl+U/Ctrl+D by half a page)
+  /, n/N               Search, next/previous match
+  h/l, ←/→             Scroll wide code blocks and tables sideways
+  w                    Wrap wide code blocks and tables, or scroll them sideways with h/l
+                       (or shift+wheel) on their own or with the whole document
+  r                    Toggle the raw markdown source with line numbers
+  v                    Show the source beside the rendered preview, scrolling in sync;
+                       h/l then pick the source or rendered pane to scroll
+  m                    Collapse/expand the front matter table
+  d                    Show the source of mermaid, dot and PlantUML diagrams
+  t                    Select task list items (Space toggles, writes to file)
+  s/S, h/l             CSV/TSV tables: sort by the next column / reverse, scroll the columns
+  Enter, 1-9, 0        JSON/YAML: fold the node at the top, fold to a depth, unfold all
+
+Mouse:
+  Click                Select tree item, open file, toggle folder (arrow or double-click)
+  Click link           Follow links in the preview (URLs open in the browser)
+  Click table header   Sort by that column; click again to reverse
+  Click tree node      Fold or unfold a JSON/YAML map or list
+  Click hint           Run the status bar hint's action
+  Drag border          Resize the panels
+
 Version: %s
 `, version)
 }